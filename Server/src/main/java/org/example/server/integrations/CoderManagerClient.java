@@ -1,7 +1,8 @@
 package org.example.server.integrations;
 
 import io.grpc.ManagedChannel;
-import io.grpc.ManagedChannelBuilder;
+import io.grpc.Metadata;
+import io.grpc.stub.MetadataUtils;
 import org.example.server.proto.CoderManagerServiceGrpc;
 import org.example.server.proto.CreateEditorSessionRequest;
 import org.example.server.proto.CreateEditorSessionResponse;
@@ -19,11 +20,20 @@ public class CoderManagerClient implements DisposableBean {
     private final CoderManagerServiceGrpc.CoderManagerServiceBlockingStub stub;
 
     public CoderManagerClient(@Value("${coder.manager.host:coder-manager-server}") String host,
-                              @Value("${coder.manager.port:9090}") int port) {
-        this.channel = ManagedChannelBuilder.forAddress(host, port)
-                .usePlaintext()
-                .build();
-        this.stub = CoderManagerServiceGrpc.newBlockingStub(channel);
+                              @Value("${coder.manager.port:9090}") int port,
+                              @Value("${coder.manager.auth.token:}") String authToken,
+                              @Value("${coder.manager.tls.enabled:false}") boolean tlsEnabled,
+                              @Value("${coder.manager.tls.ca-file:}") String tlsCaFile,
+                              @Value("${coder.manager.tls.cert-file:}") String tlsCertFile,
+                              @Value("${coder.manager.tls.key-file:}") String tlsKeyFile) {
+        this.channel = GrpcChannels.create(host, port, tlsEnabled, tlsCaFile, tlsCertFile, tlsKeyFile);
+        CoderManagerServiceGrpc.CoderManagerServiceBlockingStub blockingStub = CoderManagerServiceGrpc.newBlockingStub(channel);
+        if (authToken != null && !authToken.isBlank()) {
+            Metadata headers = new Metadata();
+            headers.put(Metadata.Key.of("authorization", Metadata.ASCII_STRING_MARSHALLER), "Bearer " + authToken);
+            blockingStub = blockingStub.withInterceptors(MetadataUtils.newAttachHeadersInterceptor(headers));
+        }
+        this.stub = blockingStub;
     }
 
     public String createEditorSession(String s3Key, String path, Long chatId, long ttlSeconds) {
@@ -0,0 +1,55 @@
+package org.example.server.integrations;
+
+import io.grpc.ChannelCredentials;
+import io.grpc.Grpc;
+import io.grpc.InsecureChannelCredentials;
+import io.grpc.ManagedChannel;
+import io.grpc.TlsChannelCredentials;
+
+import java.io.File;
+import java.io.IOException;
+import java.io.UncheckedIOException;
+
+/**
+ * Builds channels to the internal gRPC services.
+ * TLS is used when enabled; a client certificate is presented when both cert and key files are set (mTLS).
+ */
+final class GrpcChannels {
+
+    private GrpcChannels() {
+    }
+
+    static ManagedChannel create(String host, int port, boolean tls,
+                                 String caFile, String certFile, String keyFile) {
+        return Grpc.newChannelBuilderForAddress(host, port, credentials(tls, caFile, certFile, keyFile)).build();
+    }
+
+    private static ChannelCredentials credentials(boolean tls, String caFile, String certFile, String keyFile) {
+        boolean hasCert = certFile != null && !certFile.isBlank();
+        boolean hasKey = keyFile != null && !keyFile.isBlank();
+        boolean hasCa = caFile != null && !caFile.isBlank();
+        if (!tls) {
+            if (hasCert || hasKey || hasCa) {
+                throw new IllegalStateException("grpc tls files are set but tls is disabled");
+            }
+            return InsecureChannelCredentials.create();
+        }
+        if (hasCert != hasKey) {
+            throw new IllegalStateException("both grpc tls client cert and key files are required");
+        }
+        try {
+            TlsChannelCredentials.Builder builder = TlsChannelCredentials.newBuilder();
+            // Without a CA file the JVM default trust store is used.
+            if (hasCa) {
+                builder.trustManager(new File(caFile));
+            }
+            if (hasCert) {
+                // The key must be a PKCS#8 PEM file.
+                builder.keyManager(new File(certFile), new File(keyFile));
+            }
+            return builder.build();
+        } catch (IOException e) {
+            throw new UncheckedIOException("failed to load grpc tls files", e);
+        }
+    }
+}
@@ -1,7 +1,10 @@
 package org.example.server.integrations;
 
 import io.grpc.ManagedChannel;
-import io.grpc.ManagedChannelBuilder;
+import io.grpc.Metadata;
+import io.grpc.stub.MetadataUtils;
+import org.example.server.proto.RefreshTokenRequest;
+import org.example.server.proto.RefreshTokenResponse;
 import org.example.server.proto.RepTrackerServiceGrpc;
 import org.example.server.proto.TrackingRepo;
 import org.slf4j.Logger;
@@ -17,11 +20,20 @@ public class RepTrackerClient implements DisposableBean {
     private final RepTrackerServiceGrpc.RepTrackerServiceBlockingStub stub;
 
     public RepTrackerClient(@Value("${reptracker.host:localhost}") String host,
-                            @Value("${reptracker.port:50051}") int port) {
-        this.channel = ManagedChannelBuilder.forAddress(host, port)
-                .usePlaintext()
-                .build();
-        this.stub = RepTrackerServiceGrpc.newBlockingStub(channel);
+                            @Value("${reptracker.port:50051}") int port,
+                            @Value("${reptracker.auth.token:}") String authToken,
+                            @Value("${reptracker.tls.enabled:false}") boolean tlsEnabled,
+                            @Value("${reptracker.tls.ca-file:}") String tlsCaFile,
+                            @Value("${reptracker.tls.cert-file:}") String tlsCertFile,
+                            @Value("${reptracker.tls.key-file:}") String tlsKeyFile) {
+        this.channel = GrpcChannels.create(host, port, tlsEnabled, tlsCaFile, tlsCertFile, tlsKeyFile);
+        RepTrackerServiceGrpc.RepTrackerServiceBlockingStub blockingStub = RepTrackerServiceGrpc.newBlockingStub(channel);
+        if (authToken != null && !authToken.isBlank()) {
+            Metadata headers = new Metadata();
+            headers.put(Metadata.Key.of("authorization", Metadata.ASCII_STRING_MARSHALLER), "Bearer " + authToken);
+            blockingStub = blockingStub.withInterceptors(MetadataUtils.newAttachHeadersInterceptor(headers));
+        }
+        this.stub = blockingStub;
     }
 
     public void addTrackingRepo(String link, Long chatId) {
@@ -43,6 +55,16 @@ public class RepTrackerClient implements DisposableBean {
         }
     }
 
+    public int refreshToken(Long chatId) {
+        log.info("Starting RepTracker token refresh: chatId={}", chatId);
+        RefreshTokenRequest request = RefreshTokenRequest.newBuilder()
+                .setChatId(chatId.toString())
+                .build();
+        RefreshTokenResponse response = stub.refreshToken(request);
+        log.info("RepTracker token refresh completed: chatId={}, reenabled={}", chatId, response.getReenabledCount());
+        return response.getReenabledCount();
+    }
+
     @Override
     public void destroy() {
         if (channel != null && !channel.isShutdown()) {
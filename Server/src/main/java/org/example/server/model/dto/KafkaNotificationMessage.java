@@ -6,10 +6,15 @@ public record KafkaNotificationMessage(
     String link,
     String author, 
     String title,
+    String branch,
     String updated_at
 ) {
     
     public String getFormattedMessage() {
+        if (branch != null && !branch.isBlank()) {
+            return String.format("🔔 *New commit in %s*\n\n🌿 *Branch:* %s\n👤 *Author:* %s\n📝 *Message:* %s\n\n🔗 [View commit](%s)", 
+                link, branch, author, title, link);
+        }
         return String.format("🔔 *New commit in %s*\n\n👤 *Author:* %s\n📝 *Message:* %s\n\n🔗 [View commit](%s)", 
             link, author, title, link);
     }
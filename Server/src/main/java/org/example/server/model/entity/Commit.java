@@ -49,6 +49,9 @@ public class Commit {
     @Column(name = "created_at")
     private OffsetDateTime createdAt;
 
+    @Column(name = "dropped_at")
+    private OffsetDateTime droppedAt;
+
     @ManyToMany
     @JoinTable(
             name = "commit_files",
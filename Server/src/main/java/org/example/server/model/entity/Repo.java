@@ -33,6 +33,9 @@ public class Repo {
     @Column(name = "added_at")
     private OffsetDateTime addedAt;
 
+    @Column(name = "installation_id")
+    private Long installationId;
+
     @OneToMany(mappedBy = "repo")
     private Set<Branch> branches = new LinkedHashSet<>();
 
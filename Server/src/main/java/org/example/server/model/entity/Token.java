@@ -14,17 +14,23 @@ import java.time.OffsetDateTime;
 @Entity
 @Table(name = "tokens")
 public class Token {
+    public static final String DEFAULT_HOST = "github.com";
+
     @Id
     @GeneratedValue(strategy = GenerationType.SEQUENCE, generator = "tokens_id_gen")
     @SequenceGenerator(name = "tokens_id_gen", sequenceName = "tokens_id_seq", allocationSize = 1)
     @Column(name = "id", nullable = false)
     private Integer id;
 
-    @OneToOne(fetch = FetchType.LAZY, optional = false)
+    @ManyToOne(fetch = FetchType.LAZY, optional = false)
     @OnDelete(action = OnDeleteAction.CASCADE)
     @JoinColumn(name = "user_id", nullable = false)
     private User user;
 
+    @ColumnDefault("'github.com'")
+    @Column(name = "host", nullable = false)
+    private String host = DEFAULT_HOST;
+
     @Column(name = "token", nullable = false, length = 256)
     private String token;
 
@@ -35,4 +41,11 @@ public class Token {
     @Column(name = "last_validate_at")
     private OffsetDateTime lastValidateAt;
 
+    // Set once the token is sealed; null while it is stored in plaintext.
+    @Column(name = "key_id")
+    private String keyId;
+
+    @Column(name = "data_key")
+    private String dataKey;
+
 }
\ No newline at end of file
@@ -39,8 +39,8 @@ public class User {
     @OneToMany(mappedBy = "user")
     private Set<Notification> notifications = new LinkedHashSet<>();
 
-    @OneToOne(mappedBy = "user")
-    private Token token;
+    @OneToMany(mappedBy = "user")
+    private Set<Token> tokens = new LinkedHashSet<>();
 
     @OneToMany(mappedBy = "user")
     private Set<UserRepo> userRepos = new LinkedHashSet<>();
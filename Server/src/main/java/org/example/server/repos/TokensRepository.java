@@ -6,5 +6,5 @@ import org.springframework.data.jpa.repository.JpaRepository;
 import java.util.Optional;
 
 public interface TokensRepository extends JpaRepository<Token, Integer> {
-    Optional<Token> findByUser(org.example.server.model.entity.User user);
+    Optional<Token> findByUserAndHost(org.example.server.model.entity.User user, String host);
 }
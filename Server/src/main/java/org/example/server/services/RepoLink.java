@@ -0,0 +1,98 @@
+package org.example.server.services;
+
+import java.net.URI;
+import java.net.URISyntaxException;
+import java.util.ArrayList;
+import java.util.Arrays;
+import java.util.List;
+import java.util.Locale;
+import java.util.Set;
+import java.util.regex.Matcher;
+import java.util.regex.Pattern;
+
+/**
+ * Repository link in the canonical form rep_tracker stores, e.g. https://github.com/owner/name.
+ * Host, owner and name are lower-cased; on GitLab the owner is the full group path.
+ */
+public record RepoLink(String host, String owner, String name) {
+    private static final String DEFAULT_HOST = "github.com";
+    private static final Set<String> GITHUB_ALIASES = Set.of("github.com", "www.github.com", "api.github.com");
+    private static final Pattern SCP_LIKE = Pattern.compile("^(?:[\\w.-]+@)?([\\w.-]+):([^/].*)$");
+
+    public String url() {
+        return "https://" + host + "/" + owner + "/" + name;
+    }
+
+    /**
+     * Accepts the forms users paste: https and ssh URLs, git@host:owner/name.git,
+     * host/owner/name, owner/name, and GitHub URLs of sub-pages like /tree/main.
+     */
+    public static RepoLink parse(String raw) {
+        if (raw == null || raw.isBlank()) {
+            throw new IllegalArgumentException("Некорректная ссылка на репозиторий");
+        }
+        String link = raw.trim();
+        String host;
+        String path;
+        Matcher scpLike = SCP_LIKE.matcher(link);
+        if (link.contains("://")) {
+            try {
+                URI uri = new URI(link);
+                host = uri.getHost();
+                path = uri.getPath();
+            } catch (URISyntaxException e) {
+                throw new IllegalArgumentException("Некорректная ссылка на репозиторий: " + raw, e);
+            }
+        } else if (scpLike.matches()) {
+            host = scpLike.group(1);
+            path = scpLike.group(2);
+        } else {
+            String first = link.split("/", 2)[0];
+            if (first.contains(".")) {
+                host = first;
+                path = link.substring(first.length());
+            } else {
+                host = DEFAULT_HOST;
+                path = link;
+            }
+        }
+        if (host == null || host.isBlank() || path == null) {
+            throw new IllegalArgumentException("Некорректная ссылка на репозиторий: " + raw);
+        }
+        host = host.toLowerCase(Locale.ROOT);
+
+        List<String> segments = new ArrayList<>(Arrays.stream(path.split("/"))
+                .filter(segment -> !segment.isEmpty())
+                .map(segment -> segment.toLowerCase(Locale.ROOT))
+                .toList());
+        if (GITHUB_ALIASES.contains(host)) {
+            boolean api = host.startsWith("api.");
+            host = DEFAULT_HOST;
+            if (api && !segments.isEmpty() && segments.get(0).equals("repos")) {
+                segments.remove(0);
+            }
+            // Everything after owner/name is a sub-page such as /tree/main.
+            if (segments.size() > 2) {
+                segments = segments.subList(0, 2);
+            }
+        } else {
+            // GitLab separates the project path from sub-pages with "/-/".
+            int subPage = segments.indexOf("-");
+            if (subPage >= 0) {
+                segments = segments.subList(0, subPage);
+            }
+        }
+        if (segments.size() < 2) {
+            throw new IllegalArgumentException("Некорректная ссылка на репозиторий: " + raw);
+        }
+        String name = segments.get(segments.size() - 1);
+        if (name.endsWith(".git")) {
+            name = name.substring(0, name.length() - ".git".length());
+        }
+        String owner = String.join("/", segments.subList(0, segments.size() - 1));
+        if (name.isEmpty()) {
+            throw new IllegalArgumentException("Некорректная ссылка на репозиторий: " + raw);
+        }
+        return new RepoLink(host, owner, name);
+    }
+}
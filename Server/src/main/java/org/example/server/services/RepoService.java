@@ -35,6 +35,7 @@ public class RepoService {
     private final FileRepository fileRepository;
     private final StorageService storageService;
     private final TokensRepository tokensRepository;
+    private final TokenCipher tokenCipher;
     private final GitHubClientImpl gitHubClient;
     private final RepTrackerClient repTrackerClient;
     private final CoderManagerClient coderManagerClient;
@@ -47,6 +48,7 @@ public class RepoService {
                        FileRepository fileRepository,
                        StorageService storageService,
                        TokensRepository tokensRepository,
+                       TokenCipher tokenCipher,
                        GitHubClientImpl gitHubClient,
                        RepTrackerClient repTrackerClient,
                        CoderManagerClient coderManagerClient,
@@ -58,6 +60,7 @@ public class RepoService {
         this.fileRepository = fileRepository;
         this.storageService = storageService;
         this.tokensRepository = tokensRepository;
+        this.tokenCipher = tokenCipher;
         this.gitHubClient = gitHubClient;
         this.repTrackerClient = repTrackerClient;
         this.coderManagerClient = coderManagerClient;
@@ -80,14 +83,16 @@ public class RepoService {
             
             // Step 2: Get or create repository
             log.info("Step 2: Finding/creating repository for URL={}", request.repositoryUrl());
-            Repo repo = repoRepository.findByUrl(request.repositoryUrl())
+            // Store the canonical link so every spelling of a repository maps to one row.
+            RepoLink repoLink = RepoLink.parse(request.repositoryUrl());
+            Repo repo = repoRepository.findByUrl(repoLink.url())
+                    .or(() -> findLegacyRepo(repoLink))
                     .orElseGet(() -> {
-                        log.info("Creating new repository for URL={}", request.repositoryUrl());
+                        log.info("Creating new repository for URL={}", repoLink.url());
                         Repo r = new Repo();
-                        r.setUrl(request.repositoryUrl());
-                        String[] parts = parseOwnerAndName(request.repositoryUrl());
-                        r.setOwner(parts[0]);
-                        r.setName(parts[1]);
+                        r.setUrl(repoLink.url());
+                        r.setOwner(repoLink.owner());
+                        r.setName(repoLink.name());
                         r.setAddedAt(OffsetDateTime.now());
                         Repo saved = repoRepository.save(r);
                         log.info("Created repository: id={}, owner={}, name={}", saved.getId(), saved.getOwner(), saved.getName());
@@ -276,9 +281,7 @@ public class RepoService {
         
         try {
             // Получаем токен пользователя
-            String token = tokensRepository.findByUser(user)
-                    .map(Token::getToken)
-                    .orElseThrow(() -> new IllegalStateException("Нет сохраненного токена пользователя"));
+            String token = requireToken(user);
             
             // Определяем ветку
             String branch = gitHubClient.resolveDefaultBranch(token, repo.getOwner(), repo.getName());
@@ -439,9 +442,7 @@ public class RepoService {
     }
 
     private byte[] downloadAndCacheFile(User user, Repo repo, String path, File file) {
-        String token = tokensRepository.findByUser(user)
-                .map(Token::getToken)
-                .orElseThrow(() -> new IllegalStateException("Нет сохраненного токена пользователя"));
+        String token = requireToken(user);
         String branch = repo.getOwner() != null ? gitHubClient.resolveDefaultBranch(token, repo.getOwner(), repo.getName()) : "main";
         byte[] bytes = gitHubClient.downloadFile(token, repo.getOwner(), repo.getName(), path, branch);
         String objectKey = repo.getId() + "/" + path;
@@ -453,9 +454,7 @@ public class RepoService {
     }
 
     private void syncRepoTreeFromGitHub(User user, Repo repo) {
-        String token = tokensRepository.findByUser(user)
-                .map(Token::getToken)
-                .orElseThrow(() -> new IllegalStateException("Нет сохраненного токена пользователя"));
+        String token = requireToken(user);
         String branch = gitHubClient.resolveDefaultBranch(token, repo.getOwner(), repo.getName());
         List<GitHubTreeItem> items = gitHubClient.fetchRepoTree(token, repo.getOwner(), repo.getName(), branch);
         items.stream()
@@ -490,6 +489,28 @@ public class RepoService {
                 });
     }
 
+    /**
+     * Finds a row stored before links were canonicalized. Owner and name alone are ambiguous
+     * across hosts, so the stored link must parse to the same repository.
+     */
+    private Optional<Repo> findLegacyRepo(RepoLink repoLink) {
+        return repoRepository.findByOwnerIgnoreCaseAndNameIgnoreCaseOrderByIdAsc(repoLink.owner(), repoLink.name()).stream()
+                .filter(candidate -> {
+                    try {
+                        return RepoLink.parse(candidate.getUrl()).equals(repoLink);
+                    } catch (IllegalArgumentException e) {
+                        return false;
+                    }
+                })
+                .findFirst();
+    }
+
+    private String requireToken(User user) {
+        return tokensRepository.findByUserAndHost(user, Token.DEFAULT_HOST)
+                .map(tokenCipher::open)
+                .orElseThrow(() -> new IllegalStateException("Нет сохраненного токена пользователя"));
+    }
+
     private User requireUser(Long chatId) {
         return userRepository.findByChatId(chatId)
                 .orElseThrow(() -> new IllegalArgumentException("Пользователь не найден"));
@@ -500,16 +521,4 @@ public class RepoService {
                 .map(UserRepo::getRepo)
                 .orElseThrow(() -> new IllegalArgumentException("У пользователя не привязан репозиторий"));
     }
-
-    private String[] parseOwnerAndName(String url) {
-        try {
-            String trimmed = url.replaceAll(".git$", "");
-            String[] parts = trimmed.split("/");
-            String owner = parts[parts.length - 2];
-            String name = parts[parts.length - 1];
-            return new String[]{owner, name};
-        } catch (Exception e) {
-            return new String[]{"unknown", "unknown"};
-        }
-    }
 }
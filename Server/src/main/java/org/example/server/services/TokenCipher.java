@@ -0,0 +1,176 @@
+package org.example.server.services;
+
+import org.example.server.model.entity.Token;
+import org.springframework.beans.factory.annotation.Value;
+import org.springframework.stereotype.Component;
+
+import javax.crypto.Cipher;
+import javax.crypto.spec.GCMParameterSpec;
+import javax.crypto.spec.SecretKeySpec;
+import java.io.IOException;
+import java.io.UncheckedIOException;
+import java.nio.charset.StandardCharsets;
+import java.nio.file.Files;
+import java.nio.file.Path;
+import java.security.GeneralSecurityException;
+import java.security.SecureRandom;
+import java.util.Arrays;
+import java.util.Base64;
+import java.util.LinkedHashMap;
+import java.util.List;
+import java.util.Map;
+
+/**
+ * Seals and opens tokens like rep_tracker (package tokencrypt): the token is sealed with its own data key
+ * and the data key with the master key named by key_id, both as base64(nonce || AES-256-GCM ciphertext).
+ * The master keys are read from the same TOKEN_KEYS_FILE, TOKEN_ACTIVE_KEY_ID or TOKEN_MASTER_KEY
+ * settings as rep_tracker.
+ */
+@Component
+public class TokenCipher {
+    private static final int KEY_SIZE = 32;
+    private static final int NONCE_SIZE = 12;
+    private static final int TAG_BITS = 128;
+
+    private final SecureRandom random = new SecureRandom();
+    private final Map<String, byte[]> masterKeys;
+    private final String activeKeyId;
+
+    public TokenCipher(@Value("${token.keys-file:}") String keysFile,
+                       @Value("${token.master-key:}") String masterKey,
+                       @Value("${token.master-key-id:env}") String masterKeyId,
+                       @Value("${token.active-key-id:}") String activeKeyId) {
+        this.masterKeys = loadKeys(keysFile, masterKey, masterKeyId);
+        this.activeKeyId = resolveActiveKeyId(keysFile, activeKeyId);
+    }
+
+    /**
+     * Stores the plaintext in the token sealed with a fresh data key under the active master key.
+     * Without master keys it is stored unchanged; a failure to seal never falls back to plaintext.
+     */
+    public void seal(Token token, String plaintext) {
+        if (activeKeyId == null) {
+            token.setToken(plaintext);
+            token.setKeyId(null);
+            token.setDataKey(null);
+            return;
+        }
+        byte[] dataKey = new byte[KEY_SIZE];
+        random.nextBytes(dataKey);
+        try {
+            String sealedToken = seal(dataKey, plaintext.getBytes(StandardCharsets.UTF_8), null);
+            String sealedKey = seal(masterKeys.get(activeKeyId), dataKey, activeKeyId.getBytes(StandardCharsets.UTF_8));
+            token.setToken(sealedToken);
+            token.setKeyId(activeKeyId);
+            token.setDataKey(sealedKey);
+        } catch (GeneralSecurityException e) {
+            throw new IllegalStateException("Failed to seal token", e);
+        }
+    }
+
+    /** Returns the plaintext token; rows not sealed yet are returned unchanged. */
+    public String open(Token token) {
+        if (token.getKeyId() == null) {
+            return token.getToken();
+        }
+        byte[] masterKey = masterKeys.get(token.getKeyId());
+        if (masterKey == null || token.getDataKey() == null) {
+            throw new IllegalStateException("Unknown token master key " + token.getKeyId()
+                    + ", token encryption is not configured");
+        }
+        try {
+            // The key ID is authenticated so a data key cannot be moved under another master key.
+            byte[] dataKey = open(masterKey, token.getDataKey(), token.getKeyId().getBytes(StandardCharsets.UTF_8));
+            return new String(open(dataKey, token.getToken(), null), StandardCharsets.UTF_8);
+        } catch (GeneralSecurityException | IllegalArgumentException e) {
+            throw new IllegalStateException("Failed to open token " + token.getId(), e);
+        }
+    }
+
+    private String seal(byte[] key, byte[] plaintext, byte[] additional) throws GeneralSecurityException {
+        byte[] nonce = new byte[NONCE_SIZE];
+        random.nextBytes(nonce);
+        Cipher cipher = Cipher.getInstance("AES/GCM/NoPadding");
+        cipher.init(Cipher.ENCRYPT_MODE, new SecretKeySpec(key, "AES"), new GCMParameterSpec(TAG_BITS, nonce));
+        if (additional != null) {
+            cipher.updateAAD(additional);
+        }
+        byte[] ciphertext = cipher.doFinal(plaintext);
+        byte[] raw = Arrays.copyOf(nonce, NONCE_SIZE + ciphertext.length);
+        System.arraycopy(ciphertext, 0, raw, NONCE_SIZE, ciphertext.length);
+        return Base64.getEncoder().encodeToString(raw);
+    }
+
+    private static byte[] open(byte[] key, String sealed, byte[] additional) throws GeneralSecurityException {
+        byte[] raw = Base64.getDecoder().decode(sealed);
+        if (raw.length < NONCE_SIZE) {
+            throw new GeneralSecurityException("ciphertext too short");
+        }
+        Cipher cipher = Cipher.getInstance("AES/GCM/NoPadding");
+        cipher.init(Cipher.DECRYPT_MODE, new SecretKeySpec(key, "AES"),
+                new GCMParameterSpec(TAG_BITS, raw, 0, NONCE_SIZE));
+        if (additional != null) {
+            cipher.updateAAD(additional);
+        }
+        return cipher.doFinal(Arrays.copyOfRange(raw, NONCE_SIZE, raw.length));
+    }
+
+    /**
+     * Returns the key new tokens are sealed with, like rep_tracker: the configured one or the last key
+     * of the keys file, or the single master key. It is null when no keys are configured.
+     */
+    private String resolveActiveKeyId(String keysFile, String configured) {
+        if (masterKeys.isEmpty()) {
+            return null;
+        }
+        if (keysFile != null && !keysFile.isBlank() && configured != null && !configured.isBlank()) {
+            if (!masterKeys.containsKey(configured.trim())) {
+                throw new IllegalStateException("Unknown active token master key " + configured.trim());
+            }
+            return configured.trim();
+        }
+        String last = null;
+        for (String id : masterKeys.keySet()) {
+            last = id;
+        }
+        return last;
+    }
+
+    /** Reads "id=base64key" lines from the keys file in order, or the single master key when no file is set. */
+    private static Map<String, byte[]> loadKeys(String keysFile, String masterKey, String masterKeyId) {
+        Map<String, byte[]> keys = new LinkedHashMap<>();
+        if (keysFile != null && !keysFile.isBlank()) {
+            List<String> lines;
+            try {
+                lines = Files.readAllLines(Path.of(keysFile.trim()));
+            } catch (IOException e) {
+                throw new UncheckedIOException("Failed to read token keys file " + keysFile, e);
+            }
+            for (int i = 0; i < lines.size(); i++) {
+                String entry = lines.get(i).trim();
+                if (entry.isEmpty() || entry.startsWith("#")) {
+                    continue;
+                }
+                int separator = entry.indexOf('=');
+                if (separator <= 0) {
+                    throw new IllegalStateException(keysFile + ":" + (i + 1) + ": expected id=base64key");
+                }
+                String id = entry.substring(0, separator).trim();
+                // A repeated ID counts at its last line, which may make it the active key.
+                keys.remove(id);
+                keys.put(id, decodeKey(entry.substring(separator + 1)));
+            }
+        } else if (masterKey != null && !masterKey.isBlank()) {
+            keys.put(masterKeyId, decodeKey(masterKey));
+        }
+        return keys;
+    }
+
+    private static byte[] decodeKey(String encoded) {
+        byte[] key = Base64.getDecoder().decode(encoded.trim());
+        if (key.length != KEY_SIZE) {
+            throw new IllegalStateException("Token master key must be " + KEY_SIZE + " bytes, got " + key.length);
+        }
+        return key;
+    }
+}
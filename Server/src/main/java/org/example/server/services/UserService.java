@@ -1,5 +1,6 @@
 package org.example.server.services;
 
+import org.example.server.integrations.RepTrackerClient;
 import org.example.server.model.entity.Token;
 import org.example.server.model.entity.User;
 import org.example.server.repos.TokensRepository;
@@ -16,12 +17,17 @@ public class UserService {
     private final UserRepository userRepository;
     private final GitHubClientImpl gitHubClient;
     private final TokensRepository tokensRepository;
+    private final RepTrackerClient repTrackerClient;
+    private final TokenCipher tokenCipher;
     private static final Logger log = LoggerFactory.getLogger(UserService.class);
 
-    public UserService(UserRepository userRepository, GitHubClientImpl gitHubClient, TokensRepository tokensRepository) {
+    public UserService(UserRepository userRepository, GitHubClientImpl gitHubClient, TokensRepository tokensRepository,
+                       RepTrackerClient repTrackerClient, TokenCipher tokenCipher) {
         this.userRepository = userRepository;
         this.gitHubClient = gitHubClient;
         this.tokensRepository = tokensRepository;
+        this.repTrackerClient = repTrackerClient;
+        this.tokenCipher = tokenCipher;
     }
 
     public String register(Long id, String name){
@@ -42,14 +48,20 @@ public class UserService {
 
 
     public boolean token(Long id, String token){
-        Token entity = new Token();
         Optional<User> user = userRepository.findByChatId(id);
         if(user.isPresent()){
+            Token entity = tokensRepository.findByUserAndHost(user.get(), Token.DEFAULT_HOST).orElseGet(Token::new);
             entity.setUser(user.get());
             gitHubClient.validateToken(token);
-            entity.setToken(token);
+            // Sealed before it is saved, so the token is never stored in plaintext when keys are configured.
+            tokenCipher.seal(entity, token);
             entity.setCreatedAt(OffsetDateTime.now());
             tokensRepository.save(entity);
+            try {
+                repTrackerClient.refreshToken(id);
+            } catch (Exception e) {
+                log.warn("Failed to re-enable tracking after token refresh for chatId={}", id, e);
+            }
         }else{
             return false;
         }
@@ -0,0 +1,3 @@
+ALTER TABLE NOTIFICATIONS ADD COLUMN PAUSED_UNTIL TIMESTAMPTZ;
+
+CREATE INDEX NOTIFICATIONS_PAUSED_UNTIL_IND ON NOTIFICATIONS(PAUSED_UNTIL) WHERE PAUSED_UNTIL IS NOT NULL;
@@ -0,0 +1,3 @@
+ALTER TABLE NOTIFICATIONS ADD COLUMN BRANCHES JSONB NOT NULL DEFAULT '[]'::JSONB;
+
+CREATE INDEX COMMITS_BRANCH_CREATED_IND ON COMMITS(BRANCH_ID, CREATED_AT);
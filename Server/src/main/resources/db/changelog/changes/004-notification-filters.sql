@@ -0,0 +1 @@
+ALTER TABLE NOTIFICATIONS ADD COLUMN FILTERS JSONB NOT NULL DEFAULT '{}'::JSONB;
@@ -0,0 +1,5 @@
+ALTER TABLE NOTIFICATIONS ADD COLUMN DISABLE_REASON TEXT;
+
+UPDATE NOTIFICATIONS SET DISABLE_REASON = 'PAUSED' WHERE ENABLED = FALSE AND PAUSED_UNTIL IS NOT NULL;
+
+CREATE INDEX NOTIFICATIONS_USER_DISABLE_REASON_IND ON NOTIFICATIONS(USER_ID, DISABLE_REASON) WHERE DISABLE_REASON IS NOT NULL;
@@ -0,0 +1 @@
+CREATE INDEX REPOS_OWNER_NAME_LOWER_IND ON REPOS(LOWER(OWNER), LOWER(NAME));
@@ -0,0 +1,5 @@
+ALTER TABLE TOKENS ADD COLUMN HOST TEXT NOT NULL DEFAULT 'github.com';
+
+ALTER TABLE TOKENS DROP CONSTRAINT IF EXISTS TOKENS_USER_ID_KEY;
+
+ALTER TABLE TOKENS ADD CONSTRAINT TOKENS_USER_HOST_KEY UNIQUE (USER_ID, HOST);
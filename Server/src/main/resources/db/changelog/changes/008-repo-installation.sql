@@ -0,0 +1 @@
+ALTER TABLE REPOS ADD COLUMN INSTALLATION_ID BIGINT;
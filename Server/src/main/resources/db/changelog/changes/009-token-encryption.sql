@@ -0,0 +1,3 @@
+ALTER TABLE TOKENS ADD COLUMN KEY_ID TEXT;
+
+ALTER TABLE TOKENS ADD COLUMN DATA_KEY TEXT;
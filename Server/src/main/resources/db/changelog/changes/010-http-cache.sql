@@ -0,0 +1,13 @@
+CREATE TABLE HTTP_CACHE_ENTRIES(
+    TOKEN_KEY TEXT NOT NULL,
+    URL TEXT NOT NULL,
+    ETAG TEXT,
+    LAST_MODIFIED TEXT,
+    CONTENT_TYPE TEXT,
+    LINK TEXT,
+    BODY BYTEA NOT NULL,
+    UPDATED_AT TIMESTAMPTZ NOT NULL,
+    PRIMARY KEY (TOKEN_KEY, URL)
+);
+
+CREATE INDEX HTTP_CACHE_ENTRIES_UPDATED_AT_IND ON HTTP_CACHE_ENTRIES(UPDATED_AT);
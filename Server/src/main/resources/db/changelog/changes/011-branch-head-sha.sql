@@ -0,0 +1 @@
+ALTER TABLE BRANCHES ADD COLUMN HEAD_SHA TEXT;
@@ -0,0 +1 @@
+ALTER TABLE COMMITS ADD COLUMN DROPPED_AT TIMESTAMPTZ;
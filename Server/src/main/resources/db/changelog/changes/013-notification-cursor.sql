@@ -0,0 +1,13 @@
+ALTER TABLE NOTIFICATIONS ADD COLUMN BRANCH_HEADS JSONB NOT NULL DEFAULT '{}'::JSONB;
+
+ALTER TABLE NOTIFICATIONS ADD COLUMN CURSOR_SINCE TIMESTAMPTZ;
+
+UPDATE NOTIFICATIONS N
+SET BRANCH_HEADS = HEADS.BRANCH_HEADS
+FROM (
+    SELECT REPO_ID, JSONB_OBJECT_AGG(NAME, HEAD_SHA) AS BRANCH_HEADS
+    FROM BRANCHES
+    WHERE HEAD_SHA IS NOT NULL
+    GROUP BY REPO_ID
+) HEADS
+WHERE HEADS.REPO_ID = N.REPO_ID;
@@ -0,0 +1,5 @@
+DELETE FROM HTTP_CACHE_ENTRIES;
+
+ALTER TABLE HTTP_CACHE_ENTRIES ADD COLUMN KEY_ID TEXT NOT NULL;
+
+ALTER TABLE HTTP_CACHE_ENTRIES ADD COLUMN DATA_KEY TEXT NOT NULL;
@@ -0,0 +1 @@
+ALTER TABLE BRANCHES DROP COLUMN HEAD_SHA;
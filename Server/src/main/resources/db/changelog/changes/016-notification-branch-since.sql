@@ -0,0 +1,14 @@
+ALTER TABLE NOTIFICATIONS ADD COLUMN BRANCH_SINCE JSONB NOT NULL DEFAULT '{}'::JSONB;
+
+UPDATE NOTIFICATIONS N
+SET BRANCH_SINCE = SINCE.BRANCH_SINCE
+FROM (
+    SELECT HEADS.ID, JSONB_OBJECT_AGG(HEADS.KEY, C.CREATED_AT) AS BRANCH_SINCE
+    FROM (
+        SELECT NOTIFICATIONS.ID, NOTIFICATIONS.REPO_ID, BRANCH_HEAD.KEY, BRANCH_HEAD.VALUE
+        FROM NOTIFICATIONS, JSONB_EACH_TEXT(NOTIFICATIONS.BRANCH_HEADS) BRANCH_HEAD
+    ) HEADS
+    JOIN COMMITS C ON C.REPO_ID = HEADS.REPO_ID AND C.COMMIT_HASH = HEADS.VALUE
+    GROUP BY HEADS.ID
+) SINCE
+WHERE SINCE.ID = N.ID;
@@ -0,0 +1,12 @@
+CREATE TABLE COMMIT_BRANCHES(
+    COMMIT_ID BIGINT NOT NULL REFERENCES COMMITS(ID) ON DELETE CASCADE,
+    BRANCH_ID INT NOT NULL REFERENCES BRANCHES(ID) ON DELETE CASCADE,
+    PRIMARY KEY (COMMIT_ID, BRANCH_ID)
+);
+
+CREATE INDEX COMMIT_BRANCHES_BRANCH_ID_IND ON COMMIT_BRANCHES(BRANCH_ID);
+
+INSERT INTO COMMIT_BRANCHES (COMMIT_ID, BRANCH_ID)
+SELECT ID, BRANCH_ID
+FROM COMMITS
+WHERE BRANCH_ID IS NOT NULL;
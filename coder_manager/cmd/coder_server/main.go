@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
@@ -18,14 +19,20 @@ import (
 	"coder_manager/pkg/coder_client"
 	dao "coder_manager/pkg/dao"
 	"coder_manager/pkg/file_storage"
+	"coder_manager/pkg/metrics"
 	"coder_manager/pkg/notifier"
 	"coder_manager/pkg/proto"
 	"coder_manager/pkg/repo"
+	"service_common/grpc_kit"
+	"service_common/health"
+	"service_common/tokencrypt"
 
 	"github.com/coder/coder/v2/codersdk"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"google.golang.org/grpc"
+	grpchealth "google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -47,6 +54,11 @@ func main() {
 	proxyBaseURL := strings.TrimSpace(os.Getenv("PROXY_BASE_URL"))
 	tokenQueryParam := envOrDefault("CODER_TOKEN_QUERY_PARAM", codersdk.SessionTokenCookie)
 	grpcPort := envOrDefault("GRPC_PORT", "9090")
+	// An explicitly empty METRICS_ADDR disables the metrics endpoint.
+	metricsAddr, ok := os.LookupEnv("METRICS_ADDR")
+	if !ok {
+		metricsAddr = ":2112"
+	}
 	sessionSaverPeriod, err := durationFromEnv("SESSION_SAVER_PERIOD", 30*time.Second)
 	if err != nil {
 		zap.S().Fatalw("config load failed", "error", err)
@@ -92,6 +104,26 @@ func main() {
 	if err != nil {
 		zap.S().Fatalw("config load failed", "error", err)
 	}
+	tlsCfg, err := loadTLSConfig()
+	if err != nil {
+		zap.S().Fatalw("config load failed", "error", err)
+	}
+	authCfg, err := loadAuthConfig()
+	if err != nil {
+		zap.S().Fatalw("config load failed", "error", err)
+	}
+	interceptorCfg, err := loadInterceptorConfig()
+	if err != nil {
+		zap.S().Fatalw("config load failed", "error", err)
+	}
+	healthInterval, err := durationFromEnv("HEALTH_CHECK_INTERVAL", 10*time.Second)
+	if err != nil {
+		zap.S().Fatalw("config load failed", "error", err)
+	}
+	healthTimeout, err := durationFromEnv("HEALTH_CHECK_TIMEOUT", 3*time.Second)
+	if err != nil {
+		zap.S().Fatalw("config load failed", "error", err)
+	}
 	notifyEndpoint := strings.TrimSpace(os.Getenv("FILE_SAVE_NOTIFY_URL"))
 	notifyTimeout, err := durationFromEnv("FILE_SAVE_NOTIFY_TIMEOUT", 5*time.Second)
 	if err != nil {
@@ -109,7 +141,16 @@ func main() {
 		zap.S().Fatalw("db migrate failed", "error", err)
 	}
 
-	repoStore := repo.NewGormRepo(db)
+	tokenKeyring, err := tokencrypt.LoadKeyring(
+		strings.TrimSpace(os.Getenv("TOKEN_KEYS_FILE")),
+		strings.TrimSpace(os.Getenv("TOKEN_ACTIVE_KEY_ID")),
+		os.Getenv("TOKEN_MASTER_KEY"),
+		envOrDefault("TOKEN_MASTER_KEY_ID", "env"),
+	)
+	if err != nil {
+		zap.S().Fatalw("token keyring load failed", "error", err)
+	}
+	repoStore := repo.NewGormRepo(db, tokenKeyring)
 	storage, err := file_storage.NewS3Storage(s3Cfg)
 	if err != nil {
 		zap.S().Fatalw("s3 init failed", "error", err)
@@ -133,17 +174,74 @@ func main() {
 	go saver.Run(ctx)
 	go activeSaver.Run(ctx)
 
+	checker := health.NewChecker(healthInterval, healthTimeout).
+		Add("postgres", health.DBCheck(db)).
+		Add("coder", health.HTTPCheck(strings.TrimRight(coderCfg.URL, "/")+"/healthz"))
+	go checker.Run(ctx)
+
+	if metricsAddr != "" {
+		go func() {
+			routes := map[string]http.Handler{"/readyz": health.ProbeHandler(checker.ReadinessProbe)}
+			if err := metrics.Serve(ctx, metricsAddr, routes); err != nil {
+				zap.S().Errorw("metrics server stopped", "error", err)
+			}
+		}()
+	}
+
 	port := grpcPort
 	listener, err := net.Listen("tcp", ":"+port)
 	if err != nil {
 		zap.S().Fatalw("listen error", "error", err)
 	}
 
-	server := grpc.NewServer()
+	serverOpts := make([]grpc.ServerOption, 0, 3)
+	if tlsCfg.Enabled() {
+		creds, err := grpc_kit.NewServerCredentials(ctx, tlsCfg)
+		if err != nil {
+			zap.S().Fatalw("tls init failed", "error", err)
+		}
+		serverOpts = append(serverOpts, grpc.Creds(creds))
+	}
+	var unaryExtra []grpc.UnaryServerInterceptor
+	var streamExtra []grpc.StreamServerInterceptor
+	if authCfg.Enabled() {
+		auth, err := grpc_kit.NewAuthenticator(ctx, authCfg)
+		if err != nil {
+			zap.S().Fatalw("auth init failed", "error", err)
+		}
+		unaryExtra = append(unaryExtra, auth.UnaryInterceptor())
+		streamExtra = append(streamExtra, auth.StreamInterceptor())
+	} else {
+		zap.S().Warnw("grpc server started without caller authentication", "port", port)
+	}
+	serverOpts = append(serverOpts,
+		grpc.ChainUnaryInterceptor(interceptorCfg.UnaryInterceptors(unaryExtra...)...),
+		grpc.ChainStreamInterceptor(interceptorCfg.StreamInterceptors(streamExtra...)...))
+	server := grpc.NewServer(serverOpts...)
 	proto.RegisterCoderManagerServiceServer(server, grpc_server.NewCoderManagerServer(service))
 	reflection.Register(server)
+	healthServer := grpchealth.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	setServingStatus := func(servingStatus healthpb.HealthCheckResponse_ServingStatus) {
+		healthServer.SetServingStatus("", servingStatus)
+		healthServer.SetServingStatus(proto.CoderManagerService_ServiceDesc.ServiceName, servingStatus)
+	}
+	setServingStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	checker.OnChange(func(healthy bool) {
+		if healthy {
+			setServingStatus(healthpb.HealthCheckResponse_SERVING)
+		} else {
+			setServingStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+		}
+	})
+	go func() {
+		<-ctx.Done()
+		// Report NOT_SERVING first so clients stop sending new calls while in-flight ones finish.
+		healthServer.Shutdown()
+		server.GracefulStop()
+	}()
 
-	zap.S().Infow("coder manager grpc listening", "port", port)
+	zap.S().Infow("coder manager grpc listening", "port", port, "tls", tlsCfg.Enabled(), "mtls", tlsCfg.ClientCAFile != "")
 	if err := server.Serve(listener); err != nil {
 		zap.S().Fatalw("server error", "error", err)
 	}
@@ -253,6 +351,74 @@ func loadS3Config() (file_storage.Config, error) {
 	}, nil
 }
 
+func loadTLSConfig() (grpc_kit.TLSConfig, error) {
+	reloadInterval, err := durationFromEnv("GRPC_TLS_RELOAD_INTERVAL", 30*time.Second)
+	if err != nil {
+		return grpc_kit.TLSConfig{}, err
+	}
+	allowedClients := make([]string, 0)
+	for _, client := range strings.Split(os.Getenv("GRPC_TLS_ALLOWED_CLIENTS"), ",") {
+		if client = strings.TrimSpace(client); client != "" {
+			allowedClients = append(allowedClients, client)
+		}
+	}
+	cfg := grpc_kit.TLSConfig{
+		CertFile:       strings.TrimSpace(os.Getenv("GRPC_TLS_CERT_FILE")),
+		KeyFile:        strings.TrimSpace(os.Getenv("GRPC_TLS_KEY_FILE")),
+		ClientCAFile:   strings.TrimSpace(os.Getenv("GRPC_TLS_CLIENT_CA_FILE")),
+		AllowedClients: allowedClients,
+		ReloadInterval: reloadInterval,
+	}
+	if err := cfg.Validate(); err != nil {
+		return grpc_kit.TLSConfig{}, err
+	}
+	return cfg, nil
+}
+
+func loadAuthConfig() (grpc_kit.AuthConfig, error) {
+	reloadInterval, err := durationFromEnv("GRPC_AUTH_RELOAD_INTERVAL", 30*time.Second)
+	if err != nil {
+		return grpc_kit.AuthConfig{}, err
+	}
+	return grpc_kit.AuthConfig{
+		KeysFile:       strings.TrimSpace(os.Getenv("GRPC_AUTH_KEYS_FILE")),
+		ReloadInterval: reloadInterval,
+	}, nil
+}
+
+// loadInterceptorConfig reads GRPC_DEFAULT_TIMEOUT and GRPC_METHOD_TIMEOUTS ("Method=duration" pairs).
+func loadInterceptorConfig() (grpc_kit.InterceptorConfig, error) {
+	defaultTimeout, err := durationFromEnv("GRPC_DEFAULT_TIMEOUT", 30*time.Second)
+	if err != nil {
+		return grpc_kit.InterceptorConfig{}, err
+	}
+	methodTimeouts := make(map[string]time.Duration, len(grpc_server.CoderManagerMethodTimeouts))
+	for method, timeout := range grpc_server.CoderManagerMethodTimeouts {
+		methodTimeouts[method] = timeout
+	}
+	for _, pair := range strings.Split(os.Getenv("GRPC_METHOD_TIMEOUTS"), ",") {
+		method, rawTimeout, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		timeout, err := time.ParseDuration(strings.TrimSpace(rawTimeout))
+		if err != nil {
+			return grpc_kit.InterceptorConfig{}, err
+		}
+		method = strings.TrimSpace(method)
+		if !strings.HasPrefix(method, "/") {
+			method = "/coder_manager.CoderManagerService/" + method
+		}
+		methodTimeouts[method] = timeout
+	}
+	return grpc_kit.InterceptorConfig{
+		DefaultTimeout:  defaultTimeout,
+		MethodTimeouts:  methodTimeouts,
+		Requests:        metrics.GrpcRequests,
+		RequestDuration: metrics.GrpcRequestDuration,
+	}, nil
+}
+
 func requireEnv(key string) (string, error) {
 	value := os.Getenv(key)
 	if value == "" {
@@ -13,12 +13,14 @@ require (
 	github.com/coder/coder/v2 v2.29.1
 	github.com/google/go-github/v62 v62.0.0
 	github.com/google/uuid v1.6.0
-	go.uber.org/zap v1.27.0
+	github.com/prometheus/client_golang v1.23.2
+	go.uber.org/zap v1.27.1
 	golang.org/x/oauth2 v0.33.0
 	google.golang.org/grpc v1.78.0
 	google.golang.org/protobuf v1.36.11
 	gorm.io/driver/postgres v1.6.0
 	gorm.io/gorm v1.31.1
+	service_common v0.0.0
 )
 
 require (
@@ -159,7 +161,6 @@ require (
 	github.com/planetscale/vtprotobuf v0.6.1-0.20240319094008-0393e58bdf10 // indirect
 	github.com/pmezard/go-difflib v1.0.1-0.20181226105442-5d4384ee4fb2 // indirect
 	github.com/power-devops/perfstat v0.0.0-20240221224432-82ca36839d55 // indirect
-	github.com/prometheus/client_golang v1.23.2 // indirect
 	github.com/prometheus/client_model v0.6.2 // indirect
 	github.com/prometheus/common v0.67.4 // indirect
 	github.com/prometheus/procfs v0.16.1 // indirect
@@ -235,3 +236,5 @@ require (
 	storj.io/drpc v0.0.33 // indirect
 	tailscale.com v1.80.3 // indirect
 )
+
+replace service_common => ../service_common
@@ -18,6 +18,7 @@ import (
 	"coder_manager/internal/notifier"
 	"coder_manager/internal/repo"
 	dao "coder_manager/pkg/dao"
+	"coder_manager/pkg/metrics"
 
 	"github.com/coder/coder/v2/codersdk"
 	"go.uber.org/zap"
@@ -74,6 +75,13 @@ func NewService(repo repo.CoderRepo, coderClient coder_client.CoderClient, stora
 }
 
 func (s *Service) CreateEditorSession(ctx context.Context, req CreateEditorSessionRequest) (*CreateEditorSessionResponse, error) {
+	start := time.Now()
+	response, err := s.createEditorSession(ctx, req)
+	metrics.ObserveEditorSession("create", start, err)
+	return response, err
+}
+
+func (s *Service) createEditorSession(ctx context.Context, req CreateEditorSessionRequest) (*CreateEditorSessionResponse, error) {
 	pathValue := strings.TrimSpace(req.Path)
 	chatID := strings.TrimSpace(req.ChatID)
 	s3Key := strings.TrimSpace(req.S3Key)
@@ -336,6 +344,13 @@ func storageKeyForSession(session *dao.EditorSession) string {
 }
 
 func (s *Service) saveSessionFile(ctx context.Context, session *dao.EditorSession, savedAt time.Time, expireSession bool) (string, error) {
+	start := time.Now()
+	storageKey, err := s.persistSessionFile(ctx, session, savedAt, expireSession)
+	metrics.ObserveEditorSession("save", start, err)
+	return storageKey, err
+}
+
+func (s *Service) persistSessionFile(ctx context.Context, session *dao.EditorSession, savedAt time.Time, expireSession bool) (string, error) {
 	reader, err := s.coderClient.DownloadFile(ctx, coder_client.DownloadFileRequest{
 		WorkspaceID: session.WorkspaceID,
 		Path:        session.File.Path,
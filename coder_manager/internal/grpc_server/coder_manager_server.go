@@ -6,17 +6,23 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"time"
 
 	"coder_manager/internal/coder_service"
 	"coder_manager/internal/repo"
 	"coder_manager/pkg/proto"
 
-	"go.uber.org/zap"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+// CoderManagerMethodTimeouts are deadline defaults for calls that wait on Coder workspaces or S3.
+var CoderManagerMethodTimeouts = map[string]time.Duration{
+	proto.CoderManagerService_CreateEditorSession_FullMethodName: 10 * time.Minute,
+	proto.CoderManagerService_SaveEditorSession_FullMethodName:   5 * time.Minute,
+}
+
 type CoderManagerServer struct {
 	service *coder_service.Service
 	proto.UnimplementedCoderManagerServiceServer
@@ -34,7 +40,6 @@ func (s *CoderManagerServer) CreateEditorSession(ctx context.Context, req *proto
 		TTLSeconds: req.GetTtlSeconds(),
 	})
 	if err != nil {
-		zap.S().Errorw("create editor session failed", "error", err)
 		return nil, convertError(err)
 	}
 	var expiresAt *timestamppb.Timestamp
@@ -57,7 +62,6 @@ func (s *CoderManagerServer) SaveEditorSession(ctx context.Context, req *proto.S
 		SessionID: sessionID,
 	})
 	if err != nil {
-		zap.S().Errorw("save editor session failed", "session_id", req.GetSessionId(), "error", err)
 		return nil, convertError(err)
 	}
 	var savedAt *timestamppb.Timestamp
@@ -10,6 +10,9 @@ const (
 	FileStateDeleted  FileState = "DELETED"
 )
 
+// DefaultTokenHost is the tokens.host of github.com tokens.
+const DefaultTokenHost = "github.com"
+
 type User struct {
 	ID        int       `gorm:"column:id;primaryKey;autoIncrement"`
 	ChatID    string    `gorm:"column:chat_id;unique;not null"`
@@ -25,10 +28,14 @@ type User struct {
 
 type Token struct {
 	ID             int        `gorm:"column:id;primaryKey;autoIncrement"`
-	UserID         int        `gorm:"column:user_id;unique;not null"`
+	UserID         int        `gorm:"column:user_id;not null"`
+	Host           string     `gorm:"column:host;not null;default:github.com"`
 	Token          string     `gorm:"column:token;size:256;unique;not null"`
 	CreatedAt      time.Time  `gorm:"column:created_at;autoCreateTime"`
 	LastValidateAt *time.Time `gorm:"column:last_validate_at"`
+	// KeyID and DataKey are set when Token is sealed, see service_common/tokencrypt.
+	KeyID   *string `gorm:"column:key_id"`
+	DataKey *string `gorm:"column:data_key"`
 
 	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
 }
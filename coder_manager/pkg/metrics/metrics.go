@@ -0,0 +1,71 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+const namespace = "coder_manager"
+
+var (
+	EditorSessionDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "editor_session_duration_seconds",
+		Help:      "Latency of editor session operations (create includes waiting for the workspace).",
+		Buckets:   []float64{0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"operation"})
+	EditorSessionFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "editor_session_failures_total",
+		Help:      "Failed editor session operations.",
+	}, []string{"operation"})
+
+	GrpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_server_handled_total",
+		Help:      "gRPC calls handled by the server by method and code.",
+	}, []string{"method", "code"})
+	GrpcRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_server_handling_seconds",
+		Help:      "gRPC call latency by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+// ObserveEditorSession records the latency and, on error, a failure of an editor session operation.
+func ObserveEditorSession(operation string, start time.Time, err error) {
+	EditorSessionDuration.WithLabelValues(operation).Observe(time.Since(start).Seconds())
+	if err != nil {
+		EditorSessionFailures.WithLabelValues(operation).Inc()
+	}
+}
+
+// Serve exposes /metrics and the extra routes (e.g. health probes) on addr until ctx is done.
+func Serve(ctx context.Context, addr string, routes map[string]http.Handler) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	for pattern, handler := range routes {
+		mux.Handle(pattern, handler)
+	}
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	zap.S().Infow("metrics server listening", "addr", addr)
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
@@ -8,16 +8,19 @@ import (
 
 	internalrepo "coder_manager/internal/repo"
 	dao "coder_manager/pkg/dao"
+	"service_common/tokencrypt"
 
 	"gorm.io/gorm"
 )
 
 type GormRepo struct {
-	db *gorm.DB
+	db      *gorm.DB
+	keyring *tokencrypt.Keyring
 }
 
-func NewGormRepo(db *gorm.DB) *GormRepo {
-	return &GormRepo{db: db}
+// NewGormRepo returns a repo opening sealed tokens with the keyring; nil only reads plaintext tokens.
+func NewGormRepo(db *gorm.DB, keyring *tokencrypt.Keyring) *GormRepo {
+	return &GormRepo{db: db, keyring: keyring}
 }
 
 func (r *GormRepo) GetUserToken(ctx context.Context, chatID string) (string, error) {
@@ -29,13 +32,14 @@ func (r *GormRepo) GetUserToken(ctx context.Context, chatID string) (string, err
 		return "", err
 	}
 	var token dao.Token
-	if err := r.db.WithContext(ctx).Where("user_id = ?", user.ID).Order("created_at desc, id desc").First(&token).Error; err != nil {
+	// Editor sessions are opened for github.com repositories only.
+	if err := r.db.WithContext(ctx).Where("user_id = ? AND host = ?", user.ID, dao.DefaultTokenHost).Order("created_at desc, id desc").First(&token).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			return "", internalrepo.ErrTokenNotFound
 		}
 		return "", err
 	}
-	return token.Token, nil
+	return r.keyring.Open(tokencrypt.Sealed{Token: token.Token, KeyID: token.KeyID, DataKey: token.DataKey})
 }
 
 func (r *GormRepo) CreateEditorSession(ctx context.Context, params internalrepo.CreateSessionParams) (*dao.EditorSession, error) {
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	scheduler2 "rep_tracker/pkg/scheduler"
@@ -13,13 +14,26 @@ import (
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
 	"gorm.io/driver/postgres"
 	gormio "gorm.io/gorm"
 
+	"rep_tracker/internal/grpc_server"
+	"rep_tracker/internal/notification"
 	"rep_tracker/internal/tasks"
+	"rep_tracker/pkg/forge"
+	"rep_tracker/pkg/gitea"
 	"rep_tracker/pkg/github"
+	"rep_tracker/pkg/gitlab"
 	repgorm "rep_tracker/pkg/gorm"
+	"rep_tracker/pkg/httpcache"
 	"rep_tracker/pkg/kafka"
+	"rep_tracker/pkg/metrics"
+	"rep_tracker/pkg/proto"
+	"rep_tracker/pkg/stream"
+	"service_common/grpc_kit"
+	"service_common/health"
+	"service_common/tokencrypt"
 )
 
 func main() {
@@ -42,6 +56,9 @@ func main() {
 	if err != nil {
 		zap.L().Fatal("invalid config", zap.Error(err))
 	}
+	if cfg.tokenKeyring == nil {
+		zap.L().Warn("token encryption is not configured, tokens are stored in plaintext")
+	}
 	
 	zap.L().Info("configuration loaded", 
 		zap.String("dbDSN", cfg.dbDSN),
@@ -60,34 +77,94 @@ func main() {
 
 	zap.L().Info("initializing repositories")
 	globalRepo := repgorm.NewGormSchedulerRepo(db)
-	tokenRepo := repgorm.NewGormTokenRepo(db)
-	ghClient := github.NewGithubClient()
-	
-	zap.L().Info("initializing kafka writer")
-	writer, err := kafka.NewKafkaNotificationWriter(kafka.KafkaNotificationWriterConfig{
-		Addr:         cfg.kafkaBrokers,
-		Topic:        cfg.kafkaTopic,
-		MaxAttempts:  cfg.kafkaMaxAttempts,
-		BatchSize:    cfg.kafkaBatchSize,
-		BatchTimeout: cfg.kafkaBatchTimeout,
-		WriteTimeout: cfg.kafkaWriteTimeout,
-	})
+	tokenRepo := repgorm.NewGormTokenRepo(db, cfg.tokenKeyring)
+	responseCache := newResponseCache(cfg, db)
+	forgeClient, err := newForge(cfg, globalRepo, responseCache)
 	if err != nil {
-		zap.L().Fatal("kafka writer init failed", zap.Error(err))
+		zap.L().Fatal("forge client init failed", zap.Error(err))
+	}
+
+	
+	writers := make([]notification.NotificationWriter, 0, 1)
+	if len(cfg.kafkaBrokers) > 0 {
+		zap.L().Info("initializing kafka writer")
+		kafkaWriter, err := kafka.NewKafkaNotificationWriter(kafka.KafkaNotificationWriterConfig{
+			Addr:         cfg.kafkaBrokers,
+			Topic:        cfg.kafkaTopic,
+			MaxAttempts:  cfg.kafkaMaxAttempts,
+			BatchSize:    cfg.kafkaBatchSize,
+			BatchTimeout: cfg.kafkaBatchTimeout,
+			WriteTimeout: cfg.kafkaWriteTimeout,
+		})
+		if err != nil {
+			zap.L().Fatal("kafka writer init failed", zap.Error(err))
+		}
+		defer kafkaWriter.Close()
+		writers = append(writers, kafkaWriter)
+	}
+
+	var changeStream *stream.BroadcastNotificationWriter
+	if cfg.watchEnabled {
+		zap.L().Info("initializing change stream writer")
+		changeStream = stream.NewBroadcastNotificationWriter(stream.BroadcastNotificationWriterConfig{
+			BacklogSize:      cfg.watchBacklogSize,
+			SubscriberBuffer: cfg.watchSubscriberBuffer,
+		})
+		defer changeStream.Close()
+	}
+	writer := notification.NewMultiNotificationWriter(writers...)
+	if changeStream != nil {
+		// The stream is best-effort, a notification is streamed once Kafka accepted it.
+		writer.WithBestEffort(changeStream)
 	}
-	defer writer.Close()
 
 	zap.L().Info("initializing check commits function")
-	checkFunc := tasks.GetCheckCommitsFunc(cfg.trackBatchSize, globalRepo, tokenRepo, ghClient, writer)
+	checkFunc := tasks.GetCheckCommitsFunc(cfg.trackBatchSize, cfg.trackMaxCommits, globalRepo, tokenRepo, forgeClient, writer)
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	checker := health.NewChecker(cfg.healthInterval, cfg.healthTimeout).
+		Add("postgres", health.DBCheck(db))
+	if len(cfg.kafkaBrokers) > 0 {
+		checker.Add("kafka", health.TCPCheck(cfg.kafkaBrokers))
+	}
+	go checker.Run(ctx)
+	if gormCache, ok := responseCache.(*repgorm.GormHTTPCache); ok {
+		go purgeResponseCache(ctx, gormCache)
+	}
+	cfg.watchGrpc.HealthChecker = checker
+
+	var scheduler scheduler2.Scheduler
+	if cfg.metricsAddr != "" {
+		go func() {
+			routes := map[string]http.Handler{
+				"/livez":  health.ProbeHandler(scheduler.LivenessProbe(cfg.schedulerStaleAfter)),
+				"/readyz": health.ProbeHandler(checker.ReadinessProbe),
+			}
+			if err := metrics.Serve(ctx, cfg.metricsAddr, routes); err != nil {
+				zap.L().Error("metrics server stopped with error", zap.Error(err))
+			}
+		}()
+	}
+
+	if changeStream != nil {
+		go func() {
+			zap.L().Info("starting change stream grpc server", zap.String("addr", cfg.watchGrpc.Addr))
+			err := grpc_server.ConfigureGrpcServerAndServer(ctx, &cfg.watchGrpc, func(s grpc.ServiceRegistrar) {
+				proto.RegisterRepTrackerServiceServer(s, grpc_server.NewChangeStreamServer(changeStream))
+			})
+			if err != nil {
+				zap.L().Fatal("change stream grpc server stopped with error", zap.Error(err))
+			}
+		}()
+	}
+
 	zap.L().Info("starting scheduler", 
 		zap.Duration("trackInterval", cfg.trackInterval),
-		zap.Int("trackBatchSize", cfg.trackBatchSize))
+		zap.Int("trackBatchSize", cfg.trackBatchSize),
+		zap.Int("trackMaxCommits", cfg.trackMaxCommits))
 
-	var scheduler scheduler2.Scheduler
 	scheduler.Run(ctx, cfg.trackInterval, checkFunc)
 }
 
@@ -127,15 +204,101 @@ func parseLogLevel(raw string) zapcore.Level {
 }
 
 type appConfig struct {
-	dbDSN             string
-	kafkaBrokers      []string
-	kafkaTopic        string
-	kafkaMaxAttempts  int
-	kafkaBatchSize    int
-	kafkaBatchTimeout time.Duration
-	kafkaWriteTimeout time.Duration
-	trackBatchSize    int
-	trackInterval     time.Duration
+	dbDSN                 string
+	kafkaBrokers          []string
+	kafkaTopic            string
+	kafkaMaxAttempts      int
+	kafkaBatchSize        int
+	kafkaBatchTimeout     time.Duration
+	kafkaWriteTimeout     time.Duration
+	trackBatchSize        int
+	trackMaxCommits       int
+	trackInterval         time.Duration
+	watchEnabled          bool
+	watchBacklogSize      int
+	watchSubscriberBuffer int
+	watchGrpc             grpc_server.GrpcServerConfig
+	metricsAddr           string
+	healthInterval        time.Duration
+	healthTimeout         time.Duration
+	schedulerStaleAfter   time.Duration
+	githubHosts           []forge.HostConfig
+	gitlabHosts           []forge.HostConfig
+	giteaHosts            []forge.HostConfig
+	tokenKeyring          *tokencrypt.Keyring
+	githubApp             *github.AppConfig
+	httpCache             string
+	httpCacheTTL          time.Duration
+	httpCacheEntries      int
+}
+
+// newForge builds the forge registry routing links to GitHub, GitLab and Gitea by host.
+func newForge(cfg appConfig, installations github.InstallationStore, cache httpcache.Store) (*forge.Registry, error) {
+	ghClient, err := github.NewGithubClient(cfg.githubHosts...)
+	if err != nil {
+		return nil, fmt.Errorf("github client init: %w", err)
+	}
+	if cache != nil {
+		ghClient.EnableResponseCache(cache)
+	}
+	if cfg.githubApp != nil {
+		if err := ghClient.EnableApp(*cfg.githubApp, installations); err != nil {
+			return nil, fmt.Errorf("github app init: %w", err)
+		}
+	}
+	glClient, err := gitlab.NewGitlabClient(cfg.gitlabHosts...)
+	if err != nil {
+		return nil, fmt.Errorf("gitlab client init: %w", err)
+	}
+	giteaClient, err := gitea.NewGiteaClient(cfg.giteaHosts...)
+	if err != nil {
+		return nil, fmt.Errorf("gitea client init: %w", err)
+	}
+	return forge.NewRegistry().
+		Register(ghClient, ghClient.Hosts()...).
+		Register(glClient, glClient.Hosts()...).
+		Register(giteaClient, giteaClient.Hosts()...), nil
+}
+
+// newResponseCache returns the store of conditional GitHub requests selected by HTTP_CACHE:
+// postgres (default), memory or off. Stored responses may hold private repository data, so the
+// postgres store seals them with the token keyring and is replaced by memory without one.
+func newResponseCache(cfg appConfig, db *gormio.DB) httpcache.Store {
+	switch cfg.httpCache {
+	case "off", "none", "":
+		return nil
+	case "memory":
+		return httpcache.NewMemoryStore(cfg.httpCacheEntries)
+	default:
+		if cfg.tokenKeyring == nil {
+			zap.L().Warn("token encryption is not configured, http cache is kept in memory")
+			return httpcache.NewMemoryStore(cfg.httpCacheEntries)
+		}
+		zap.L().Info("http cache stored in postgres", zap.Duration("ttl", cfg.httpCacheTTL))
+		return repgorm.NewGormHTTPCache(db, cfg.tokenKeyring, cfg.httpCacheTTL)
+	}
+}
+
+// purgeResponseCache drops entries older than the cache TTL on start and once a day. Expired
+// entries are not served in between.
+func purgeResponseCache(ctx context.Context, cache *repgorm.GormHTTPCache) {
+	ticker := time.NewTicker(24 * time.Hour)
+	defer ticker.Stop()
+	for {
+		removed, err := cache.PurgeBefore(ctx, time.Now().UTC().Add(-cache.TTL()))
+		if err != nil {
+			zap.L().Warn("purging http cache failed", zap.Error(err))
+		} else {
+			zap.L().Info("http cache purged",
+				zap.Int("removed", removed),
+				zap.Duration("ttl", cache.TTL()))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
 
 func loadConfig() (appConfig, error) {
@@ -144,33 +307,167 @@ func loadConfig() (appConfig, error) {
 		return appConfig{}, fmt.Errorf("DB_DSN is required")
 	}
 
-	brokersRaw := os.Getenv("KAFKA_BROKERS")
-	if brokersRaw == "" {
-		return appConfig{}, fmt.Errorf("KAFKA_BROKERS is required")
-	}
-	brokers := splitAndTrim(brokersRaw)
-	if len(brokers) == 0 {
-		return appConfig{}, fmt.Errorf("KAFKA_BROKERS is empty")
+	// Kafka is optional when changes are consumed through the WatchChanges stream.
+	watchAddr := strings.TrimSpace(os.Getenv("WATCH_GRPC_ADDR"))
+	brokers := splitAndTrim(os.Getenv("KAFKA_BROKERS"))
+	if len(brokers) == 0 && watchAddr == "" {
+		return appConfig{}, fmt.Errorf("KAFKA_BROKERS or WATCH_GRPC_ADDR is required")
 	}
 
 	topic := os.Getenv("KAFKA_TOPIC")
-	if topic == "" {
+	if len(brokers) > 0 && topic == "" {
 		return appConfig{}, fmt.Errorf("KAFKA_TOPIC is required")
 	}
 
+	// GitHub Enterprise instances as host[=api_base_url] entries; github.com is always tracked.
+	githubHosts, err := forge.ParseHostConfigs(os.Getenv("GITHUB_HOSTS"))
+	if err != nil {
+		return appConfig{}, fmt.Errorf("GITHUB_HOSTS: %w", err)
+	}
+	// GitLab and Gitea instances use the same host[=api_base_url] format.
+	gitlabHosts, err := forge.ParseHostConfigs(os.Getenv("GITLAB_HOSTS"))
+	if err != nil {
+		return appConfig{}, fmt.Errorf("GITLAB_HOSTS: %w", err)
+	}
+	giteaHosts, err := forge.ParseHostConfigs(os.Getenv("GITEA_HOSTS"))
+	if err != nil {
+		return appConfig{}, fmt.Errorf("GITEA_HOSTS: %w", err)
+	}
+
+	tokenKeyring, err := loadTokenKeyring()
+	if err != nil {
+		return appConfig{}, err
+	}
+
+	githubApp, err := loadGithubApp()
+	if err != nil {
+		return appConfig{}, err
+	}
+
+	trackInterval := time.Duration(getEnvInt("TRACK_INTERVAL_SEC", 60)) * time.Second
+
+	// Cached responses are kept this long after their last refresh.
+	httpCacheTTL := time.Duration(getEnvInt("HTTP_CACHE_TTL_HOURS", 168)) * time.Hour
+	if httpCacheTTL <= 0 {
+		return appConfig{}, fmt.Errorf("HTTP_CACHE_TTL_HOURS must be positive")
+	}
+
+	watchTLS := grpc_kit.TLSConfig{
+		CertFile:       strings.TrimSpace(os.Getenv("GRPC_TLS_CERT_FILE")),
+		KeyFile:        strings.TrimSpace(os.Getenv("GRPC_TLS_KEY_FILE")),
+		ClientCAFile:   strings.TrimSpace(os.Getenv("GRPC_TLS_CLIENT_CA_FILE")),
+		AllowedClients: splitAndTrim(os.Getenv("GRPC_TLS_ALLOWED_CLIENTS")),
+		ReloadInterval: time.Duration(getEnvInt("GRPC_TLS_RELOAD_INTERVAL_SEC", 30)) * time.Second,
+	}
+	if err := watchTLS.Validate(); err != nil {
+		return appConfig{}, fmt.Errorf("GRPC_TLS: %w", err)
+	}
+
 	return appConfig{
-		dbDSN:             dbDSN,
-		kafkaBrokers:      brokers,
-		kafkaTopic:        topic,
-		kafkaMaxAttempts:  getEnvInt("KAFKA_MAX_ATTEMPTS", 3),
-		kafkaBatchSize:    getEnvInt("KAFKA_BATCH_SIZE", 100),
-		kafkaBatchTimeout: time.Duration(getEnvInt("KAFKA_BATCH_TIMEOUT_MS", 1000)) * time.Millisecond,
-		kafkaWriteTimeout: time.Duration(getEnvInt("KAFKA_WRITE_TIMEOUT_MS", 10000)) * time.Millisecond,
-		trackBatchSize:    getEnvInt("TRACK_BATCH_SIZE", 100),
-		trackInterval:     time.Duration(getEnvInt("TRACK_INTERVAL_SEC", 60)) * time.Second,
+		dbDSN:                 dbDSN,
+		kafkaBrokers:          brokers,
+		kafkaTopic:            topic,
+		kafkaMaxAttempts:      getEnvInt("KAFKA_MAX_ATTEMPTS", 3),
+		kafkaBatchSize:        getEnvInt("KAFKA_BATCH_SIZE", 100),
+		kafkaBatchTimeout:     time.Duration(getEnvInt("KAFKA_BATCH_TIMEOUT_MS", 1000)) * time.Millisecond,
+		kafkaWriteTimeout:     time.Duration(getEnvInt("KAFKA_WRITE_TIMEOUT_MS", 10000)) * time.Millisecond,
+		trackBatchSize:        getEnvInt("TRACK_BATCH_SIZE", 100),
+		// Pushes with more new commits than this are reported as a single summary.
+		trackMaxCommits:       getEnvInt("TRACK_MAX_COMMITS", 50),
+		trackInterval:         trackInterval,
+		watchEnabled:          watchAddr != "",
+		watchBacklogSize:      getEnvInt("WATCH_BACKLOG_SIZE", 1024),
+		watchSubscriberBuffer: getEnvInt("WATCH_SUBSCRIBER_BUFFER", 256),
+		metricsAddr:           getEnvString("METRICS_ADDR", ":2112"),
+		healthInterval:        time.Duration(getEnvInt("HEALTH_CHECK_INTERVAL_SEC", 10)) * time.Second,
+		healthTimeout:         time.Duration(getEnvInt("HEALTH_CHECK_TIMEOUT_SEC", 3)) * time.Second,
+		// A cycle may legitimately span several intervals; only a stuck scheduler should fail liveness.
+		schedulerStaleAfter:   time.Duration(getEnvInt("SCHEDULER_STALE_AFTER_SEC", int(5*trackInterval/time.Second))) * time.Second,
+		githubHosts:           githubHosts,
+		gitlabHosts:           gitlabHosts,
+		giteaHosts:            giteaHosts,
+		tokenKeyring:          tokenKeyring,
+		githubApp:             githubApp,
+		httpCache:             strings.ToLower(getEnvString("HTTP_CACHE", "postgres")),
+		httpCacheTTL:          httpCacheTTL,
+		httpCacheEntries:      getEnvInt("HTTP_CACHE_MEMORY_ENTRIES", 10000),
+		watchGrpc: grpc_server.GrpcServerConfig{
+			Addr:                watchAddr,
+			Transport:           "tcp",
+			EnableHealthService: true,
+			GracefulStopTimeout: 10 * time.Second,
+			TLS:                 watchTLS,
+			Auth: grpc_kit.AuthConfig{
+				KeysFile:       strings.TrimSpace(os.Getenv("GRPC_AUTH_KEYS_FILE")),
+				ReloadInterval: time.Duration(getEnvInt("GRPC_AUTH_RELOAD_INTERVAL_SEC", 30)) * time.Second,
+			},
+			Interceptors: grpc_kit.InterceptorConfig{
+				DefaultTimeout: time.Duration(getEnvInt("GRPC_DEFAULT_TIMEOUT_SEC", 30)) * time.Second,
+				MethodTimeouts: grpc_server.RepTrackerMethodTimeouts,
+			},
+		},
 	}, nil
 }
 
+// loadGithubApp reads the optional GitHub App used to poll repositories it is installed on.
+func loadGithubApp() (*github.AppConfig, error) {
+	rawID := strings.TrimSpace(os.Getenv("GITHUB_APP_ID"))
+	if rawID == "" {
+		return nil, nil
+	}
+	appID, err := strconv.ParseInt(rawID, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("GITHUB_APP_ID: %w", err)
+	}
+	keyFile := strings.TrimSpace(os.Getenv("GITHUB_APP_PRIVATE_KEY_FILE"))
+	if keyFile == "" {
+		return nil, fmt.Errorf("GITHUB_APP_PRIVATE_KEY_FILE is required with GITHUB_APP_ID")
+	}
+	privateKey, err := os.ReadFile(keyFile)
+	if err != nil {
+		return nil, fmt.Errorf("GITHUB_APP_PRIVATE_KEY_FILE: %w", err)
+	}
+	return &github.AppConfig{
+		Host:       strings.TrimSpace(os.Getenv("GITHUB_APP_HOST")),
+		AppID:      appID,
+		PrivateKey: privateKey,
+	}, nil
+}
+
+// loadTokenKeyring reads the master keys sealing stored tokens, from TOKEN_KEYS_FILE or TOKEN_MASTER_KEY.
+func loadTokenKeyring() (*tokencrypt.Keyring, error) {
+	keyring, err := tokencrypt.LoadKeyring(
+		strings.TrimSpace(os.Getenv("TOKEN_KEYS_FILE")),
+		strings.TrimSpace(os.Getenv("TOKEN_ACTIVE_KEY_ID")),
+		os.Getenv("TOKEN_MASTER_KEY"),
+		getEnvString("TOKEN_MASTER_KEY_ID", "env"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("token keyring: %w", err)
+	}
+	return keyring, nil
+}
+
+func getEnvString(key string, def string) string {
+	raw, ok := os.LookupEnv(key)
+	if !ok {
+		return def
+	}
+	return strings.TrimSpace(raw)
+}
+
+func getEnvBool(key string, def bool) bool {
+	raw := strings.TrimSpace(os.Getenv(key))
+	if raw == "" {
+		return def
+	}
+	val, err := strconv.ParseBool(raw)
+	if err != nil {
+		return def
+	}
+	return val
+}
+
 func getEnvInt(key string, def int) int {
 	raw := strings.TrimSpace(os.Getenv(key))
 	if raw == "" {
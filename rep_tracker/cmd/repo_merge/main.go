@@ -0,0 +1,79 @@
+// Command repo_merge folds repos rows that point to the same repository under different spellings
+// of its link into one row with the canonical link. It uses the same DB_DSN and GITHUB_HOSTS,
+// GITLAB_HOSTS and GITEA_HOSTS settings as the services, is idempotent and is safe to run while
+// they are up.
+package main
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	gormio "gorm.io/gorm"
+
+	"rep_tracker/pkg/forge"
+	"rep_tracker/pkg/gitea"
+	"rep_tracker/pkg/github"
+	"rep_tracker/pkg/gitlab"
+	repgorm "rep_tracker/pkg/gorm"
+)
+
+func main() {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		panic(err)
+	}
+	defer logger.Sync()
+	zap.ReplaceGlobals(logger)
+
+	dbDSN := os.Getenv("DB_DSN")
+	if dbDSN == "" {
+		zap.L().Fatal("DB_DSN is required")
+	}
+	// The forge clients register their hosts, so links on them parse like in the services.
+	if err := registerHosts(); err != nil {
+		zap.L().Fatal("forge hosts config invalid", zap.Error(err))
+	}
+
+	db, err := gormio.Open(postgres.Open(dbDSN), &gormio.Config{})
+	if err != nil {
+		zap.L().Fatal("db connection failed", zap.Error(err))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	merged, err := repgorm.NewGormSchedulerRepo(db).MergeDuplicateRepos(ctx)
+	if err != nil {
+		zap.L().Fatal("merging duplicate repositories failed",
+			zap.Int("merged", merged),
+			zap.Error(err))
+	}
+	zap.L().Info("duplicate repositories merged", zap.Int("merged", merged))
+}
+
+func registerHosts() error {
+	githubHosts, err := forge.ParseHostConfigs(os.Getenv("GITHUB_HOSTS"))
+	if err != nil {
+		return err
+	}
+	gitlabHosts, err := forge.ParseHostConfigs(os.Getenv("GITLAB_HOSTS"))
+	if err != nil {
+		return err
+	}
+	giteaHosts, err := forge.ParseHostConfigs(os.Getenv("GITEA_HOSTS"))
+	if err != nil {
+		return err
+	}
+	if _, err := github.NewGithubClient(githubHosts...); err != nil {
+		return err
+	}
+	if _, err := gitlab.NewGitlabClient(gitlabHosts...); err != nil {
+		return err
+	}
+	_, err = gitea.NewGiteaClient(giteaHosts...)
+	return err
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
 	"strconv"
@@ -18,9 +19,16 @@ import (
 
 	"rep_tracker/internal/grpc_server"
 	"rep_tracker/internal/rep_service"
+	"rep_tracker/pkg/forge"
+	"rep_tracker/pkg/gitea"
 	"rep_tracker/pkg/github"
+	"rep_tracker/pkg/gitlab"
 	repgorm "rep_tracker/pkg/gorm"
+	"rep_tracker/pkg/metrics"
 	"rep_tracker/pkg/proto"
+	"service_common/grpc_kit"
+	"service_common/health"
+	"service_common/tokencrypt"
 )
 
 func main() {
@@ -43,20 +51,40 @@ func main() {
 	if err != nil {
 		zap.L().Fatal("invalid config", zap.Error(err))
 	}
+	if cfg.tokenKeyring == nil {
+		zap.L().Warn("token encryption is not configured, tokens are stored in plaintext")
+	}
 
 	db, err := gormio.Open(postgres.Open(cfg.dbDSN), &gormio.Config{})
 	if err != nil {
 		zap.L().Fatal("db connection failed", zap.Error(err))
 	}
 
-	tokenRepo := repgorm.NewGormTokenRepo(db)
+	tokenRepo := repgorm.NewGormTokenRepo(db, cfg.tokenKeyring)
 	serverRepo := repgorm.NewGormServerRepo(db)
-	ghClient := github.NewGithubClient()
-	repService := rep_service.NewRepService(ghClient, tokenRepo, serverRepo)
+	forgeClient, err := newForge(cfg)
+	if err != nil {
+		zap.L().Fatal("forge client init failed", zap.Error(err))
+	}
+	repService := rep_service.NewRepService(forgeClient, tokenRepo, serverRepo)
 
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	checker := health.NewChecker(cfg.healthInterval, cfg.healthTimeout).
+		Add("postgres", health.DBCheck(db))
+	go checker.Run(ctx)
+	cfg.grpc.HealthChecker = checker
+
+	if cfg.metricsAddr != "" {
+		go func() {
+			routes := map[string]http.Handler{"/readyz": health.ProbeHandler(checker.ReadinessProbe)}
+			if err := metrics.Serve(ctx, cfg.metricsAddr, routes); err != nil {
+				zap.L().Error("metrics server stopped with error", zap.Error(err))
+			}
+		}()
+	}
+
 	err = grpc_server.ConfigureGrpcServerAndServer(ctx, &cfg.grpc, func(s grpc.ServiceRegistrar) {
 		proto.RegisterRepTrackerServiceServer(s, grpc_server.NewRepTrackerServiceServer(repService))
 	})
@@ -66,8 +94,35 @@ func main() {
 }
 
 type appConfig struct {
-	dbDSN string
-	grpc  grpc_server.GrpcServerConfig
+	dbDSN          string
+	grpc           grpc_server.GrpcServerConfig
+	metricsAddr    string
+	healthInterval time.Duration
+	healthTimeout  time.Duration
+	githubHosts    []forge.HostConfig
+	gitlabHosts    []forge.HostConfig
+	giteaHosts     []forge.HostConfig
+	tokenKeyring   *tokencrypt.Keyring
+}
+
+// newForge builds the forge registry routing links to GitHub, GitLab and Gitea by host.
+func newForge(cfg appConfig) (*forge.Registry, error) {
+	ghClient, err := github.NewGithubClient(cfg.githubHosts...)
+	if err != nil {
+		return nil, fmt.Errorf("github client init: %w", err)
+	}
+	glClient, err := gitlab.NewGitlabClient(cfg.gitlabHosts...)
+	if err != nil {
+		return nil, fmt.Errorf("gitlab client init: %w", err)
+	}
+	giteaClient, err := gitea.NewGiteaClient(cfg.giteaHosts...)
+	if err != nil {
+		return nil, fmt.Errorf("gitea client init: %w", err)
+	}
+	return forge.NewRegistry().
+		Register(ghClient, ghClient.Hosts()...).
+		Register(glClient, glClient.Hosts()...).
+		Register(giteaClient, giteaClient.Hosts()...), nil
 }
 
 func loadConfig() (appConfig, error) {
@@ -92,9 +147,63 @@ func loadConfig() (appConfig, error) {
 		KeepAliveMinTime:        getEnvDuration("GRPC_KEEPALIVE_MIN_TIME_SEC", 0),
 		KeepAliveWithoutStream:  getEnvBool("GRPC_KEEPALIVE_PERMIT_WITHOUT_STREAM", false),
 		GracefulStopTimeout:     getEnvDuration("GRPC_GRACEFUL_STOP_TIMEOUT_SEC", 10),
+		TLS: grpc_kit.TLSConfig{
+			CertFile:       getEnvString("GRPC_TLS_CERT_FILE", ""),
+			KeyFile:        getEnvString("GRPC_TLS_KEY_FILE", ""),
+			ClientCAFile:   getEnvString("GRPC_TLS_CLIENT_CA_FILE", ""),
+			AllowedClients: splitAndTrim(os.Getenv("GRPC_TLS_ALLOWED_CLIENTS")),
+			ReloadInterval: getEnvDuration("GRPC_TLS_RELOAD_INTERVAL_SEC", 30),
+		},
+		Auth: grpc_kit.AuthConfig{
+			KeysFile:       getEnvString("GRPC_AUTH_KEYS_FILE", ""),
+			ReloadInterval: getEnvDuration("GRPC_AUTH_RELOAD_INTERVAL_SEC", 30),
+		},
+		Interceptors: grpc_kit.InterceptorConfig{
+			DefaultTimeout: getEnvDuration("GRPC_DEFAULT_TIMEOUT_SEC", 30),
+			MethodTimeouts: getEnvMethodTimeouts("GRPC_METHOD_TIMEOUTS", "/rep_tracker.RepTrackerService/", grpc_server.RepTrackerMethodTimeouts),
+		},
+	}
+
+	if err := grpcCfg.TLS.Validate(); err != nil {
+		return appConfig{}, fmt.Errorf("GRPC_TLS: %w", err)
 	}
 
-	return appConfig{dbDSN: dbDSN, grpc: grpcCfg}, nil
+	githubHosts, err := forge.ParseHostConfigs(os.Getenv("GITHUB_HOSTS"))
+	if err != nil {
+		return appConfig{}, fmt.Errorf("GITHUB_HOSTS: %w", err)
+	}
+	// GitLab and Gitea instances use the same host[=api_base_url] format.
+	gitlabHosts, err := forge.ParseHostConfigs(os.Getenv("GITLAB_HOSTS"))
+	if err != nil {
+		return appConfig{}, fmt.Errorf("GITLAB_HOSTS: %w", err)
+	}
+	giteaHosts, err := forge.ParseHostConfigs(os.Getenv("GITEA_HOSTS"))
+	if err != nil {
+		return appConfig{}, fmt.Errorf("GITEA_HOSTS: %w", err)
+	}
+
+	tokenKeyring, err := loadTokenKeyring()
+	if err != nil {
+		return appConfig{}, err
+	}
+
+	// An explicitly empty METRICS_ADDR disables the metrics endpoint.
+	metricsAddr, ok := os.LookupEnv("METRICS_ADDR")
+	if !ok {
+		metricsAddr = ":2112"
+	}
+
+	return appConfig{
+		dbDSN:          dbDSN,
+		grpc:           grpcCfg,
+		metricsAddr:    strings.TrimSpace(metricsAddr),
+		healthInterval: getEnvDuration("HEALTH_CHECK_INTERVAL_SEC", 10),
+		healthTimeout:  getEnvDuration("HEALTH_CHECK_TIMEOUT_SEC", 3),
+		githubHosts:    githubHosts,
+		gitlabHosts:    gitlabHosts,
+		giteaHosts:     giteaHosts,
+		tokenKeyring:   tokenKeyring,
+	}, nil
 }
 
 func buildLogger() (*zap.Logger, error) {
@@ -132,6 +241,20 @@ func parseLogLevel(raw string) zapcore.Level {
 	}
 }
 
+// loadTokenKeyring reads the master keys sealing stored tokens, from TOKEN_KEYS_FILE or TOKEN_MASTER_KEY.
+func loadTokenKeyring() (*tokencrypt.Keyring, error) {
+	keyring, err := tokencrypt.LoadKeyring(
+		strings.TrimSpace(os.Getenv("TOKEN_KEYS_FILE")),
+		strings.TrimSpace(os.Getenv("TOKEN_ACTIVE_KEY_ID")),
+		os.Getenv("TOKEN_MASTER_KEY"),
+		getEnvString("TOKEN_MASTER_KEY_ID", "env"),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("token keyring: %w", err)
+	}
+	return keyring, nil
+}
+
 func getEnvString(key string, def string) string {
 	raw := strings.TrimSpace(os.Getenv(key))
 	if raw == "" {
@@ -174,3 +297,39 @@ func getEnvDuration(key string, defSec int) time.Duration {
 	}
 	return time.Duration(sec) * time.Second
 }
+
+func splitAndTrim(raw string) []string {
+	parts := strings.Split(raw, ",")
+	out := make([]string, 0, len(parts))
+	for _, p := range parts {
+		p = strings.TrimSpace(p)
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return out
+}
+
+// getEnvMethodTimeouts parses "Method=seconds" pairs separated by commas on top of the defaults.
+func getEnvMethodTimeouts(key string, servicePrefix string, defaults map[string]time.Duration) map[string]time.Duration {
+	timeouts := make(map[string]time.Duration, len(defaults))
+	for method, timeout := range defaults {
+		timeouts[method] = timeout
+	}
+	for _, pair := range splitAndTrim(os.Getenv(key)) {
+		method, rawSec, ok := strings.Cut(pair, "=")
+		if !ok {
+			continue
+		}
+		sec, err := strconv.Atoi(strings.TrimSpace(rawSec))
+		if err != nil {
+			continue
+		}
+		method = strings.TrimSpace(method)
+		if !strings.HasPrefix(method, "/") {
+			method = servicePrefix + method
+		}
+		timeouts[method] = time.Duration(sec) * time.Second
+	}
+	return timeouts
+}
@@ -0,0 +1,71 @@
+// Command token_reencrypt seals every stored token that is still in plaintext or sealed under
+// a retired master key with the active master key. It uses the same DB_DSN and TOKEN_* settings
+// as the services and is safe to run while they are up.
+package main
+
+import (
+	"context"
+	"flag"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	gormio "gorm.io/gorm"
+
+	repgorm "rep_tracker/pkg/gorm"
+	"service_common/tokencrypt"
+)
+
+func main() {
+	batchSize := flag.Int("batch-size", 100, "rows read per query")
+	flag.Parse()
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		panic(err)
+	}
+	defer logger.Sync()
+	zap.ReplaceGlobals(logger)
+
+	dbDSN := os.Getenv("DB_DSN")
+	if dbDSN == "" {
+		zap.L().Fatal("DB_DSN is required")
+	}
+	masterKeyID := os.Getenv("TOKEN_MASTER_KEY_ID")
+	if masterKeyID == "" {
+		masterKeyID = "env"
+	}
+	keyring, err := tokencrypt.LoadKeyring(
+		strings.TrimSpace(os.Getenv("TOKEN_KEYS_FILE")),
+		strings.TrimSpace(os.Getenv("TOKEN_ACTIVE_KEY_ID")),
+		os.Getenv("TOKEN_MASTER_KEY"),
+		masterKeyID,
+	)
+	if err != nil {
+		zap.L().Fatal("token keyring load failed", zap.Error(err))
+	}
+	if keyring == nil {
+		zap.L().Fatal("TOKEN_KEYS_FILE or TOKEN_MASTER_KEY is required")
+	}
+
+	db, err := gormio.Open(postgres.Open(dbDSN), &gormio.Config{})
+	if err != nil {
+		zap.L().Fatal("db connection failed", zap.Error(err))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	rewritten, err := repgorm.NewGormTokenRepo(db, keyring).ReencryptTokens(ctx, *batchSize)
+	if err != nil {
+		zap.L().Fatal("re-encrypting tokens failed",
+			zap.Int("rewritten", rewritten),
+			zap.Error(err))
+	}
+	zap.L().Info("tokens re-encrypted",
+		zap.String("activeKeyId", keyring.ActiveKeyID()),
+		zap.Int("rewritten", rewritten))
+}
@@ -0,0 +1,54 @@
+package grpc_server
+
+import (
+	"rep_tracker/pkg/errs"
+	"rep_tracker/pkg/proto"
+	"rep_tracker/pkg/stream"
+	"service_common/logctx"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+)
+
+// ChangeStreamServer serves only WatchChanges, so the process producing notifications
+// exposes no way to change subscriptions; every other method is Unimplemented.
+type ChangeStreamServer struct {
+	changes *stream.BroadcastNotificationWriter
+	proto.UnimplementedRepTrackerServiceServer
+}
+
+func NewChangeStreamServer(changes *stream.BroadcastNotificationWriter) *ChangeStreamServer {
+	return &ChangeStreamServer{changes: changes}
+}
+
+func (server *ChangeStreamServer) WatchChanges(req *proto.WatchChangesRequest, srv grpc.ServerStreamingServer[proto.ChangeEvent]) error {
+	// Streams are per chat; an empty chat_id would receive every chat's commits.
+	if req.GetChatId() == "" {
+		return convertErrToGrpcError(errs.ErrNotValidData)
+	}
+	sub, err := server.changes.Subscribe(req.GetChatId(), req.GetLastEventId())
+	if err != nil {
+		return convertErrToGrpcError(err)
+	}
+	defer sub.Close()
+	logctx.From(srv.Context()).Info("WatchChanges subscriber connected",
+		zap.String("chatId", req.GetChatId()),
+		zap.Uint64("lastEventId", req.GetLastEventId()))
+
+	for {
+		select {
+		case <-srv.Context().Done():
+			return nil
+		case event, ok := <-sub.Events():
+			if !ok {
+				if sub.Err() != nil {
+					return convertErrToGrpcError(sub.Err())
+				}
+				return convertErrToGrpcError(stream.ErrWriterClosed)
+			}
+			if err := srv.Send(convertEventToProto(event)); err != nil {
+				return err
+			}
+		}
+	}
+}
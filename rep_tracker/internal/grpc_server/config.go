@@ -3,9 +3,17 @@ package grpc_server
 import (
 	"context"
 	"net"
+	"strings"
 	"time"
 
+	"rep_tracker/pkg/errs"
+	"rep_tracker/pkg/metrics"
+	"service_common/grpc_kit"
+	dephealth "service_common/health"
+
+	"go.uber.org/zap"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/health"
 	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/keepalive"
@@ -28,6 +36,11 @@ type GrpcServerConfig struct {
 	KeepAliveMinTime        time.Duration
 	KeepAliveWithoutStream  bool
 	GracefulStopTimeout     time.Duration
+	TLS                     grpc_kit.TLSConfig
+	Auth                    grpc_kit.AuthConfig
+	Interceptors            grpc_kit.InterceptorConfig
+	// HealthChecker drives the per-service health status; without it services always report SERVING.
+	HealthChecker *dephealth.Checker
 }
 
 func ConfigureGrpcServerAndServer(ctx context.Context, cfg *GrpcServerConfig, registerFunc func(s grpc.ServiceRegistrar)) error {
@@ -37,11 +50,39 @@ func ConfigureGrpcServerAndServer(ctx context.Context, cfg *GrpcServerConfig, re
 	}
 	defer listener.Close()
 
-	grpcServer := grpc.NewServer(cfg.buildServerOptions()...)
+	opts := cfg.buildServerOptions()
+	if cfg.TLS.Enabled() {
+		creds, err := grpc_kit.NewServerCredentials(ctx, cfg.TLS)
+		if err != nil {
+			return err
+		}
+		opts = append(opts, grpc.Creds(creds))
+	}
+	var unaryExtra []grpc.UnaryServerInterceptor
+	var streamExtra []grpc.StreamServerInterceptor
+	if cfg.Auth.Enabled() {
+		auth, err := grpc_kit.NewAuthenticator(ctx, cfg.Auth)
+		if err != nil {
+			return err
+		}
+		unaryExtra = append(unaryExtra, auth.UnaryInterceptor())
+		streamExtra = append(streamExtra, auth.StreamInterceptor())
+	} else {
+		zap.L().Warn("grpc server started without caller authentication", zap.String("addr", cfg.Addr))
+	}
+	interceptors := cfg.Interceptors
+	interceptors.Requests = metrics.GrpcRequests
+	interceptors.RequestDuration = metrics.GrpcRequestDuration
+	interceptors.PanicError = newStatusError(codes.Internal, INTERNAL_REASON, errs.ErrInternal.Error(), map[string]string{"error": "panic"}, 0)
+	opts = append(opts,
+		grpc.ChainUnaryInterceptor(interceptors.UnaryInterceptors(unaryExtra...)...),
+		grpc.ChainStreamInterceptor(interceptors.StreamInterceptors(streamExtra...)...))
+	grpcServer := grpc.NewServer(opts...)
 	defer grpcServer.Stop()
 
+	var healthServer *health.Server
 	if cfg.EnableHealthService {
-		healthServer := health.NewServer()
+		healthServer = health.NewServer()
 		healthpb.RegisterHealthServer(grpcServer, healthServer)
 	}
 	if cfg.EnableReflection {
@@ -50,6 +91,9 @@ func ConfigureGrpcServerAndServer(ctx context.Context, cfg *GrpcServerConfig, re
 	if registerFunc != nil {
 		registerFunc(grpcServer)
 	}
+	if healthServer != nil {
+		watchHealth(grpcServer, healthServer, cfg.HealthChecker)
+	}
 
 	errCh := make(chan error, 1)
 	go func() {
@@ -58,6 +102,10 @@ func ConfigureGrpcServerAndServer(ctx context.Context, cfg *GrpcServerConfig, re
 
 	select {
 	case <-ctx.Done():
+		if healthServer != nil {
+			// Load balancers stop routing new calls while in-flight ones finish.
+			healthServer.Shutdown()
+		}
 		if cfg.GracefulStopTimeout > 0 {
 			done := make(chan struct{})
 			go func() {
@@ -78,6 +126,35 @@ func ConfigureGrpcServerAndServer(ctx context.Context, cfg *GrpcServerConfig, re
 	}
 }
 
+// watchHealth reports every registered application service (and the overall "" service)
+// as SERVING only while the dependency checker is healthy.
+func watchHealth(grpcServer *grpc.Server, healthServer *health.Server, checker *dephealth.Checker) {
+	services := []string{""}
+	for name := range grpcServer.GetServiceInfo() {
+		if name == healthpb.Health_ServiceDesc.ServiceName || strings.HasPrefix(name, "grpc.reflection.") {
+			continue
+		}
+		services = append(services, name)
+	}
+	setStatus := func(servingStatus healthpb.HealthCheckResponse_ServingStatus) {
+		for _, service := range services {
+			healthServer.SetServingStatus(service, servingStatus)
+		}
+	}
+	if checker == nil {
+		setStatus(healthpb.HealthCheckResponse_SERVING)
+		return
+	}
+	setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+	checker.OnChange(func(healthy bool) {
+		if healthy {
+			setStatus(healthpb.HealthCheckResponse_SERVING)
+		} else {
+			setStatus(healthpb.HealthCheckResponse_NOT_SERVING)
+		}
+	})
+}
+
 func (cfg GrpcServerConfig) buildServerOptions() []grpc.ServerOption {
 	opts := make([]grpc.ServerOption, 0, 8)
 	if cfg.ConcurrentStreamsNumber > 0 {
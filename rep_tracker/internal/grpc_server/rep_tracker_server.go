@@ -2,23 +2,80 @@ package grpc_server
 
 import (
 	"context"
+	"errors"
+	"strings"
+	"time"
+
 	"rep_tracker/internal/rep_service"
 	"rep_tracker/internal/server_model"
 	"rep_tracker/pkg/errs"
+	"rep_tracker/pkg/filters"
+	"rep_tracker/pkg/forge"
 	"rep_tracker/pkg/proto"
+	"rep_tracker/pkg/repolink"
+	"rep_tracker/pkg/stream"
 	"go.uber.org/zap"
 
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+	"google.golang.org/protobuf/types/known/durationpb"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
 const (
-	USER_NOT_FOUND_REASON = "USER_NOT_FOUND_REASON"
-	REPO_NOT_FOUND_REASON = "REPO_NOT_FOUND_REASON"
+	ERROR_DOMAIN = "rep_tracker"
+
+	USER_NOT_FOUND_REASON     = "USER_NOT_FOUND_REASON"
+	REPO_NOT_FOUND_REASON     = "REPO_NOT_FOUND_REASON"
+	NOT_VALID_DATA_REASON     = "NOT_VALID_DATA_REASON"
+	INVALID_LINK_REASON       = "INVALID_LINK_REASON"
+	UNSUPPORTED_HOST_REASON   = "UNSUPPORTED_HOST_REASON"
+	INVALID_TOKEN_REASON      = "INVALID_TOKEN_REASON"
+	TOKEN_MISSING_REASON      = "TOKEN_MISSING_REASON"
+	RATE_LIMITED_REASON       = "RATE_LIMITED_REASON"
+	GITHUB_UNAVAILABLE_REASON = "GITHUB_UNAVAILABLE_REASON"
+	EVENTS_EXPIRED_REASON     = "EVENTS_EXPIRED_REASON"
+	SLOW_SUBSCRIBER_REASON    = "SLOW_SUBSCRIBER_REASON"
+	STREAM_CLOSED_REASON      = "STREAM_CLOSED_REASON"
+	STREAM_DISABLED_REASON    = "STREAM_DISABLED_REASON"
+	CANCELED_REASON           = "CANCELED_REASON"
+	DEADLINE_EXCEEDED_REASON  = "DEADLINE_EXCEEDED_REASON"
+	INTERNAL_REASON           = "INTERNAL_REASON"
 )
 
+// RepTrackerMethodTimeouts are deadline defaults for calls slower than DefaultTimeout allows.
+var RepTrackerMethodTimeouts = map[string]time.Duration{
+	proto.RepTrackerService_ImportRepos_FullMethodName: 2 * time.Minute,
+}
+
+type errorMapping struct {
+	target error
+	code   codes.Code
+	reason string
+}
+
+// errorMappings is checked in order with errors.Is, so wrapped errors keep their code.
+var errorMappings = []errorMapping{
+	{errs.ErrUserNotFound, codes.NotFound, USER_NOT_FOUND_REASON},
+	{errs.ErrRepoNotFound, codes.NotFound, REPO_NOT_FOUND_REASON},
+	{errs.ErrNotValidData, codes.InvalidArgument, NOT_VALID_DATA_REASON},
+	{errs.ErrInvalidLink, codes.InvalidArgument, INVALID_LINK_REASON},
+	{errs.ErrUnsupportedHost, codes.InvalidArgument, UNSUPPORTED_HOST_REASON},
+	{errs.ErrInvalidToken, codes.PermissionDenied, INVALID_TOKEN_REASON},
+	{errs.ErrTokenMissing, codes.FailedPrecondition, TOKEN_MISSING_REASON},
+	{errs.ErrRateLimited, codes.ResourceExhausted, RATE_LIMITED_REASON},
+	{errs.ErrGithubUnavailable, codes.Unavailable, GITHUB_UNAVAILABLE_REASON},
+	{stream.ErrEventsExpired, codes.OutOfRange, EVENTS_EXPIRED_REASON},
+	{stream.ErrSlowSubscriber, codes.ResourceExhausted, SLOW_SUBSCRIBER_REASON},
+	{stream.ErrWriterClosed, codes.Unavailable, STREAM_CLOSED_REASON},
+	{context.Canceled, codes.Canceled, CANCELED_REASON},
+	{context.DeadlineExceeded, codes.DeadlineExceeded, DEADLINE_EXCEEDED_REASON},
+}
+
 type RepTrackerServiceServer struct {
 	repService *rep_service.RepService
 	proto.UnimplementedRepTrackerServiceServer
@@ -29,76 +86,321 @@ func NewRepTrackerServiceServer(repService *rep_service.RepService) *RepTrackerS
 }
 
 func (server *RepTrackerServiceServer) AddTrackingRepo(ctx context.Context, trackingRepo *proto.TrackingRepo) (*emptypb.Empty, error) {
-	zap.L().Info("Received AddTrackingRepo gRPC request", 
-		zap.String("link", trackingRepo.GetLink()), 
-		zap.String("chatId", trackingRepo.GetChatId()))
-	
-	result, err := server.doWithServerModelTrackingRepo(ctx, trackingRepo, server.repService.AddTrackingRepo)
-	
-	if err != nil {
-		zap.L().Error("AddTrackingRepo failed", 
-			zap.String("link", trackingRepo.GetLink()), 
-			zap.String("chatId", trackingRepo.GetChatId()),
-			zap.Error(err))
-	} else {
-		zap.L().Info("AddTrackingRepo completed successfully", 
-			zap.String("link", trackingRepo.GetLink()), 
-			zap.String("chatId", trackingRepo.GetChatId()))
-	}
-	
-	return result, err
+	return server.doWithServerModelTrackingRepo(ctx, trackingRepo, server.repService.AddTrackingRepo)
 }
 
 func (server *RepTrackerServiceServer) RemoveTrackingRepo(ctx context.Context, trackingRepo *proto.TrackingRepo) (*emptypb.Empty, error) {
 	return server.doWithServerModelTrackingRepo(ctx, trackingRepo, server.repService.RemoveTrackingRepo)
 }
 
+func (server *RepTrackerServiceServer) PauseTrackingRepo(ctx context.Context, req *proto.PauseTrackingRepoRequest) (*emptypb.Empty, error) {
+	modelTrackingRepo, err := parseProtoTrackingRepo(&proto.TrackingRepo{Link: req.GetLink(), ChatId: req.GetChatId()})
+	if err != nil {
+		return nil, convertErrToGrpcError(err)
+	}
+	pause := &server_model.PauseTrackingRepo{TrackingRepo: *modelTrackingRepo}
+	if req.GetPausedUntil() != nil {
+		pausedUntil := req.GetPausedUntil().AsTime()
+		pause.PausedUntil = &pausedUntil
+	}
+	return &emptypb.Empty{}, convertErrToGrpcError(server.repService.PauseTrackingRepo(ctx, pause))
+}
+
+func (server *RepTrackerServiceServer) ResumeTrackingRepo(ctx context.Context, trackingRepo *proto.TrackingRepo) (*emptypb.Empty, error) {
+	return server.doWithServerModelTrackingRepo(ctx, trackingRepo, server.repService.ResumeTrackingRepo)
+}
+
+func (server *RepTrackerServiceServer) ResetCursor(ctx context.Context, req *proto.ResetCursorRequest) (*emptypb.Empty, error) {
+	modelTrackingRepo, err := parseProtoTrackingRepo(&proto.TrackingRepo{Link: req.GetLink(), ChatId: req.GetChatId()})
+	if err != nil {
+		return nil, convertErrToGrpcError(err)
+	}
+	if req.GetSince() == nil {
+		return nil, convertErrToGrpcError(errs.ErrNotValidData)
+	}
+	reset := &server_model.ResetCursor{TrackingRepo: *modelTrackingRepo, Since: req.GetSince().AsTime()}
+	return &emptypb.Empty{}, convertErrToGrpcError(server.repService.ResetCursor(ctx, reset))
+}
+
+func (server *RepTrackerServiceServer) UpdateTrackingFilters(ctx context.Context, req *proto.UpdateTrackingFiltersRequest) (*emptypb.Empty, error) {
+	modelTrackingRepo, err := parseProtoTrackingRepo(&proto.TrackingRepo{Link: req.GetLink(), ChatId: req.GetChatId()})
+	if err != nil {
+		return nil, convertErrToGrpcError(err)
+	}
+	update := &server_model.UpdateTrackingFilters{
+		TrackingRepo: *modelTrackingRepo,
+		Filters:      parseProtoFilters(req.GetFilters()),
+	}
+	return &emptypb.Empty{}, convertErrToGrpcError(server.repService.UpdateTrackingFilters(ctx, update))
+}
+
+func (server *RepTrackerServiceServer) ListTrackingRepos(ctx context.Context, req *proto.ListTrackingReposRequest) (*proto.ListTrackingReposResponse, error) {
+	chatId := req.GetChatId()
+	if chatId == "" {
+		return nil, convertErrToGrpcError(errs.ErrNotValidData)
+	}
+	page, err := server.repService.ListTrackingRepos(ctx, &server_model.ListTrackingReposQuery{
+		ChatID:    chatId,
+		PageToken: req.GetPageToken(),
+		PageSize:  int(req.GetPageSize()),
+	})
+	if err != nil {
+		return nil, convertErrToGrpcError(err)
+	}
+	repos := make([]*proto.TrackingRepoInfo, 0, len(page.Repos))
+	for _, repo := range page.Repos {
+		repos = append(repos, convertTrackingRepoInfoToProto(repo))
+	}
+	return &proto.ListTrackingReposResponse{
+		Repos:         repos,
+		NextPageToken: page.NextPageToken,
+	}, nil
+}
+
+func (server *RepTrackerServiceServer) GetRepoHistory(ctx context.Context, req *proto.GetRepoHistoryRequest) (*proto.GetRepoHistoryResponse, error) {
+	if req.GetChatId() == "" || req.GetLink() == "" {
+		return nil, convertErrToGrpcError(errs.ErrNotValidData)
+	}
+	query := &server_model.RepoHistoryQuery{
+		ChatID:    req.GetChatId(),
+		Link:      req.GetLink(),
+		Author:    strings.TrimSpace(req.GetAuthor()),
+		Branch:    strings.TrimSpace(req.GetBranch()),
+		PageToken: req.GetPageToken(),
+		PageSize:  int(req.GetPageSize()),
+	}
+	if req.GetSince() != nil {
+		since := req.GetSince().AsTime()
+		query.Since = &since
+	}
+	if req.GetUntil() != nil {
+		until := req.GetUntil().AsTime()
+		query.Until = &until
+	}
+	page, err := server.repService.GetRepoHistory(ctx, query)
+	if err != nil {
+		return nil, convertErrToGrpcError(err)
+	}
+	commits := make([]*proto.CommitInfo, 0, len(page.Commits))
+	for _, commit := range page.Commits {
+		commits = append(commits, convertCommitInfoToProto(commit))
+	}
+	return &proto.GetRepoHistoryResponse{
+		Commits:       commits,
+		NextPageToken: page.NextPageToken,
+	}, nil
+}
+
+func (server *RepTrackerServiceServer) ImportRepos(ctx context.Context, req *proto.ImportReposRequest) (*proto.ImportReposResponse, error) {
+	if req.GetChatId() == "" {
+		return nil, convertErrToGrpcError(errs.ErrNotValidData)
+	}
+	results, err := server.repService.ImportRepos(ctx, &server_model.ImportReposQuery{
+		ChatID:          req.GetChatId(),
+		Owner:           strings.TrimSpace(req.GetOwner()),
+		NamePattern:     strings.TrimSpace(req.GetNamePattern()),
+		Visibility:      convertProtoVisibility(req.GetVisibility()),
+		IncludeArchived: req.GetIncludeArchived(),
+		Host:            strings.ToLower(strings.TrimSpace(req.GetHost())),
+	})
+	if err != nil {
+		return nil, convertErrToGrpcError(err)
+	}
+	response := &proto.ImportReposResponse{Results: make([]*proto.ImportRepoResult, 0, len(results))}
+	for _, result := range results {
+		response.Results = append(response.Results, &proto.ImportRepoResult{
+			Link:   result.Link,
+			Status: convertImportStatusToProto(result.Status),
+			Reason: result.Reason,
+		})
+	}
+	return response, nil
+}
+
+func (server *RepTrackerServiceServer) RefreshToken(ctx context.Context, req *proto.RefreshTokenRequest) (*proto.RefreshTokenResponse, error) {
+	if req.GetChatId() == "" {
+		return nil, convertErrToGrpcError(errs.ErrNotValidData)
+	}
+	result, err := server.repService.RefreshToken(ctx, &server_model.RefreshToken{
+		ChatID: req.GetChatId(),
+		Token:  strings.TrimSpace(req.GetToken()),
+		Host:   strings.ToLower(strings.TrimSpace(req.GetHost())),
+	})
+	if err != nil {
+		return nil, convertErrToGrpcError(err)
+	}
+	return &proto.RefreshTokenResponse{
+		GithubLogin:    result.GithubLogin,
+		ValidatedAt:    timestamppb.New(result.ValidatedAt),
+		ReenabledCount: int32(result.ReenabledCount),
+	}, nil
+}
+
+// WatchChanges is served by ChangeStreamServer in the process that produces notifications.
+func (server *RepTrackerServiceServer) WatchChanges(req *proto.WatchChangesRequest, srv grpc.ServerStreamingServer[proto.ChangeEvent]) error {
+	return newStatusError(codes.Unimplemented, STREAM_DISABLED_REASON, "change stream is not enabled on this instance", map[string]string{"method": "WatchChanges"}, 0)
+}
+
 func (server *RepTrackerServiceServer) doWithServerModelTrackingRepo(ctx context.Context, trackingRepo *proto.TrackingRepo, operation func(context.Context, *server_model.TrackingRepo) error) (*emptypb.Empty, error) {
 	modelTrackingRepo, err := parseProtoTrackingRepo(trackingRepo)
 	if err != nil {
-		return nil, status.Error(codes.InvalidArgument, err.Error())
+		return nil, convertErrToGrpcError(err)
 	}
 	return &emptypb.Empty{}, convertErrToGrpcError(operation(ctx, modelTrackingRepo))
 }
 
 func parseProtoTrackingRepo(trackingRepo *proto.TrackingRepo) (*server_model.TrackingRepo, error) {
-	link := trackingRepo.GetLink()
-	if link == "" {
+	if trackingRepo.GetLink() == "" {
 		return nil, errs.ErrNotValidData
 	}
+	link, err := repolink.Canonical(trackingRepo.GetLink())
+	if err != nil {
+		return nil, err
+	}
 	chatId := trackingRepo.GetChatId()
 	if chatId == "" {
 		return nil, errs.ErrNotValidData
 	}
-	return &server_model.TrackingRepo{Link: link, ChatID: chatId}, nil
+	branches := make([]string, 0, len(trackingRepo.GetBranches()))
+	for _, branch := range trackingRepo.GetBranches() {
+		branch = strings.TrimSpace(branch)
+		if forge.ValidateBranchPattern(branch) != nil {
+			return nil, errs.ErrNotValidData
+		}
+		branches = append(branches, branch)
+	}
+	return &server_model.TrackingRepo{Link: link, ChatID: chatId, Branches: branches}, nil
+}
+
+func parseProtoFilters(protoFilters *proto.TrackingFilters) filters.Filters {
+	return filters.Filters{
+		IncludePaths:   trimNonEmpty(protoFilters.GetIncludePaths()),
+		ExcludePaths:   trimNonEmpty(protoFilters.GetExcludePaths()),
+		AllowAuthors:   trimNonEmpty(protoFilters.GetAllowAuthors()),
+		DenyAuthors:    trimNonEmpty(protoFilters.GetDenyAuthors()),
+		MessagePattern: strings.TrimSpace(protoFilters.GetMessagePattern()),
+	}
+}
+
+func trimNonEmpty(values []string) []string {
+	result := make([]string, 0, len(values))
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func convertTrackingRepoInfoToProto(info *server_model.TrackingRepoInfo) *proto.TrackingRepoInfo {
+	result := &proto.TrackingRepoInfo{
+		Link:      info.Link,
+		Owner:     info.Owner,
+		Name:      info.Name,
+		Enabled:   info.Enabled,
+		Branches:  info.Branches,
+		CreatedAt: timestamppb.New(info.CreatedAt),
+		Filters: &proto.TrackingFilters{
+			IncludePaths:   info.Filters.IncludePaths,
+			ExcludePaths:   info.Filters.ExcludePaths,
+			AllowAuthors:   info.Filters.AllowAuthors,
+			DenyAuthors:    info.Filters.DenyAuthors,
+			MessagePattern: info.Filters.MessagePattern,
+		},
+	}
+	if info.PausedUntil != nil {
+		result.PausedUntil = timestamppb.New(*info.PausedUntil)
+	}
+	if info.LastCommit != nil {
+		result.LastCommit = convertCommitInfoToProto(info.LastCommit)
+	}
+	return result
+}
+
+func convertCommitInfoToProto(info *server_model.CommitInfo) *proto.CommitInfo {
+	return &proto.CommitInfo{
+		Hash:        info.Hash,
+		Message:     info.Message,
+		Author:      info.Author,
+		Branch:      info.Branch,
+		CommittedAt: timestamppb.New(info.CommittedAt),
+	}
+}
+
+func convertProtoVisibility(visibility proto.RepoVisibility) string {
+	switch visibility {
+	case proto.RepoVisibility_REPO_VISIBILITY_PUBLIC:
+		return "public"
+	case proto.RepoVisibility_REPO_VISIBILITY_PRIVATE:
+		return "private"
+	default:
+		return "all"
+	}
+}
+
+func convertImportStatusToProto(importStatus server_model.ImportStatus) proto.ImportStatus {
+	switch importStatus {
+	case server_model.ImportStatusAdded:
+		return proto.ImportStatus_IMPORT_STATUS_ADDED
+	case server_model.ImportStatusAlreadyTracked:
+		return proto.ImportStatus_IMPORT_STATUS_ALREADY_TRACKED
+	case server_model.ImportStatusFailed:
+		return proto.ImportStatus_IMPORT_STATUS_FAILED
+	default:
+		return proto.ImportStatus_IMPORT_STATUS_UNSPECIFIED
+	}
+}
+
+func convertEventToProto(event stream.Event) *proto.ChangeEvent {
+	return &proto.ChangeEvent{
+		EventId:   event.ID,
+		ChatId:    event.ChatID,
+		Link:      event.Change.Link,
+		Author:    event.Change.Author,
+		Title:     event.Change.Title,
+		Branch:    event.Change.Branch,
+		UpdatedAt: timestamppb.New(event.Change.UpdatedAt),
+	}
 }
 
 func convertErrToGrpcError(err error) error {
-	if err != nil {
-		switch err {
-		case errs.ErrUserNotFound:
-			st := status.New(codes.NotFound, err.Error())
-			detail := &errdetails.ErrorInfo{
-				Reason: USER_NOT_FOUND_REASON,
-			}
-			stWithDetails, _ := st.WithDetails(detail)
-			return stWithDetails.Err()
-		case errs.ErrNotValidData:
-			return status.Error(codes.InvalidArgument, err.Error())
-		case errs.ErrRepoNotFound:
-			st := status.New(codes.NotFound, err.Error())
-			detail := &errdetails.ErrorInfo{
-				Reason: REPO_NOT_FOUND_REASON,
-			}
-			stWithDetails, _ := st.WithDetails(detail)
-			return stWithDetails.Err()
-		case errs.ErrInvalidToken:
-			return status.Error(codes.PermissionDenied, err.Error())
-		case errs.ErrInternal:
-			return status.Error(codes.Internal, err.Error())
-		default:
-			return status.Error(codes.Internal, err.Error())
+	if err == nil {
+		return nil
+	}
+	metadata := map[string]string{"error": err.Error()}
+	var linkErr *errs.LinkError
+	if errors.As(err, &linkErr) {
+		metadata["link"] = linkErr.Link
+	}
+	var retryAfter time.Duration
+	var rateErr *errs.RateLimitError
+	if errors.As(err, &rateErr) {
+		retryAfter = max(rateErr.RetryAfter, time.Second)
+		metadata["reset_at"] = rateErr.ResetAt.UTC().Format(time.RFC3339)
+	}
+	for _, mapping := range errorMappings {
+		if errors.Is(err, mapping.target) {
+			return newStatusError(mapping.code, mapping.reason, err.Error(), metadata, retryAfter)
 		}
 	}
-	return nil
+	zap.L().Error("Unexpected error in RepTrackerService", zap.Error(err))
+	return newStatusError(codes.Internal, INTERNAL_REASON, errs.ErrInternal.Error(), map[string]string{"error": errs.ErrInternal.Error()}, 0)
+}
+
+// newStatusError builds a status with ErrorInfo and, when retryAfter is set, RetryInfo.
+func newStatusError(code codes.Code, reason string, message string, metadata map[string]string, retryAfter time.Duration) error {
+	st := status.New(code, message)
+	details := []protoadapt.MessageV1{&errdetails.ErrorInfo{
+		Reason:   reason,
+		Domain:   ERROR_DOMAIN,
+		Metadata: metadata,
+	}}
+	if retryAfter > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+	}
+	stWithDetails, err := st.WithDetails(details...)
+	if err != nil {
+		return st.Err()
+	}
+	return stWithDetails.Err()
 }
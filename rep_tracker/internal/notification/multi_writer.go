@@ -0,0 +1,48 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"rep_tracker/pkg/dto"
+
+	"go.uber.org/zap"
+)
+
+// MultiNotificationWriter delivers to several writers. A failure of a writer keeps the subscription
+// cursor, so the notification is retried and written again to the writers that already accepted it.
+// Best-effort writers are therefore written only once the others accepted, and their failures are not
+// returned: a retry must not be caused by them.
+type MultiNotificationWriter struct {
+	writers    []NotificationWriter
+	bestEffort []NotificationWriter
+}
+
+func NewMultiNotificationWriter(writers ...NotificationWriter) *MultiNotificationWriter {
+	return &MultiNotificationWriter{writers: writers}
+}
+
+// WithBestEffort adds writers whose failures are only logged.
+func (mw *MultiNotificationWriter) WithBestEffort(writers ...NotificationWriter) *MultiNotificationWriter {
+	mw.bestEffort = append(mw.bestEffort, writers...)
+	return mw
+}
+
+// WriteNotification writes to every writer, even if some of them fail, and then to the best-effort
+// writers if none did.
+func (mw *MultiNotificationWriter) WriteNotification(ctx context.Context, chatId string, dto *dto.ChangingDTO) error {
+	var errList []error
+	for _, writer := range mw.writers {
+		if err := writer.WriteNotification(ctx, chatId, dto); err != nil {
+			errList = append(errList, err)
+		}
+	}
+	if len(errList) > 0 {
+		return errors.Join(errList...)
+	}
+	for _, writer := range mw.bestEffort {
+		if err := writer.WriteNotification(ctx, chatId, dto); err != nil {
+			zap.S().Warnf("best-effort notification writer %T failed for chat %v: %v", writer, chatId, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,52 @@
+package notification
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"rep_tracker/pkg/dto"
+)
+
+type recordingWriter struct {
+	err    error
+	writes int
+}
+
+func (w *recordingWriter) WriteNotification(ctx context.Context, chatId string, dto *dto.ChangingDTO) error {
+	w.writes++
+	return w.err
+}
+
+func TestMultiNotificationWriter(t *testing.T) {
+	failure := errors.New("broker unavailable")
+	tests := []struct {
+		name           string
+		writerErr      error
+		bestEffortErr  error
+		wantErr        error
+		wantBestEffort int
+	}{
+		{name: "delivered", wantBestEffort: 1},
+		// A retry would stream the notification again, so it is streamed only once it is delivered.
+		{name: "writer fails", writerErr: failure, wantErr: failure},
+		{name: "best-effort writer fails", bestEffortErr: failure, wantBestEffort: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &recordingWriter{err: tt.writerErr}
+			bestEffort := &recordingWriter{err: tt.bestEffortErr}
+			mw := NewMultiNotificationWriter(writer).WithBestEffort(bestEffort)
+			err := mw.WriteNotification(context.Background(), "1", &dto.ChangingDTO{})
+			if !errors.Is(err, tt.wantErr) || (tt.wantErr == nil && err != nil) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+			if writer.writes != 1 {
+				t.Errorf("writer writes = %v, want 1", writer.writes)
+			}
+			if bestEffort.writes != tt.wantBestEffort {
+				t.Errorf("best-effort writes = %v, want %v", bestEffort.writes, tt.wantBestEffort)
+			}
+		})
+	}
+}
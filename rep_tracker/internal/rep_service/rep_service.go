@@ -2,50 +2,71 @@ package rep_service
 
 import (
 	"context"
+	"encoding/base64"
+	"errors"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
 	"rep_tracker/internal/repo"
 	"rep_tracker/internal/server_model"
 	"rep_tracker/pkg/errs"
-	"rep_tracker/pkg/github"
+	"rep_tracker/pkg/filters"
+	"rep_tracker/pkg/forge"
+	"rep_tracker/pkg/repolink"
+	"service_common/logctx"
 	"go.uber.org/zap"
 )
 
+const (
+	defaultListPageSize = 20
+	maxListPageSize     = 100
+)
+
 type RepService struct {
-	ghClient   *github.GithubClient
+	forge      forge.Forge
 	tokenRepo  repo.TokenRepo
 	serverRepo repo.ServerRepo
 }
 
-func NewRepService(ghClient *github.GithubClient, tokenRepo repo.TokenRepo, serverRepo repo.ServerRepo) *RepService {
+func NewRepService(forge forge.Forge, tokenRepo repo.TokenRepo, serverRepo repo.ServerRepo) *RepService {
 	return &RepService{
-		ghClient:   ghClient,
+		forge:      forge,
 		tokenRepo:  tokenRepo,
 		serverRepo: serverRepo,
 	}
 }
 
 func (service *RepService) AddTrackingRepo(ctx context.Context, trackingRepo *server_model.TrackingRepo) error {
-	zap.L().Info("Starting AddTrackingRepo in RepService", 
+	logctx.From(ctx).Info("Starting AddTrackingRepo in RepService", 
 		zap.String("link", trackingRepo.Link), 
 		zap.String("chatId", trackingRepo.ChatID))
 	
-	// Step 1: Get user token
-	zap.L().Debug("Step 1: Getting token for chatId", zap.String("chatId", trackingRepo.ChatID))
-	token, err := service.tokenRepo.GetToken(ctx, trackingRepo.ChatID)
+	// Step 1: Parse the link, tokens are stored per host
+	link, err := service.forge.ParseLink(trackingRepo.Link)
+	if err != nil {
+		return err
+	}
+
+	// Step 2: Get user token
+	logctx.From(ctx).Debug("Step 2: Getting token for chatId", zap.String("chatId", trackingRepo.ChatID))
+	token, err := service.tokenRepo.GetToken(ctx, trackingRepo.ChatID, link.Host)
 	if err != nil {
-		zap.L().Error("Failed to get token", 
+		logctx.From(ctx).Error("Failed to get token", 
 			zap.String("chatId", trackingRepo.ChatID), 
 			zap.Error(err))
-		return errs.ErrInternal
+		return tokenError(err)
 	}
-	zap.L().Debug("Token retrieved successfully", zap.String("chatId", trackingRepo.ChatID))
+	logctx.From(ctx).Debug("Token retrieved successfully", zap.String("chatId", trackingRepo.ChatID))
 	
-	// Step 2: Check if repository exists on GitHub
-	zap.L().Debug("Step 2: Checking repository existence on GitHub", 
+	// Step 3: Check if repository exists on GitHub
+	logctx.From(ctx).Debug("Step 3: Checking repository existence on GitHub", 
 		zap.String("link", trackingRepo.Link), 
 		zap.String("chatId", trackingRepo.ChatID))
-	exists, err := service.ghClient.CheckRepo(ctx, token, trackingRepo.Link)
+	exists, err := service.forge.CheckRepo(ctx, token, trackingRepo.Link)
 	if err != nil {
-		zap.L().Error("GitHub repo check failed", 
+		logctx.From(ctx).Error("GitHub repo check failed", 
 			zap.String("link", trackingRepo.Link), 
 			zap.String("chatId", trackingRepo.ChatID),
 			zap.Error(err))
@@ -53,35 +74,287 @@ func (service *RepService) AddTrackingRepo(ctx context.Context, trackingRepo *se
 	}
 	
 	if !exists {
-		zap.L().Warn("Repository not found on GitHub", 
+		logctx.From(ctx).Warn("Repository not found on GitHub", 
 			zap.String("link", trackingRepo.Link), 
 			zap.String("chatId", trackingRepo.ChatID))
 		return errs.ErrRepoNotFound
 	}
-	zap.L().Info("Repository exists on GitHub", 
+	logctx.From(ctx).Info("Repository exists on GitHub", 
 		zap.String("link", trackingRepo.Link), 
 		zap.String("chatId", trackingRepo.ChatID))
 	
-	// Step 3: Add to server repository for notifications
-	zap.L().Debug("Step 3: Adding repository to server notifications", 
+	// Step 4: Add to server repository for notifications
+	logctx.From(ctx).Debug("Step 4: Adding repository to server notifications", 
 		zap.String("link", trackingRepo.Link), 
 		zap.String("chatId", trackingRepo.ChatID))
 	err = service.serverRepo.AddNotificationRep(ctx, trackingRepo)
 	if err != nil {
-		zap.L().Error("Failed to add repository to server notifications", 
+		logctx.From(ctx).Error("Failed to add repository to server notifications", 
 			zap.String("link", trackingRepo.Link), 
 			zap.String("chatId", trackingRepo.ChatID),
 			zap.Error(err))
 		return err
 	}
 	
-	zap.L().Info("AddTrackingRepo completed successfully", 
+	logctx.From(ctx).Info("AddTrackingRepo completed successfully", 
 		zap.String("link", trackingRepo.Link), 
 		zap.String("chatId", trackingRepo.ChatID))
 	
 	return nil
 }
 
+func (service *RepService) ImportRepos(ctx context.Context, query *server_model.ImportReposQuery) ([]*server_model.ImportResult, error) {
+	if query.NamePattern != "" {
+		if _, err := path.Match(query.NamePattern, ""); err != nil {
+			return nil, errs.ErrNotValidData
+		}
+	}
+	host := hostOrDefault(query.Host)
+	token, err := service.tokenRepo.GetToken(ctx, query.ChatID, host)
+	if err != nil {
+		logctx.From(ctx).Error("Failed to get token",
+			zap.String("chatId", query.ChatID),
+			zap.String("host", host),
+			zap.Error(err))
+		return nil, tokenError(err)
+	}
+	lister, ok := service.forge.(forge.RepoLister)
+	if !ok {
+		return nil, errs.ErrNotValidData
+	}
+	repos, err := lister.ListRepositories(ctx, host, token, query.Owner, query.Visibility)
+	if err != nil {
+		logctx.From(ctx).Error("Failed to list repositories",
+			zap.String("owner", query.Owner),
+			zap.String("chatId", query.ChatID),
+			zap.Error(err))
+		return nil, err
+	}
+
+	links := make([]string, 0, len(repos))
+	for _, repo := range repos {
+		if repo.Archived && !query.IncludeArchived {
+			continue
+		}
+		if (query.Visibility == "public" && repo.Private) || (query.Visibility == "private" && !repo.Private) {
+			continue
+		}
+		if query.NamePattern != "" {
+			if ok, _ := path.Match(query.NamePattern, repo.Name); !ok {
+				continue
+			}
+		}
+		links = append(links, repo.HTMLURL)
+	}
+	logctx.From(ctx).Info("Importing repositories",
+		zap.String("owner", query.Owner),
+		zap.String("chatId", query.ChatID),
+		zap.Int("listed", len(repos)),
+		zap.Int("matched", len(links)))
+	if len(links) == 0 {
+		return []*server_model.ImportResult{}, nil
+	}
+	return service.serverRepo.AddNotificationReps(ctx, query.ChatID, links)
+}
+
 func (service *RepService) RemoveTrackingRepo(ctx context.Context, trackingRepo *server_model.TrackingRepo) error {
 	return service.serverRepo.RemoveNotificationRep(ctx, trackingRepo)
 }
+
+func (service *RepService) PauseTrackingRepo(ctx context.Context, pause *server_model.PauseTrackingRepo) error {
+	if pause.PausedUntil != nil && !pause.PausedUntil.After(time.Now()) {
+		return errs.ErrNotValidData
+	}
+	return service.serverRepo.PauseNotificationRep(ctx, pause)
+}
+
+func (service *RepService) ResumeTrackingRepo(ctx context.Context, trackingRepo *server_model.TrackingRepo) error {
+	return service.serverRepo.ResumeNotificationRep(ctx, trackingRepo)
+}
+
+func (service *RepService) ResetCursor(ctx context.Context, reset *server_model.ResetCursor) error {
+	if reset.Since.IsZero() || reset.Since.After(time.Now()) {
+		return errs.ErrNotValidData
+	}
+	logctx.From(ctx).Info("Resetting tracking cursor",
+		zap.String("link", reset.Link),
+		zap.String("chatId", reset.ChatID),
+		zap.Time("since", reset.Since))
+	return service.serverRepo.ResetNotificationCursor(ctx, reset)
+}
+
+func (service *RepService) UpdateTrackingFilters(ctx context.Context, update *server_model.UpdateTrackingFilters) error {
+	if _, err := filters.Compile(update.Filters); err != nil {
+		logctx.From(ctx).Warn("Invalid tracking filters",
+			zap.String("link", update.Link),
+			zap.String("chatId", update.ChatID),
+			zap.Error(err))
+		return errs.ErrNotValidData
+	}
+	return service.serverRepo.UpdateNotificationFilters(ctx, update)
+}
+
+func (service *RepService) ListTrackingRepos(ctx context.Context, query *server_model.ListTrackingReposQuery) (*server_model.TrackingReposPage, error) {
+	afterID, err := decodePageToken(query.PageToken)
+	if err != nil {
+		return nil, errs.ErrNotValidData
+	}
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultListPageSize
+	}
+	if pageSize > maxListPageSize {
+		pageSize = maxListPageSize
+	}
+
+	// Fetch one extra row to know whether another page exists.
+	repos, err := service.serverRepo.ListNotificationReps(ctx, query.ChatID, afterID, pageSize+1)
+	if err != nil {
+		logctx.From(ctx).Error("Failed to list tracking repos",
+			zap.String("chatId", query.ChatID),
+			zap.Error(err))
+		return nil, err
+	}
+
+	page := &server_model.TrackingReposPage{Repos: repos}
+	if len(repos) > pageSize {
+		page.Repos = repos[:pageSize]
+		page.NextPageToken = encodePageToken(page.Repos[pageSize-1].ID)
+	}
+	return page, nil
+}
+
+func (service *RepService) GetRepoHistory(ctx context.Context, query *server_model.RepoHistoryQuery) (*server_model.RepoHistoryPage, error) {
+	after, err := decodeHistoryPageToken(query.PageToken)
+	if err != nil {
+		return nil, errs.ErrNotValidData
+	}
+	if query.Since != nil && query.Until != nil && !query.Since.Before(*query.Until) {
+		return nil, errs.ErrNotValidData
+	}
+	pageSize := query.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultListPageSize
+	}
+	if pageSize > maxListPageSize {
+		pageSize = maxListPageSize
+	}
+
+	commits, err := service.serverRepo.ListRepoCommits(ctx, query, after, pageSize+1)
+	if err != nil {
+		logctx.From(ctx).Error("Failed to get repo history",
+			zap.String("link", query.Link),
+			zap.String("chatId", query.ChatID),
+			zap.Error(err))
+		return nil, err
+	}
+
+	page := &server_model.RepoHistoryPage{Commits: commits}
+	if len(commits) > pageSize {
+		page.Commits = commits[:pageSize]
+		last := page.Commits[pageSize-1]
+		page.NextPageToken = encodeHistoryPageToken(&server_model.HistoryCursor{CommittedAt: last.CommittedAt, ID: last.ID})
+	}
+	return page, nil
+}
+
+// RefreshToken validates the new (or, when empty, the stored) token against GitHub and
+// re-enables the subscriptions that were disabled because the previous token was invalid.
+func (service *RepService) RefreshToken(ctx context.Context, refresh *server_model.RefreshToken) (*server_model.RefreshTokenResult, error) {
+	host := hostOrDefault(refresh.Host)
+	token := refresh.Token
+	if token == "" {
+		storedToken, err := service.tokenRepo.GetToken(ctx, refresh.ChatID, host)
+		if err != nil {
+			logctx.From(ctx).Error("Failed to get token",
+				zap.String("chatId", refresh.ChatID),
+				zap.Error(err))
+			return nil, tokenError(err)
+		}
+		token = storedToken
+	}
+	login, err := service.forge.ValidateToken(ctx, host, token)
+	if err != nil {
+		logctx.From(ctx).Warn("Token validation failed",
+			zap.String("chatId", refresh.ChatID),
+			zap.String("host", host),
+			zap.Error(err))
+		return nil, err
+	}
+	validatedAt := time.Now().UTC()
+	reenabled, err := service.tokenRepo.SaveValidatedToken(ctx, refresh.ChatID, host, refresh.Token, validatedAt)
+	if err != nil {
+		logctx.From(ctx).Error("Failed to save validated token",
+			zap.String("chatId", refresh.ChatID),
+			zap.Error(err))
+		return nil, err
+	}
+	logctx.From(ctx).Info("Token refreshed",
+		zap.String("chatId", refresh.ChatID),
+		zap.String("host", host),
+		zap.String("githubLogin", login),
+		zap.Int("reenabled", reenabled))
+	return &server_model.RefreshTokenResult{
+		GithubLogin:    login,
+		ValidatedAt:    validatedAt,
+		ReenabledCount: reenabled,
+	}, nil
+}
+
+func hostOrDefault(host string) string {
+	if host == "" {
+		return repolink.DefaultHost
+	}
+	return host
+}
+
+// tokenError lets a missing token reach the user and hides storage failures behind ErrInternal.
+func tokenError(err error) error {
+	if errors.Is(err, errs.ErrTokenMissing) {
+		return err
+	}
+	return errs.ErrInternal
+}
+
+func encodePageToken(lastID int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(lastID)))
+}
+
+func decodePageToken(token string) (int, error) {
+	if token == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.Atoi(string(raw))
+}
+
+func encodeHistoryPageToken(cursor *server_model.HistoryCursor) string {
+	raw := strconv.FormatInt(cursor.CommittedAt.UnixNano(), 10) + ":" + strconv.FormatInt(cursor.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeHistoryPageToken(token string) (*server_model.HistoryCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, err
+	}
+	nanosRaw, idRaw, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return nil, errs.ErrNotValidData
+	}
+	nanos, err := strconv.ParseInt(nanosRaw, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	id, err := strconv.ParseInt(idRaw, 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	return &server_model.HistoryCursor{CommittedAt: time.Unix(0, nanos).UTC(), ID: id}, nil
+}
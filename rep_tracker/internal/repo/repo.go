@@ -3,24 +3,35 @@ package repo
 import (
 	"context"
 	"rep_tracker/internal/server_model"
+	"rep_tracker/pkg/forge"
 	"rep_tracker/pkg/gorm"
-
-	"github.com/google/go-github/github"
+	"time"
 )
 
 type SchedulerRepo interface {
-	SaveCommitsAndUpdateNotification(ctx context.Context, commits ...*github.RepositoryCommit) error
+	SaveCommits(ctx context.Context, repoID int, branch string, commits ...*forge.Commit) error
+	AdvanceCursor(ctx context.Context, notificationID int, branch string, head string, headTime time.Time) error
+	MarkCommitsDropped(ctx context.Context, repoID int, hashes ...string) error
 	GetCountTrackingRepos(ctx context.Context) (int, error)
 	GetTrackingRepos(ctx context.Context, offset int, limit int) ([]*gorm.Notification, error)
 	DisableTracking(ctx context.Context, notificationID int) error
-	DisableTrackingForUser(ctx context.Context, userID int) error
+	DisableTrackingForUser(ctx context.Context, userID int, host string) error
+	ResumeExpiredPauses(ctx context.Context, now time.Time) (int, error)
 }
 
 type TokenRepo interface {
-	GetToken(ctx context.Context, chatId string) (string, error)
+	GetToken(ctx context.Context, chatId string, host string) (string, error)
+	SaveValidatedToken(ctx context.Context, chatId string, host string, token string, validatedAt time.Time) (int, error)
 }
 
 type ServerRepo interface {
 	AddNotificationRep(ctx context.Context, notification *server_model.TrackingRepo) error
+	AddNotificationReps(ctx context.Context, chatID string, links []string) ([]*server_model.ImportResult, error)
 	RemoveNotificationRep(ctx context.Context, notification *server_model.TrackingRepo) error
+	PauseNotificationRep(ctx context.Context, pause *server_model.PauseTrackingRepo) error
+	ResumeNotificationRep(ctx context.Context, notification *server_model.TrackingRepo) error
+	UpdateNotificationFilters(ctx context.Context, update *server_model.UpdateTrackingFilters) error
+	ResetNotificationCursor(ctx context.Context, reset *server_model.ResetCursor) error
+	ListNotificationReps(ctx context.Context, chatID string, afterID int, limit int) ([]*server_model.TrackingRepoInfo, error)
+	ListRepoCommits(ctx context.Context, query *server_model.RepoHistoryQuery, after *server_model.HistoryCursor, limit int) ([]*server_model.CommitInfo, error)
 }
@@ -1,6 +1,121 @@
 package server_model
 
+import (
+	"time"
+
+	"rep_tracker/pkg/filters"
+)
+
 type TrackingRepo struct {
+	Link     string
+	ChatID   string
+	Branches []string
+}
+
+type PauseTrackingRepo struct {
+	TrackingRepo
+	PausedUntil *time.Time
+}
+
+// ResetCursor moves a subscription back so commits after Since are reported again.
+type ResetCursor struct {
+	TrackingRepo
+	Since time.Time
+}
+
+type UpdateTrackingFilters struct {
+	TrackingRepo
+	Filters filters.Filters
+}
+
+type ListTrackingReposQuery struct {
+	ChatID    string
+	PageToken string
+	PageSize  int
+}
+
+type CommitInfo struct {
+	ID          int64
+	Hash        string
+	Message     string
+	Author      string
+	Branch      string
+	CommittedAt time.Time
+}
+
+type TrackingRepoInfo struct {
+	ID          int
+	Link        string
+	Owner       string
+	Name        string
+	Enabled     bool
+	PausedUntil *time.Time
+	Branches    []string
+	Filters     filters.Filters
+	CreatedAt   time.Time
+	LastCommit  *CommitInfo
+}
+
+type TrackingReposPage struct {
+	Repos         []*TrackingRepoInfo
+	NextPageToken string
+}
+
+type RepoHistoryQuery struct {
+	ChatID    string
+	Link      string
+	Since     *time.Time
+	Until     *time.Time
+	Author    string
+	Branch    string
+	PageToken string
+	PageSize  int
+}
+
+// HistoryCursor points at the last commit of the previous page.
+type HistoryCursor struct {
+	CommittedAt time.Time
+	ID          int64
+}
+
+type RepoHistoryPage struct {
+	Commits       []*CommitInfo
+	NextPageToken string
+}
+
+type ImportReposQuery struct {
+	ChatID          string
+	Owner           string
+	NamePattern     string
+	Visibility      string
+	IncludeArchived bool
+	// Host is the GitHub host to import from; empty means github.com.
+	Host string
+}
+
+type ImportStatus int
+
+const (
+	ImportStatusAdded ImportStatus = iota + 1
+	ImportStatusAlreadyTracked
+	ImportStatusFailed
+)
+
+type ImportResult struct {
 	Link   string
+	Status ImportStatus
+	Reason string
+}
+
+type RefreshToken struct {
 	ChatID string
+	Token  string
+	// Host is the GitHub host the token belongs to; empty means github.com.
+	Host string
+}
+
+type RefreshTokenResult struct {
+	GithubLogin    string
+	ValidatedAt    time.Time
+	ReenabledCount int
 }
@@ -3,21 +3,54 @@ package tasks
 import (
 	"context"
 	"errors"
+	"fmt"
 	"rep_tracker/internal/notification"
 	"rep_tracker/internal/repo"
 	"rep_tracker/pkg/dto"
 	"rep_tracker/pkg/errs"
-	"rep_tracker/pkg/github"
+	"rep_tracker/pkg/filters"
+	"rep_tracker/pkg/forge"
 	"rep_tracker/pkg/gorm"
+	"rep_tracker/pkg/metrics"
+	"rep_tracker/pkg/repolink"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	gh "github.com/google/go-github/github"
 	"go.uber.org/zap"
 )
 
-func GetCheckCommitsFunc(batchSize int, repo repo.SchedulerRepo, tokenRepo repo.TokenRepo, ghClient *github.GithubClient, writer notification.NotificationWriter) func(ctx context.Context) {
+type commitChecker struct {
+	repo      repo.SchedulerRepo
+	tokenRepo repo.TokenRepo
+	forge     forge.Forge
+	writer    notification.NotificationWriter
+	// maxCommits is how many commits are fetched per branch and cycle; beyond it a single summary is sent.
+	maxCommits int
+}
+
+func GetCheckCommitsFunc(batchSize int, maxCommits int, repo repo.SchedulerRepo, tokenRepo repo.TokenRepo, forge forge.Forge, writer notification.NotificationWriter) func(ctx context.Context) {
+	checker := &commitChecker{
+		repo:       repo,
+		tokenRepo:  tokenRepo,
+		forge:      forge,
+		writer:     writer,
+		maxCommits: max(maxCommits, 1),
+	}
 	return func(ctx context.Context) {
+		cycleStart := time.Now()
+		var checked atomic.Int64
+		defer func() {
+			metrics.CycleDuration.Observe(time.Since(cycleStart).Seconds())
+			metrics.ReposPerCycle.Set(float64(checked.Load()))
+		}()
+		resumed, err := repo.ResumeExpiredPauses(ctx, time.Now().UTC())
+		if err != nil {
+			zap.S().Warnf("resume expired pauses failed: %v", err)
+		} else if resumed > 0 {
+			zap.S().Infof("resumed %v paused subscriptions", resumed)
+		}
 		repoCount, err := repo.GetCountTrackingRepos(ctx)
 		if err != nil {
 			zap.S().Warn("repo count tracking repos failed", zap.Error(err))
@@ -37,106 +70,9 @@ func GetCheckCommitsFunc(batchSize int, repo repo.SchedulerRepo, tokenRepo repo.
 					zap.S().Warnf("get tracking repos failed (offset - %v, limit - %v): %v", localOffset, batchSize, currErr)
 				}
 				for _, currRepo := range currRepos {
-					token, currErr := tokenRepo.GetToken(localCtx, currRepo.User.ChatID)
-					if currErr != nil {
-						zap.S().Warnf("get token for user (user_is: %v) failed: %v", currRepo.User.ID, currErr)
-						continue
-					}
-					exists, currErr := ghClient.CheckRepo(localCtx, token, currRepo.Repo.URL)
-					if currErr != nil {
-						if errors.Is(currErr, errs.ErrInvalidToken) {
-							disableErr := repo.DisableTrackingForUser(localCtx, currRepo.User.ID)
-							if disableErr != nil {
-								zap.S().Warnf("disable tracking for user (user_id: %v) failed: %v", currRepo.User.ID, disableErr)
-							}
-							notifyErr := writer.WriteNotification(ctx, currRepo.User.ChatID, &dto.ChangingDTO{
-								Link:      currRepo.Repo.URL,
-								Author:    "system",
-								Title:     "Invalid PAT token. Tracking disabled until you. Refresh your token.",
-								UpdatedAt: time.Now().UTC(),
-							})
-							if notifyErr != nil {
-								zap.S().Warnf("write invalid token notification for user (user_id: %v) failed: %v", currRepo.User.ID, notifyErr)
-							}
-							continue
-						}
-						zap.S().Warnf("check repo - %v failed: %v", currRepo.Repo.URL, currErr)
-						continue
-					}
-					if !exists {
-						disableErr := repo.DisableTracking(localCtx, currRepo.ID)
-						if disableErr != nil {
-							zap.S().Warnf("disable tracking for repo - %v failed: %v", currRepo.Repo.URL, disableErr)
-						}
-						notifyErr := writer.WriteNotification(ctx, currRepo.User.ChatID, &dto.ChangingDTO{
-							Link:      currRepo.Repo.URL,
-							Author:    "system",
-							Title:     "Repository deleted or access lost. Tracking disabled.",
-							UpdatedAt: time.Now().UTC(),
-						})
-						if notifyErr != nil {
-							zap.S().Warnf("write deletion notification for repo - %v failed: %v", currRepo.Repo.URL, notifyErr)
-						}
-						continue
-					}
-					var lastCommitTime time.Time
-					if currRepo.LastCommitEntity != nil {
-						lastCommitTime = currRepo.LastCommitEntity.CreatedAt
-					} else {
-						lastCommitTime = currRepo.CreatedAt
-					}
-					newCommits, currErr := ghClient.GetCommitsSince(localCtx, token, currRepo.Repo.URL, lastCommitTime)
-					if currErr != nil {
-						if errors.Is(currErr, errs.ErrInvalidToken) {
-							disableErr := repo.DisableTrackingForUser(localCtx, currRepo.User.ID)
-							if disableErr != nil {
-								zap.S().Warnf("disable tracking for user (user_id: %v) failed: %v", currRepo.User.ID, disableErr)
-							}
-							notifyErr := writer.WriteNotification(ctx, currRepo.User.ChatID, &dto.ChangingDTO{
-								Link:      currRepo.Repo.URL,
-								Author:    "system",
-								Title:     "Invalid PAT token. Tracking disabled until you обновите токен.",
-								UpdatedAt: time.Now().UTC(),
-							})
-							if notifyErr != nil {
-								zap.S().Warnf("write invalid token notification for user (user_id: %v) failed: %v", currRepo.User.ID, notifyErr)
-							}
-							continue
-						}
-						zap.S().Warnf("get commits for repo - %v since (%v) failed: %v", currRepo.Repo.URL, lastCommitTime, currErr)
-					}
-					zap.S().Infof("get commits for repo - %v since (%v): %v", currRepo.Repo.URL, lastCommitTime, len(newCommits))
-					if len(newCommits) > 0 {
-						filteredCommits := filterNewCommits(newCommits, currRepo.LastCommitEntity)
-						if len(filteredCommits) == 0 {
-							continue
-						}
-						err = repo.SaveCommitsAndUpdateNotification(ctx, filteredCommits...)
-						if err != nil {
-							zap.S().Warnf("save commits failed: %v", err)
-							continue
-						}
-						for _, newCommit := range filteredCommits {
-							zap.L().Info("Sending notification to user",
-								zap.String("chat_id", currRepo.User.ChatID),
-								zap.String("commit_url", newCommit.GetCommit().GetURL()),
-								zap.String("commit_sha", newCommit.GetCommit().GetSHA()),
-								zap.String("repo_url", currRepo.Repo.URL))
-
-							currErr = writer.WriteNotification(ctx, currRepo.User.ChatID, dto.ConvertRepositoryCommitToDTO(newCommit))
-							if currErr != nil {
-								zap.L().Error("Failed to send notification about commit",
-									zap.String("commit_url", newCommit.GetCommit().GetURL()),
-									zap.String("commit_sha", newCommit.GetCommit().GetSHA()),
-									zap.String("chat_id", currRepo.User.ChatID),
-									zap.Error(currErr))
-							} else {
-								zap.L().Info("Successfully sent notification",
-									zap.String("commit_url", newCommit.GetCommit().GetURL()),
-									zap.String("chat_id", currRepo.User.ChatID))
-							}
-						}
-					}
+					checker.checkRepo(ctx, localCtx, currRepo)
+					checked.Add(1)
+					metrics.ReposChecked.Inc()
 				}
 			}()
 			offset += batchSize
@@ -145,7 +81,389 @@ func GetCheckCommitsFunc(batchSize int, repo repo.SchedulerRepo, tokenRepo repo.
 	}
 }
 
-func filterNewCommits(commits []*gh.RepositoryCommit, lastCommit *gorm.Commit) []*gh.RepositoryCommit {
+// rateLimitedUntil returns when the budget of the credentials used for the repository
+// allows requests again, or the zero time if it does now or is unknown.
+func (c *commitChecker) rateLimitedUntil(ctx context.Context, token string, link string) time.Time {
+	limiter, ok := c.forge.(forge.RateLimiter)
+	if !ok {
+		return time.Time{}
+	}
+	status, ok := limiter.RateLimitStatus(ctx, token, link)
+	if !ok {
+		return time.Time{}
+	}
+	return status.AvailableAt(time.Now())
+}
+
+// deferRateLimited leaves a rate limited repository for a later cycle instead of failing it.
+// Commits are fetched by time, so nothing is lost by checking it later.
+func (c *commitChecker) deferRateLimited(currRepo *gorm.Notification, err error) bool {
+	var limited *errs.RateLimitError
+	if !errors.As(err, &limited) {
+		return false
+	}
+	zap.S().Infof("repo - %v rate limited until %v, deferred to a later cycle", currRepo.Repo.URL, limited.ResetAt)
+	metrics.ReposRateLimited.Inc()
+	return true
+}
+
+func (c *commitChecker) checkRepo(ctx context.Context, localCtx context.Context, currRepo *gorm.Notification) {
+	link, currErr := c.forge.ParseLink(currRepo.Repo.URL)
+	if currErr != nil {
+		zap.S().Warnf("parse link of repo - %v failed: %v", currRepo.Repo.URL, currErr)
+		return
+	}
+	token, currErr := c.tokenRepo.GetToken(localCtx, currRepo.User.ChatID, link.Host)
+	// A user token is required even for repositories covered by an app installation,
+	// since CheckRepo verifies with it that the user still has access.
+	if currErr != nil {
+		zap.S().Warnf("get token for user (user_is: %v, host: %v) failed: %v", currRepo.User.ID, link.Host, currErr)
+		return
+	}
+	if availableAt := c.rateLimitedUntil(localCtx, token, currRepo.Repo.URL); !availableAt.IsZero() {
+		zap.S().Infof("skip repo - %v until %v: token rate limit exhausted", currRepo.Repo.URL, availableAt)
+		metrics.ReposRateLimited.Inc()
+		return
+	}
+	exists, currErr := c.forge.CheckRepo(localCtx, token, currRepo.Repo.URL)
+	if currErr != nil {
+		if errors.Is(currErr, errs.ErrInvalidToken) {
+			c.disableForInvalidToken(ctx, localCtx, currRepo, link.Host)
+			return
+		}
+		if c.deferRateLimited(currRepo, currErr) {
+			return
+		}
+		zap.S().Warnf("check repo - %v failed: %v", currRepo.Repo.URL, currErr)
+		return
+	}
+	if !exists {
+		disableErr := c.repo.DisableTracking(localCtx, currRepo.ID)
+		if disableErr != nil {
+			zap.S().Warnf("disable tracking for repo - %v failed: %v", currRepo.Repo.URL, disableErr)
+		}
+		notifyErr := c.writer.WriteNotification(ctx, currRepo.User.ChatID, &dto.ChangingDTO{
+			Link:      currRepo.Repo.URL,
+			Author:    "system",
+			Title:     "Repository deleted or access lost. Tracking disabled.",
+			UpdatedAt: time.Now().UTC(),
+		})
+		if notifyErr != nil {
+			zap.S().Warnf("write deletion notification for repo - %v failed: %v", currRepo.Repo.URL, notifyErr)
+		}
+		return
+	}
+	branches, currErr := c.forge.ResolveBranches(localCtx, token, currRepo.Repo.URL, currRepo.Branches)
+	if currErr != nil {
+		if errors.Is(currErr, errs.ErrInvalidToken) {
+			c.disableForInvalidToken(ctx, localCtx, currRepo, link.Host)
+			return
+		}
+		if c.deferRateLimited(currRepo, currErr) {
+			return
+		}
+		zap.S().Warnf("resolve branches for repo - %v failed: %v", currRepo.Repo.URL, currErr)
+		return
+	}
+	// Filters are validated when written, a row that still fails is skipped rather than sent unfiltered.
+	// Its cursor stays, so the commits are delivered once the filters are fixed.
+	matcher, currErr := filters.Compile(currRepo.Filters)
+	if currErr != nil {
+		zap.S().Errorf("compile filters for notification (id: %v) failed, skipping repo - %v: %v", currRepo.ID, currRepo.Repo.URL, currErr)
+		return
+	}
+	// A commit reachable from several tracked branches is reported only once.
+	sent := make(map[string]struct{})
+	for _, branch := range branches {
+		cursor := branchCursor(currRepo, branch)
+		since := cursor.Since
+		listed, currErr := c.forge.ListCommitsSince(localCtx, token, currRepo.Repo.URL, branch, cursor, c.maxCommits)
+		if currErr != nil {
+			if errors.Is(currErr, errs.ErrInvalidToken) {
+				c.disableForInvalidToken(ctx, localCtx, currRepo, link.Host)
+				return
+			}
+			if c.deferRateLimited(currRepo, currErr) {
+				return
+			}
+			zap.S().Warnf("get commits for repo - %v (branch %v) since (%v) failed: %v", currRepo.Repo.URL, branch, since, currErr)
+			continue
+		}
+		newCommits := listed.Commits
+		zap.S().Infof("get commits for repo - %v (branch %v) since (%v): %v (truncated: %v)", currRepo.Repo.URL, branch, since, len(newCommits), listed.Truncated)
+		if listed.Rewritten {
+			if c.handleRewrite(ctx, currRepo, link, branch, cursor.SHA, listed, sent) {
+				var headTime time.Time
+				if len(listed.Commits) > 0 && listed.Commits[0].SHA == listed.Head {
+					headTime = listed.Commits[0].CommittedAt
+				}
+				c.advanceCursor(ctx, currRepo, branch, listed.Head, headTime)
+			}
+			continue
+		}
+		if len(newCommits) == 0 {
+			continue
+		}
+		filteredCommits := newCommits
+		// Commits listed by time may include the last seen one; compared commits never do.
+		if cursor.SHA == "" {
+			filteredCommits = filterNewCommits(newCommits, currRepo.LastCommitEntity)
+		}
+		if len(filteredCommits) == 0 {
+			// Nothing new, but the head found by time lets the next check compare by SHA.
+			if cursor.SHA == "" {
+				c.advanceCursor(ctx, currRepo, branch, newCommits[0].SHA, newCommits[0].CommittedAt)
+			}
+			continue
+		}
+		err := c.repo.SaveCommits(ctx, currRepo.RepoID, branch, filteredCommits...)
+		if err != nil {
+			zap.S().Warnf("save commits failed: %v", err)
+			continue
+		}
+		// The last known commit was not reached within the limit, so there are more new commits than are worth a message each.
+		if listed.Truncated && len(filteredCommits) == len(newCommits) {
+			if c.notifyTruncated(ctx, currRepo, link, branch, cursor.SHA, filteredCommits, sent) {
+				c.advanceCursor(ctx, currRepo, branch, filteredCommits[0].SHA, filteredCommits[0].CommittedAt)
+			}
+			continue
+		}
+		delivered := make([]bool, len(filteredCommits))
+		for i, newCommit := range filteredCommits {
+			if _, ok := sent[newCommit.SHA]; ok {
+				delivered[i] = true
+				continue
+			}
+			matched, err := c.matchFilters(localCtx, token, currRepo, matcher, newCommit)
+			if err != nil {
+				// The commit is neither sent nor skipped, so the cursor stays before it and the next check retries.
+				zap.S().Warnf("get files of commit %v failed, retrying next check: %v", newCommit.SHA, err)
+				continue
+			}
+			if !matched {
+				zap.L().Debug("Commit skipped by subscription filters",
+					zap.String("commit_sha", newCommit.SHA),
+					zap.String("chat_id", currRepo.User.ChatID))
+				sent[newCommit.SHA] = struct{}{}
+				delivered[i] = true
+				continue
+			}
+			zap.L().Info("Sending notification to user",
+				zap.String("chat_id", currRepo.User.ChatID),
+				zap.String("commit_url", newCommit.HTMLURL),
+				zap.String("commit_sha", newCommit.SHA),
+				zap.String("repo_url", currRepo.Repo.URL),
+				zap.String("branch", branch))
+
+			currErr = c.writer.WriteNotification(ctx, currRepo.User.ChatID, dto.ConvertCommitToDTO(newCommit, branch))
+			if currErr != nil {
+				zap.L().Error("Failed to send notification about commit",
+					zap.String("commit_url", newCommit.HTMLURL),
+					zap.String("commit_sha", newCommit.SHA),
+					zap.String("chat_id", currRepo.User.ChatID),
+					zap.Error(currErr))
+			} else {
+				sent[newCommit.SHA] = struct{}{}
+				delivered[i] = true
+				zap.L().Info("Successfully sent notification",
+					zap.String("commit_url", newCommit.HTMLURL),
+					zap.String("chat_id", currRepo.User.ChatID))
+			}
+		}
+		// The cursor moves past the delivered commits up to the oldest undelivered one, which the next
+		// check lists again together with the commits after it.
+		if head := deliveredHead(filteredCommits, delivered); head != nil {
+			c.advanceCursor(ctx, currRepo, branch, head.SHA, head.CommittedAt)
+		}
+	}
+}
+
+// branchCursor returns where the subscription lists the branch from. Heads and their times are kept per
+// branch by the subscription itself, since a branch row is shared by every subscription of the repository.
+// A branch without a time of its own, e.g. one added later by a pattern, is listed from the subscription's
+// last delivered commit, the time it was reset to, or its creation.
+func branchCursor(currRepo *gorm.Notification, branch string) forge.Cursor {
+	cursor := forge.Cursor{SHA: currRepo.BranchHeads[branch]}
+	since, ok := currRepo.BranchSince[branch]
+	switch {
+	case ok:
+		cursor.Since = since
+	case currRepo.LastCommitEntity != nil:
+		cursor.Since = currRepo.LastCommitEntity.CreatedAt
+	case currRepo.CursorSince != nil:
+		cursor.Since = *currRepo.CursorSince
+	default:
+		cursor.Since = currRepo.CreatedAt
+	}
+	return cursor
+}
+
+// deliveredHead returns the newest commit of the delivered run starting at the oldest one,
+// or nil when the oldest commit was not delivered. Commits are newest first.
+func deliveredHead(commits []*forge.Commit, delivered []bool) *forge.Commit {
+	var head *forge.Commit
+	for i := len(commits) - 1; i >= 0 && delivered[i]; i-- {
+		head = commits[i]
+	}
+	return head
+}
+
+// advanceCursor moves the subscription past head on the branch, headTime is its commit time if known.
+func (c *commitChecker) advanceCursor(ctx context.Context, currRepo *gorm.Notification, branch string, head string, headTime time.Time) {
+	if head == "" {
+		return
+	}
+	if err := c.repo.AdvanceCursor(ctx, currRepo.ID, branch, head, headTime); err != nil {
+		zap.S().Warnf("advance cursor of notification (id: %v, branch %v) failed: %v", currRepo.ID, branch, err)
+	}
+}
+
+// handleRewrite stores the commits of a force-pushed branch, marks all the ones it dropped and reports
+// both in a single message instead of one message per new commit, naming at most maxListedCommits of each.
+// Filters are not applied, like for truncated pushes: the rewrite concerns the whole branch, and the
+// dropped commits may be ones the user was notified about. It reports whether the message was delivered.
+func (c *commitChecker) handleRewrite(ctx context.Context, currRepo *gorm.Notification, link repolink.Link, branch string, oldHead string, listed *forge.CommitList, sent map[string]struct{}) bool {
+	if len(listed.Commits) > 0 {
+		if err := c.repo.SaveCommits(ctx, currRepo.RepoID, branch, listed.Commits...); err != nil {
+			zap.S().Warnf("save commits failed: %v", err)
+			return false
+		}
+	}
+	dropped := make([]string, 0, len(listed.Dropped))
+	for _, commit := range listed.Dropped {
+		dropped = append(dropped, commit.SHA)
+	}
+	if err := c.repo.MarkCommitsDropped(ctx, currRepo.RepoID, dropped...); err != nil {
+		zap.S().Warnf("mark dropped commits of repo - %v (branch %v) failed: %v", currRepo.Repo.URL, branch, err)
+		return false
+	}
+	compareURL := link.CompareURL(oldHead, listed.Head)
+	zap.L().Info("Sending history rewrite notification to user",
+		zap.String("chat_id", currRepo.User.ChatID),
+		zap.String("compare_url", compareURL),
+		zap.Int("dropped", len(listed.Dropped)),
+		zap.Int("new", len(listed.Commits)),
+		zap.String("repo_url", currRepo.Repo.URL),
+		zap.String("branch", branch))
+	title := "History rewritten by force push.\nDropped:" + listCommits(listed.Dropped, false) + "\nNew:" + listCommits(listed.Commits, listed.Truncated)
+	err := c.writer.WriteNotification(ctx, currRepo.User.ChatID, &dto.ChangingDTO{
+		Link:      compareURL,
+		Author:    "system",
+		Title:     title,
+		Branch:    branch,
+		UpdatedAt: time.Now().UTC(),
+	})
+	if err != nil {
+		zap.L().Error("Failed to send history rewrite notification",
+			zap.String("compare_url", compareURL),
+			zap.String("chat_id", currRepo.User.ChatID),
+			zap.Error(err))
+		return false
+	}
+	for _, commit := range listed.Commits {
+		sent[commit.SHA] = struct{}{}
+	}
+	return true
+}
+
+// maxListedCommits bounds the commits named in a history rewrite message.
+const maxListedCommits = 10
+
+// listCommits renders commits as lines of short SHA and subject.
+func listCommits(commits []*forge.Commit, truncated bool) string {
+	if len(commits) == 0 {
+		return " none"
+	}
+	var b strings.Builder
+	for _, commit := range commits[:min(len(commits), maxListedCommits)] {
+		subject, _, _ := strings.Cut(commit.Message, "\n")
+		fmt.Fprintf(&b, "\n%v %v", commit.SHA[:min(len(commit.SHA), 7)], subject)
+	}
+	if rest := len(commits) - maxListedCommits; rest > 0 || truncated {
+		more := ""
+		if truncated {
+			more = "+"
+		}
+		fmt.Fprintf(&b, "\n… and %d%v more", max(rest, 0), more)
+	}
+	return b.String()
+}
+
+// notifyTruncated reports the commits of a large push as one message linking to the comparison
+// with the last known commit. Filters are not applied since the skipped commits are unknown.
+// It reports whether the message was delivered.
+func (c *commitChecker) notifyTruncated(ctx context.Context, currRepo *gorm.Notification, link repolink.Link, branch string, base string, commits []*forge.Commit, sent map[string]struct{}) bool {
+	newest := commits[0]
+	if base == "" && currRepo.LastCommitEntity != nil && currRepo.LastCommitEntity.CommitHash != nil {
+		base = *currRepo.LastCommitEntity.CommitHash
+	}
+	if base == "" {
+		base = commits[len(commits)-1].SHA
+	}
+	compareURL := link.CompareURL(base, newest.SHA)
+	zap.L().Info("Sending summary notification to user",
+		zap.String("chat_id", currRepo.User.ChatID),
+		zap.String("compare_url", compareURL),
+		zap.Int("commits", len(commits)),
+		zap.String("repo_url", currRepo.Repo.URL),
+		zap.String("branch", branch))
+	err := c.writer.WriteNotification(ctx, currRepo.User.ChatID, &dto.ChangingDTO{
+		Link:      compareURL,
+		Author:    "system",
+		Title:     fmt.Sprintf("%d+ more commits, see compare link", len(commits)),
+		Branch:    branch,
+		UpdatedAt: newest.CommittedAt,
+	})
+	if err != nil {
+		zap.L().Error("Failed to send summary notification",
+			zap.String("compare_url", compareURL),
+			zap.String("chat_id", currRepo.User.ChatID),
+			zap.Error(err))
+		return false
+	}
+	for _, commit := range commits {
+		sent[commit.SHA] = struct{}{}
+	}
+	return true
+}
+
+// matchFilters reports whether the commit passes the subscription filters. It fails when the
+// files of the commit are needed for path filters but cannot be fetched.
+func (c *commitChecker) matchFilters(ctx context.Context, token string, currRepo *gorm.Notification, matcher *filters.Matcher, commit *forge.Commit) (bool, error) {
+	if !matcher.MatchAuthor(commit.AuthorLogin) {
+		return false, nil
+	}
+	if !matcher.MatchMessage(commit.Message) {
+		return false, nil
+	}
+	if !currRepo.Filters.HasPathFilters() {
+		return true, nil
+	}
+	// ListCommits does not include changed files, so they are fetched only when path filters are set.
+	paths, err := c.forge.GetCommitFiles(ctx, token, currRepo.Repo.URL, commit.SHA)
+	if err != nil {
+		return false, err
+	}
+	return matcher.MatchPaths(paths), nil
+}
+
+func (c *commitChecker) disableForInvalidToken(ctx context.Context, localCtx context.Context, currRepo *gorm.Notification, host string) {
+	disableErr := c.repo.DisableTrackingForUser(localCtx, currRepo.User.ID, host)
+	if disableErr != nil {
+		zap.S().Warnf("disable tracking for user (user_id: %v) failed: %v", currRepo.User.ID, disableErr)
+	}
+	notifyErr := c.writer.WriteNotification(ctx, currRepo.User.ChatID, &dto.ChangingDTO{
+		Link:      currRepo.Repo.URL,
+		Author:    "system",
+		Title:     "Invalid PAT token. Tracking disabled until you refresh your token.",
+		UpdatedAt: time.Now().UTC(),
+	})
+	if notifyErr != nil {
+		zap.S().Warnf("write invalid token notification for user (user_id: %v) failed: %v", currRepo.User.ID, notifyErr)
+	}
+}
+
+func filterNewCommits(commits []*forge.Commit, lastCommit *gorm.Commit) []*forge.Commit {
 	if len(commits) == 0 {
 		return nil
 	}
@@ -153,12 +471,12 @@ func filterNewCommits(commits []*gh.RepositoryCommit, lastCommit *gorm.Commit) [
 		return commits
 	}
 	lastSHA := *lastCommit.CommitHash
-	filtered := make([]*gh.RepositoryCommit, 0, len(commits))
+	filtered := make([]*forge.Commit, 0, len(commits))
 	for _, commit := range commits {
-		if commit == nil || commit.GetSHA() == "" {
+		if commit == nil || commit.SHA == "" {
 			continue
 		}
-		if commit.GetSHA() == lastSHA {
+		if commit.SHA == lastSHA {
 			break
 		}
 		filtered = append(filtered, commit)
@@ -0,0 +1,469 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"rep_tracker/pkg/dto"
+	"rep_tracker/pkg/forge"
+	"rep_tracker/pkg/gorm"
+	"rep_tracker/pkg/repolink"
+)
+
+var base = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+func at(minutes int) time.Time {
+	return base.Add(time.Duration(minutes) * time.Minute)
+}
+
+// commit returns a commit whose message is its SHA, so notifications name the commit.
+func commit(sha string, minutes int) *forge.Commit {
+	return &forge.Commit{SHA: sha, Message: sha, CommittedAt: at(minutes)}
+}
+
+func ptrString(s string) *string {
+	return &s
+}
+
+type listCall struct {
+	branch string
+	cursor forge.Cursor
+}
+
+// fakeForge lists the commits returned by list and records the cursors it was asked for.
+type fakeForge struct {
+	branches []string
+	list     func(branch string, cursor forge.Cursor) *forge.CommitList
+	calls    []listCall
+}
+
+func (f *fakeForge) ParseLink(raw string) (repolink.Link, error) {
+	return repolink.Parse(raw)
+}
+
+func (f *fakeForge) ValidateToken(ctx context.Context, host string, token string) (string, error) {
+	return "user", nil
+}
+
+func (f *fakeForge) CheckRepo(ctx context.Context, token string, link string) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeForge) ResolveBranches(ctx context.Context, token string, link string, patterns []string) ([]string, error) {
+	return f.branches, nil
+}
+
+func (f *fakeForge) ListCommitsSince(ctx context.Context, token string, link string, branch string, cursor forge.Cursor, limit int) (*forge.CommitList, error) {
+	f.calls = append(f.calls, listCall{branch: branch, cursor: cursor})
+	return f.list(branch, cursor), nil
+}
+
+func (f *fakeForge) GetCommitFiles(ctx context.Context, token string, link string, sha string) ([]string, error) {
+	return nil, nil
+}
+
+type fakeTokenRepo struct{}
+
+func (fakeTokenRepo) GetToken(ctx context.Context, chatId string, host string) (string, error) {
+	return "token", nil
+}
+
+func (fakeTokenRepo) SaveValidatedToken(ctx context.Context, chatId string, host string, token string, validatedAt time.Time) (int, error) {
+	return 0, nil
+}
+
+type advanceCall struct {
+	notificationID int
+	branch         string
+	head           string
+	headTime       time.Time
+}
+
+// fakeSchedulerRepo records the cursor moves.
+type fakeSchedulerRepo struct {
+	advanced []advanceCall
+}
+
+func (r *fakeSchedulerRepo) SaveCommits(ctx context.Context, repoID int, branch string, commits ...*forge.Commit) error {
+	return nil
+}
+
+func (r *fakeSchedulerRepo) AdvanceCursor(ctx context.Context, notificationID int, branch string, head string, headTime time.Time) error {
+	r.advanced = append(r.advanced, advanceCall{notificationID: notificationID, branch: branch, head: head, headTime: headTime})
+	return nil
+}
+
+func (r *fakeSchedulerRepo) MarkCommitsDropped(ctx context.Context, repoID int, hashes ...string) error {
+	return nil
+}
+
+func (r *fakeSchedulerRepo) GetCountTrackingRepos(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+func (r *fakeSchedulerRepo) GetTrackingRepos(ctx context.Context, offset int, limit int) ([]*gorm.Notification, error) {
+	return nil, nil
+}
+
+func (r *fakeSchedulerRepo) DisableTracking(ctx context.Context, notificationID int) error {
+	return nil
+}
+
+func (r *fakeSchedulerRepo) DisableTrackingForUser(ctx context.Context, userID int, host string) error {
+	return nil
+}
+
+func (r *fakeSchedulerRepo) ResumeExpiredPauses(ctx context.Context, now time.Time) (int, error) {
+	return 0, nil
+}
+
+// fakeWriter fails the notifications titled in fail and records the delivered commits as "chat:title".
+// System messages, e.g. about a force push, are only counted.
+type fakeWriter struct {
+	fail      map[string]bool
+	delivered []string
+	system    int
+}
+
+func (w *fakeWriter) WriteNotification(ctx context.Context, chatId string, dto *dto.ChangingDTO) error {
+	if w.fail[dto.Title] {
+		return errors.New("broker unavailable")
+	}
+	if dto.Author == "system" {
+		w.system++
+		return nil
+	}
+	w.delivered = append(w.delivered, chatId+":"+dto.Title)
+	return nil
+}
+
+func newNotification(id int, chatID string) *gorm.Notification {
+	return &gorm.Notification{
+		ID:          id,
+		RepoID:      1,
+		CreatedAt:   at(-60),
+		BranchHeads: gorm.StringMap{},
+		BranchSince: gorm.TimeMap{},
+		User:        gorm.User{ID: id, ChatID: chatID},
+		Repo:        gorm.Repo{ID: 1, URL: "https://github.com/owner/repo"},
+	}
+}
+
+func TestBranchCursor(t *testing.T) {
+	cursorSince := at(-30)
+	lastCommit := &gorm.Commit{CommitHash: ptrString("a"), CreatedAt: at(-10)}
+	tests := []struct {
+		name   string
+		setup  func(n *gorm.Notification)
+		branch string
+		want   forge.Cursor
+	}{
+		{
+			name: "own head and time",
+			setup: func(n *gorm.Notification) {
+				n.BranchHeads["main"], n.BranchSince["main"] = "b", at(5)
+				n.LastCommitEntity = lastCommit
+			},
+			branch: "main",
+			want:   forge.Cursor{SHA: "b", Since: at(5)},
+		},
+		{
+			name: "branch added by a pattern after the last delivery",
+			setup: func(n *gorm.Notification) {
+				n.BranchHeads["main"], n.BranchSince["main"] = "b", at(5)
+				n.LastCommitEntity = lastCommit
+			},
+			branch: "feature",
+			want:   forge.Cursor{Since: at(-10)},
+		},
+		{
+			name: "head without a time, stored before times were kept",
+			setup: func(n *gorm.Notification) {
+				n.BranchHeads["main"] = "b"
+				n.LastCommitEntity = lastCommit
+			},
+			branch: "main",
+			want:   forge.Cursor{SHA: "b", Since: at(-10)},
+		},
+		{
+			name: "reset cursor",
+			setup: func(n *gorm.Notification) {
+				n.CursorSince = &cursorSince
+			},
+			branch: "main",
+			want:   forge.Cursor{Since: at(-30)},
+		},
+		{
+			name:   "new subscription",
+			setup:  func(n *gorm.Notification) {},
+			branch: "main",
+			want:   forge.Cursor{Since: at(-60)},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := newNotification(1, "1001")
+			tt.setup(n)
+			if got := branchCursor(n, tt.branch); got != tt.want {
+				t.Errorf("branchCursor = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeliveredHead(t *testing.T) {
+	commits := []*forge.Commit{commit("c", 3), commit("b", 2), commit("a", 1)}
+	tests := []struct {
+		name      string
+		delivered []bool
+		want      string
+	}{
+		{name: "all delivered", delivered: []bool{true, true, true}, want: "c"},
+		{name: "newest failed", delivered: []bool{false, true, true}, want: "b"},
+		{name: "middle failed", delivered: []bool{true, false, true}, want: "a"},
+		{name: "oldest failed", delivered: []bool{true, true, false}},
+		{name: "none delivered", delivered: []bool{false, false, false}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := deliveredHead(commits, tt.delivered)
+			if (got == nil && tt.want != "") || (got != nil && got.SHA != tt.want) {
+				t.Errorf("deliveredHead = %+v, want %q", got, tt.want)
+			}
+		})
+	}
+	if got := deliveredHead(nil, nil); got != nil {
+		t.Errorf("deliveredHead of no commits = %+v, want nil", got)
+	}
+}
+
+func TestCheckRepoAdvancesCursor(t *testing.T) {
+	tests := []struct {
+		name          string
+		setup         func(n *gorm.Notification)
+		branches      []string
+		list          func(branch string, cursor forge.Cursor) *forge.CommitList
+		fail          []string
+		wantCalls     []listCall
+		wantDelivered []string
+		wantSystem    int
+		wantAdvanced  []advanceCall
+	}{
+		{
+			name: "delivered after the head",
+			setup: func(n *gorm.Notification) {
+				n.BranchHeads["main"], n.BranchSince["main"] = "a", at(1)
+			},
+			branches: []string{"main"},
+			list: func(string, forge.Cursor) *forge.CommitList {
+				return &forge.CommitList{Commits: []*forge.Commit{commit("c", 3), commit("b", 2)}}
+			},
+			wantCalls:     []listCall{{branch: "main", cursor: forge.Cursor{SHA: "a", Since: at(1)}}},
+			wantDelivered: []string{"1001:c", "1001:b"},
+			wantAdvanced:  []advanceCall{{notificationID: 1, branch: "main", head: "c", headTime: at(3)}},
+		},
+		{
+			name: "partial delivery failure",
+			setup: func(n *gorm.Notification) {
+				n.BranchHeads["main"], n.BranchSince["main"] = "a", at(1)
+			},
+			branches: []string{"main"},
+			list: func(string, forge.Cursor) *forge.CommitList {
+				return &forge.CommitList{Commits: []*forge.Commit{commit("d", 4), commit("c", 3), commit("b", 2)}}
+			},
+			fail:          []string{"c"},
+			wantCalls:     []listCall{{branch: "main", cursor: forge.Cursor{SHA: "a", Since: at(1)}}},
+			wantDelivered: []string{"1001:d", "1001:b"},
+			// c is listed again by the next check, and d is delivered again with it.
+			wantAdvanced: []advanceCall{{notificationID: 1, branch: "main", head: "b", headTime: at(2)}},
+		},
+		{
+			name: "oldest delivery fails",
+			setup: func(n *gorm.Notification) {
+				n.BranchHeads["main"], n.BranchSince["main"] = "a", at(1)
+			},
+			branches: []string{"main"},
+			list: func(string, forge.Cursor) *forge.CommitList {
+				return &forge.CommitList{Commits: []*forge.Commit{commit("c", 3), commit("b", 2)}}
+			},
+			fail:          []string{"b"},
+			wantCalls:     []listCall{{branch: "main", cursor: forge.Cursor{SHA: "a", Since: at(1)}}},
+			wantDelivered: []string{"1001:c"},
+		},
+		{
+			name: "date fallback skips the last delivered commit",
+			setup: func(n *gorm.Notification) {
+				n.LastCommitEntity = &gorm.Commit{CommitHash: ptrString("a"), CreatedAt: at(1)}
+			},
+			branches: []string{"main"},
+			list: func(string, forge.Cursor) *forge.CommitList {
+				return &forge.CommitList{Commits: []*forge.Commit{commit("b", 2), commit("a", 1)}}
+			},
+			wantCalls:     []listCall{{branch: "main", cursor: forge.Cursor{Since: at(1)}}},
+			wantDelivered: []string{"1001:b"},
+			wantAdvanced:  []advanceCall{{notificationID: 1, branch: "main", head: "b", headTime: at(2)}},
+		},
+		{
+			name: "date fallback with nothing new",
+			setup: func(n *gorm.Notification) {
+				n.LastCommitEntity = &gorm.Commit{CommitHash: ptrString("a"), CreatedAt: at(1)}
+			},
+			branches: []string{"main"},
+			list: func(string, forge.Cursor) *forge.CommitList {
+				return &forge.CommitList{Commits: []*forge.Commit{commit("a", 1)}}
+			},
+			wantCalls: []listCall{{branch: "main", cursor: forge.Cursor{Since: at(1)}}},
+			// The head found by time lets the next check compare by SHA.
+			wantAdvanced: []advanceCall{{notificationID: 1, branch: "main", head: "a", headTime: at(1)}},
+		},
+		{
+			name: "branch added by a pattern",
+			setup: func(n *gorm.Notification) {
+				n.BranchHeads["main"], n.BranchSince["main"] = "b", at(2)
+				n.LastCommitEntity = &gorm.Commit{CommitHash: ptrString("b"), CreatedAt: at(2)}
+			},
+			branches: []string{"main", "feature"},
+			list: func(branch string, cursor forge.Cursor) *forge.CommitList {
+				if branch == "feature" {
+					return &forge.CommitList{Commits: []*forge.Commit{commit("f", 5), commit("b", 2)}}
+				}
+				return &forge.CommitList{}
+			},
+			wantCalls: []listCall{
+				{branch: "main", cursor: forge.Cursor{SHA: "b", Since: at(2)}},
+				{branch: "feature", cursor: forge.Cursor{Since: at(2)}},
+			},
+			wantDelivered: []string{"1001:f"},
+			wantAdvanced:  []advanceCall{{notificationID: 1, branch: "feature", head: "f", headTime: at(5)}},
+		},
+		{
+			name: "commit on two branches is delivered once",
+			setup: func(n *gorm.Notification) {
+				n.BranchHeads["main"], n.BranchSince["main"] = "a", at(1)
+				n.BranchHeads["dev"], n.BranchSince["dev"] = "a", at(1)
+			},
+			branches: []string{"main", "dev"},
+			list: func(string, forge.Cursor) *forge.CommitList {
+				return &forge.CommitList{Commits: []*forge.Commit{commit("b", 2)}}
+			},
+			wantCalls: []listCall{
+				{branch: "main", cursor: forge.Cursor{SHA: "a", Since: at(1)}},
+				{branch: "dev", cursor: forge.Cursor{SHA: "a", Since: at(1)}},
+			},
+			wantDelivered: []string{"1001:b"},
+			wantAdvanced: []advanceCall{
+				{notificationID: 1, branch: "main", head: "b", headTime: at(2)},
+				{notificationID: 1, branch: "dev", head: "b", headTime: at(2)},
+			},
+		},
+		{
+			name: "force push",
+			setup: func(n *gorm.Notification) {
+				n.BranchHeads["main"], n.BranchSince["main"] = "b", at(2)
+			},
+			branches: []string{"main"},
+			list: func(string, forge.Cursor) *forge.CommitList {
+				return &forge.CommitList{
+					Commits:   []*forge.Commit{commit("x", 3)},
+					Rewritten: true,
+					Dropped:   []*forge.Commit{commit("b", 2)},
+					Head:      "x",
+				}
+			},
+			wantCalls:    []listCall{{branch: "main", cursor: forge.Cursor{SHA: "b", Since: at(2)}}},
+			wantSystem:   1,
+			wantAdvanced: []advanceCall{{notificationID: 1, branch: "main", head: "x", headTime: at(3)}},
+		},
+		{
+			name: "force push to an older commit",
+			setup: func(n *gorm.Notification) {
+				n.BranchHeads["main"], n.BranchSince["main"] = "b", at(2)
+			},
+			branches: []string{"main"},
+			list: func(string, forge.Cursor) *forge.CommitList {
+				return &forge.CommitList{Rewritten: true, Dropped: []*forge.Commit{commit("b", 2)}, Head: "a"}
+			},
+			wantCalls:  []listCall{{branch: "main", cursor: forge.Cursor{SHA: "b", Since: at(2)}}},
+			wantSystem: 1,
+			// The time of the new head is unknown, so the cursor keeps only its SHA.
+			wantAdvanced: []advanceCall{{notificationID: 1, branch: "main", head: "a"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fakeForge := &fakeForge{branches: tt.branches, list: tt.list}
+			repo := &fakeSchedulerRepo{}
+			writer := &fakeWriter{fail: make(map[string]bool)}
+			for _, title := range tt.fail {
+				writer.fail[title] = true
+			}
+			checker := &commitChecker{repo: repo, tokenRepo: fakeTokenRepo{}, forge: fakeForge, writer: writer, maxCommits: 10}
+			n := newNotification(1, "1001")
+			tt.setup(n)
+
+			checker.checkRepo(context.Background(), context.Background(), n)
+
+			if !slices.Equal(fakeForge.calls, tt.wantCalls) {
+				t.Errorf("listed with %+v, want %+v", fakeForge.calls, tt.wantCalls)
+			}
+			if !slices.Equal(writer.delivered, tt.wantDelivered) {
+				t.Errorf("delivered = %v, want %v", writer.delivered, tt.wantDelivered)
+			}
+			if writer.system != tt.wantSystem {
+				t.Errorf("system messages = %v, want %v", writer.system, tt.wantSystem)
+			}
+			if !slices.EqualFunc(repo.advanced, tt.wantAdvanced, equalAdvance) {
+				t.Errorf("advanced = %+v, want %+v", repo.advanced, tt.wantAdvanced)
+			}
+		})
+	}
+}
+
+func equalAdvance(a advanceCall, b advanceCall) bool {
+	return a.notificationID == b.notificationID && a.branch == b.branch && a.head == b.head && a.headTime.Equal(b.headTime)
+}
+
+// TestCheckRepoPerSubscription checks two subscriptions of one repository: each lists from and
+// advances its own cursor, so one falling behind does not move or hold back the other.
+func TestCheckRepoPerSubscription(t *testing.T) {
+	history := []*forge.Commit{commit("c", 3), commit("b", 2), commit("a", 1)}
+	fakeForge := &fakeForge{
+		branches: []string{"main"},
+		list: func(_ string, cursor forge.Cursor) *forge.CommitList {
+			i := slices.IndexFunc(history, func(c *forge.Commit) bool { return c.SHA == cursor.SHA })
+			return &forge.CommitList{Commits: history[:i]}
+		},
+	}
+	repo := &fakeSchedulerRepo{}
+	writer := &fakeWriter{fail: map[string]bool{}}
+	checker := &commitChecker{repo: repo, tokenRepo: fakeTokenRepo{}, forge: fakeForge, writer: writer, maxCommits: 10}
+
+	ahead := newNotification(1, "1001")
+	ahead.BranchHeads["main"], ahead.BranchSince["main"] = "b", at(2)
+	behind := newNotification(2, "1002")
+	behind.BranchHeads["main"], behind.BranchSince["main"] = "a", at(1)
+
+	checker.checkRepo(context.Background(), context.Background(), ahead)
+	checker.checkRepo(context.Background(), context.Background(), behind)
+
+	wantCalls := []listCall{
+		{branch: "main", cursor: forge.Cursor{SHA: "b", Since: at(2)}},
+		{branch: "main", cursor: forge.Cursor{SHA: "a", Since: at(1)}},
+	}
+	if !slices.Equal(fakeForge.calls, wantCalls) {
+		t.Errorf("listed with %+v, want %+v", fakeForge.calls, wantCalls)
+	}
+	if want := []string{"1001:c", "1002:c", "1002:b"}; !slices.Equal(writer.delivered, want) {
+		t.Errorf("delivered = %v, want %v", writer.delivered, want)
+	}
+	wantAdvanced := []advanceCall{
+		{notificationID: 1, branch: "main", head: "c", headTime: at(3)},
+		{notificationID: 2, branch: "main", head: "c", headTime: at(3)},
+	}
+	if !slices.EqualFunc(repo.advanced, wantAdvanced, equalAdvance) {
+		t.Errorf("advanced = %+v, want %+v", repo.advanced, wantAdvanced)
+	}
+}
@@ -1,11 +1,50 @@
 package errs
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
 
 var (
-	ErrInternal     = errors.New("internal error")
-	ErrRepoNotFound = errors.New("not found")
-	ErrUserNotFound = errors.New("user not found")
-	ErrInvalidToken = errors.New("invalid token")
-	ErrNotValidData = errors.New("not valid data")
+	ErrInternal          = errors.New("internal error")
+	ErrRepoNotFound      = errors.New("not found")
+	ErrUserNotFound      = errors.New("user not found")
+	ErrInvalidToken      = errors.New("invalid token")
+	ErrNotValidData      = errors.New("not valid data")
+	ErrInvalidLink       = errors.New("invalid repository link")
+	ErrUnsupportedHost   = errors.New("unsupported repository host")
+	ErrRateLimited       = errors.New("rate limited")
+	ErrTokenMissing      = errors.New("token missing")
+	ErrGithubUnavailable = errors.New("github unavailable")
 )
+
+// LinkError reports a repository link that cannot be tracked.
+// Err is ErrInvalidLink or ErrUnsupportedHost.
+type LinkError struct {
+	Link string
+	Err  error
+}
+
+func (e *LinkError) Error() string {
+	return fmt.Sprintf("%v: %v", e.Err, e.Link)
+}
+
+func (e *LinkError) Unwrap() error {
+	return e.Err
+}
+
+// RateLimitError is returned when GitHub rejects a request because of a primary or secondary rate limit.
+type RateLimitError struct {
+	ResetAt    time.Time
+	RetryAfter time.Duration
+	Err        error
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%v until %v: %v", ErrRateLimited, e.ResetAt.Format(time.RFC3339), e.Err)
+}
+
+func (e *RateLimitError) Unwrap() []error {
+	return []error{ErrRateLimited, e.Err}
+}
@@ -0,0 +1,202 @@
+package filters
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Filters restrict which commits of a subscription produce notifications.
+// Empty fields do not filter anything.
+type Filters struct {
+	IncludePaths   []string `json:"include_paths,omitempty"`
+	ExcludePaths   []string `json:"exclude_paths,omitempty"`
+	AllowAuthors   []string `json:"allow_authors,omitempty"`
+	DenyAuthors    []string `json:"deny_authors,omitempty"`
+	MessagePattern string   `json:"message_pattern,omitempty"`
+}
+
+func (f Filters) Value() (driver.Value, error) {
+	raw, err := json.Marshal(f)
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func (f *Filters) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		*f = Filters{}
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for Filters: %T", src)
+	}
+	return json.Unmarshal(raw, f)
+}
+
+func (f Filters) HasPathFilters() bool {
+	return len(f.IncludePaths) > 0 || len(f.ExcludePaths) > 0
+}
+
+// Matcher is a compiled form of Filters.
+type Matcher struct {
+	include      []*regexp.Regexp
+	exclude      []*regexp.Regexp
+	allowAuthors map[string]struct{}
+	denyAuthors  map[string]struct{}
+	message      *regexp.Regexp
+}
+
+func Compile(f Filters) (*Matcher, error) {
+	m := &Matcher{
+		allowAuthors: toLoginSet(f.AllowAuthors),
+		denyAuthors:  toLoginSet(f.DenyAuthors),
+	}
+	var err error
+	if m.include, err = compileGlobs(f.IncludePaths); err != nil {
+		return nil, err
+	}
+	if m.exclude, err = compileGlobs(f.ExcludePaths); err != nil {
+		return nil, err
+	}
+	if f.MessagePattern != "" {
+		if m.message, err = regexp.Compile(f.MessagePattern); err != nil {
+			return nil, fmt.Errorf("invalid message pattern: %w", err)
+		}
+	}
+	return m, nil
+}
+
+func (m *Matcher) MatchAuthor(login string) bool {
+	login = strings.ToLower(login)
+	if _, denied := m.denyAuthors[login]; denied {
+		return false
+	}
+	if len(m.allowAuthors) == 0 {
+		return true
+	}
+	_, allowed := m.allowAuthors[login]
+	return allowed
+}
+
+func (m *Matcher) MatchMessage(message string) bool {
+	return m.message == nil || m.message.MatchString(message)
+}
+
+// MatchPaths reports whether at least one touched file is included and not excluded.
+func (m *Matcher) MatchPaths(paths []string) bool {
+	if len(m.include) == 0 && len(m.exclude) == 0 {
+		return true
+	}
+	for _, p := range paths {
+		if len(m.include) > 0 && !matchAny(m.include, p) {
+			continue
+		}
+		if matchAny(m.exclude, p) {
+			continue
+		}
+		return true
+	}
+	return false
+}
+
+func toLoginSet(logins []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(logins))
+	for _, login := range logins {
+		login = strings.ToLower(strings.TrimSpace(login))
+		if login != "" {
+			set[login] = struct{}{}
+		}
+	}
+	return set
+}
+
+func compileGlobs(globs []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(globs))
+	for _, glob := range globs {
+		re, err := globToRegexp(glob)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
+
+// globToRegexp supports "**" (any path), "*" (any chars except "/"), "?" (one char except "/")
+// and character classes such as "[abc]", "[a-z]" or "[!0-9]".
+func globToRegexp(glob string) (*regexp.Regexp, error) {
+	glob = strings.TrimPrefix(strings.TrimSpace(glob), "/")
+	if glob == "" {
+		return nil, fmt.Errorf("empty path pattern")
+	}
+	if strings.HasSuffix(glob, "/") {
+		glob += "**"
+	}
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(glob); i++ {
+		switch ch := glob[i]; ch {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("invalid path pattern %q: unterminated character class", glob)
+			}
+			class := glob[i+1 : i+1+end]
+			i += end + 1
+			sb.WriteString("[")
+			if negated, ok := strings.CutPrefix(class, "!"); ok {
+				// A negated class must not match the separator, like "*" and "?".
+				sb.WriteString("^/")
+				class = negated
+			}
+			if class == "" {
+				return nil, fmt.Errorf("invalid path pattern %q: empty character class", glob)
+			}
+			sb.WriteString(classEscaper.Replace(class))
+			sb.WriteString("]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	sb.WriteString("$")
+	re, err := regexp.Compile(sb.String())
+	if err != nil {
+		return nil, fmt.Errorf("invalid path pattern %q: %w", glob, err)
+	}
+	return re, nil
+}
+
+// classEscaper quotes the characters that are special inside a regexp class but not in a glob one.
+var classEscaper = strings.NewReplacer(`\`, `\\`, `[`, `\[`, `^`, `\^`)
+
+func matchAny(patterns []*regexp.Regexp, value string) bool {
+	for _, re := range patterns {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
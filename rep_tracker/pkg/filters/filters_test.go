@@ -0,0 +1,124 @@
+package filters
+
+import "testing"
+
+func TestMatchPaths(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters Filters
+		paths   []string
+		want    bool
+	}{
+		{name: "no filters", paths: []string{"main.go"}, want: true},
+		{name: "no files", filters: Filters{IncludePaths: []string{"**"}}, want: false},
+		{name: "double star any depth", filters: Filters{IncludePaths: []string{"docs/**"}}, paths: []string{"docs/a/b/c.md"}, want: true},
+		{name: "double star dir prefix matches root", filters: Filters{IncludePaths: []string{"**/go.mod"}}, paths: []string{"go.mod"}, want: true},
+		{name: "double star dir prefix matches nested", filters: Filters{IncludePaths: []string{"**/go.mod"}}, paths: []string{"tools/lint/go.mod"}, want: true},
+		{name: "trailing slash is a directory", filters: Filters{IncludePaths: []string{"docs/"}}, paths: []string{"docs/guide/intro.md"}, want: true},
+		{name: "leading slash is ignored", filters: Filters{IncludePaths: []string{"/cmd/*"}}, paths: []string{"cmd/main.go"}, want: true},
+		{name: "star within a segment", filters: Filters{IncludePaths: []string{"cmd/*.go"}}, paths: []string{"cmd/main.go"}, want: true},
+		{name: "star does not cross directories", filters: Filters{IncludePaths: []string{"cmd/*.go"}}, paths: []string{"cmd/tool/main.go"}, want: false},
+		{name: "question mark is one char", filters: Filters{IncludePaths: []string{"v?.txt"}}, paths: []string{"v1.txt"}, want: true},
+		{name: "question mark is not a separator", filters: Filters{IncludePaths: []string{"a?b"}}, paths: []string{"a/b"}, want: false},
+		{name: "dot is literal", filters: Filters{IncludePaths: []string{"*.md"}}, paths: []string{"READMExmd"}, want: false},
+		{name: "class", filters: Filters{IncludePaths: []string{"v[12].txt"}}, paths: []string{"v2.txt"}, want: true},
+		{name: "class miss", filters: Filters{IncludePaths: []string{"v[12].txt"}}, paths: []string{"v3.txt"}, want: false},
+		{name: "class range", filters: Filters{IncludePaths: []string{"part[a-c].go"}}, paths: []string{"partb.go"}, want: true},
+		{name: "negated class", filters: Filters{IncludePaths: []string{"v[!12].txt"}}, paths: []string{"v3.txt"}, want: true},
+		{name: "negated class miss", filters: Filters{IncludePaths: []string{"v[!12].txt"}}, paths: []string{"v1.txt"}, want: false},
+		{name: "negated class is not a separator", filters: Filters{IncludePaths: []string{"a[!x]b"}}, paths: []string{"a/b"}, want: false},
+		{name: "class with regexp chars", filters: Filters{IncludePaths: []string{"a[\\^]b"}}, paths: []string{"a^b"}, want: true},
+		{name: "paths are case sensitive", filters: Filters{IncludePaths: []string{"docs/**"}}, paths: []string{"Docs/intro.md"}, want: false},
+		{name: "exclude only", filters: Filters{ExcludePaths: []string{"**/*_test.go"}}, paths: []string{"pkg/a_test.go"}, want: false},
+		{name: "exclude only other file", filters: Filters{ExcludePaths: []string{"**/*_test.go"}}, paths: []string{"pkg/a_test.go", "pkg/a.go"}, want: true},
+		{name: "exclude wins over include", filters: Filters{IncludePaths: []string{"pkg/**"}, ExcludePaths: []string{"pkg/gen/**"}}, paths: []string{"pkg/gen/a.go"}, want: false},
+		{name: "one included file is enough", filters: Filters{IncludePaths: []string{"pkg/**"}, ExcludePaths: []string{"pkg/gen/**"}}, paths: []string{"pkg/gen/a.go", "pkg/b.go"}, want: true},
+		{name: "not included", filters: Filters{IncludePaths: []string{"pkg/**"}}, paths: []string{"cmd/main.go"}, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Compile(tt.filters)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.MatchPaths(tt.paths); got != tt.want {
+				t.Errorf("MatchPaths(%v) = %v, want %v", tt.paths, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchAuthor(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters Filters
+		login   string
+		want    bool
+	}{
+		{name: "no filters", login: "ann", want: true},
+		{name: "allowed", filters: Filters{AllowAuthors: []string{"ann"}}, login: "ann", want: true},
+		{name: "not allowed", filters: Filters{AllowAuthors: []string{"ann"}}, login: "bob", want: false},
+		{name: "denied", filters: Filters{DenyAuthors: []string{"bot"}}, login: "bot", want: false},
+		{name: "deny wins over allow", filters: Filters{AllowAuthors: []string{"ann"}, DenyAuthors: []string{"ann"}}, login: "ann", want: false},
+		{name: "case insensitive", filters: Filters{AllowAuthors: []string{" Ann "}}, login: "ANN", want: true},
+		{name: "case insensitive deny", filters: Filters{DenyAuthors: []string{"Dependabot"}}, login: "dependabot", want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Compile(tt.filters)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.MatchAuthor(tt.login); got != tt.want {
+				t.Errorf("MatchAuthor(%q) = %v, want %v", tt.login, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchMessage(t *testing.T) {
+	tests := []struct {
+		name    string
+		pattern string
+		message string
+		want    bool
+	}{
+		{name: "no pattern", message: "anything", want: true},
+		{name: "match", pattern: `^fix(\(.+\))?:`, message: "fix(api): handle nil", want: true},
+		{name: "miss", pattern: `^fix:`, message: "feat: add", want: false},
+		{name: "case sensitive", pattern: `^fix:`, message: "Fix: typo", want: false},
+		{name: "case flag", pattern: `(?i)^fix:`, message: "Fix: typo", want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m, err := Compile(Filters{MessagePattern: tt.pattern})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := m.MatchMessage(tt.message); got != tt.want {
+				t.Errorf("MatchMessage(%q) = %v, want %v", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCompileInvalid(t *testing.T) {
+	tests := []struct {
+		name    string
+		filters Filters
+	}{
+		{name: "empty path", filters: Filters{IncludePaths: []string{" "}}},
+		{name: "unterminated class", filters: Filters{IncludePaths: []string{"v[12.txt"}}},
+		{name: "empty class", filters: Filters{ExcludePaths: []string{"v[].txt"}}},
+		{name: "empty negated class", filters: Filters{ExcludePaths: []string{"v[!].txt"}}},
+		{name: "reversed range", filters: Filters{IncludePaths: []string{"[z-a]"}}},
+		{name: "message pattern", filters: Filters{MessagePattern: "fix("}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Compile(tt.filters); err == nil {
+				t.Errorf("Compile(%+v) succeeded, want an error", tt.filters)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+package forge
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"rep_tracker/pkg/repolink"
+)
+
+// Commit is a commit as reported by a forge.
+type Commit struct {
+	SHA     string
+	Message string
+	// AuthorLogin is the forge account of the author, or the git author name when the forge has none.
+	AuthorLogin string
+	HTMLURL     string
+	CommittedAt time.Time
+}
+
+// Cursor marks what has already been seen on a branch.
+type Cursor struct {
+	// SHA of the last seen commit, empty when nothing was seen yet.
+	SHA string
+	// Since is the commit time of the last seen commit.
+	Since time.Time
+}
+
+// Unseen reports whether the commit is newer than the cursor time. Forges list commits since a time
+// inclusively, so listings by date repeat the last seen commit.
+func (c Cursor) Unseen(commit *Commit) bool {
+	return c.Since.IsZero() || commit.CommittedAt.After(c.Since)
+}
+
+// CommitList is the result of ListCommitsSince.
+type CommitList struct {
+	// Commits are newest first.
+	Commits []*Commit
+	// Truncated is set when more commits than the limit follow the cursor; Commits then holds the newest of them.
+	Truncated bool
+	// Rewritten is set when the cursor SHA is no longer part of the branch, e.g. after a force push.
+	// Commits then are the new commits since the merge base, Dropped all of the removed ones regardless
+	// of the limit (newest first) and Head the new branch head.
+	Rewritten bool
+	Dropped   []*Commit
+	Head      string
+}
+
+// NewestFirst returns the newest limit of the commits, which compare APIs list oldest first.
+// Total is the number of commits after the base, which may exceed len(commits).
+func NewestFirst(commits []*Commit, total int, limit int) *CommitList {
+	start := max(len(commits)-limit, 0)
+	result := make([]*Commit, 0, len(commits)-start)
+	for i := len(commits) - 1; i >= start; i-- {
+		result = append(result, commits[i])
+	}
+	return &CommitList{Commits: result, Truncated: total > limit}
+}
+
+// Repository is an entry of ListRepositories.
+type Repository struct {
+	HTMLURL  string
+	Name     string
+	Private  bool
+	Archived bool
+}
+
+// Forge is a code hosting service repositories are tracked on (GitHub, GitLab, Gitea).
+// Links may be in any form accepted by ParseLink.
+type Forge interface {
+	// ParseLink canonicalizes a repository link on one of the forge hosts.
+	ParseLink(raw string) (repolink.Link, error)
+	// ValidateToken checks the token against the host and returns the login of its owner.
+	ValidateToken(ctx context.Context, host string, token string) (string, error)
+	// CheckRepo reports whether the repository exists and is visible to the token.
+	CheckRepo(ctx context.Context, token string, link string) (bool, error)
+	// ResolveBranches returns the branches matching the names or glob patterns,
+	// or only the default branch when no patterns are given.
+	ResolveBranches(ctx context.Context, token string, link string, patterns []string) ([]string, error)
+	// ListCommitsSince returns up to limit commits of the branch after the cursor, following pagination.
+	// Commits are compared with the cursor SHA; the cursor time is only used when the SHA is unset or unknown to the forge.
+	ListCommitsSince(ctx context.Context, token string, link string, branch string, cursor Cursor, limit int) (*CommitList, error)
+	// GetCommitFiles returns the paths of the files touched by the commit.
+	GetCommitFiles(ctx context.Context, token string, link string, sha string) ([]string, error)
+}
+
+// RepoLister is implemented by forges that can list repositories for bulk import.
+type RepoLister interface {
+	// ListRepositories lists repositories of a user or organization on the host,
+	// or every repository visible to the token when owner is empty. Visibility is one of all, public or private.
+	ListRepositories(ctx context.Context, host string, token string, owner string, visibility string) ([]*Repository, error)
+}
+
+// RateLimitStatus is the request budget of a token as last reported by the forge.
+type RateLimitStatus struct {
+	Limit     int
+	Remaining int
+	ResetAt   time.Time
+	// BlockedUntil is set while a secondary rate limit is backed off.
+	BlockedUntil time.Time
+}
+
+// AvailableAt returns when requests with the token are accepted again, or the zero time if they are now.
+func (s RateLimitStatus) AvailableAt(now time.Time) time.Time {
+	var at time.Time
+	if s.Limit > 0 && s.Remaining == 0 && s.ResetAt.After(now) {
+		at = s.ResetAt
+	}
+	if s.BlockedUntil.After(now) && s.BlockedUntil.After(at) {
+		at = s.BlockedUntil
+	}
+	return at
+}
+
+// RateLimiter is implemented by forges that track request budgets per token.
+type RateLimiter interface {
+	// RateLimitStatus returns the budget of the credentials used for the repository,
+	// false when nothing is known about them yet.
+	RateLimitStatus(ctx context.Context, token string, link string) (RateLimitStatus, bool)
+}
+
+// HostConfig describes an instance of a forge.
+type HostConfig struct {
+	// Host is the web host used in repository links, e.g. git.example.com.
+	Host string
+	// APIBaseURL is the REST API root; every forge defaults it from Host.
+	APIBaseURL string
+}
+
+// ParseHostConfigs parses a comma-separated list of host[=api_base_url] entries,
+// e.g. "git.example.com,git.corp=https://git.corp/api/v4/".
+func ParseHostConfigs(raw string) ([]HostConfig, error) {
+	configs := make([]HostConfig, 0)
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		host, apiBaseURL, _ := strings.Cut(entry, "=")
+		host = strings.ToLower(strings.TrimSpace(host))
+		if host == "" || strings.ContainsAny(host, "/:") {
+			return nil, fmt.Errorf("invalid host %q", entry)
+		}
+		configs = append(configs, HostConfig{Host: host, APIBaseURL: strings.TrimSpace(apiBaseURL)})
+	}
+	return configs, nil
+}
+
+// MatchBranch reports whether the branch name matches any of the names or glob patterns.
+func MatchBranch(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if pattern == name {
+			return true
+		}
+		if ok, err := path.Match(pattern, name); err == nil && ok {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateBranchPattern checks that the pattern is a well-formed branch glob.
+func ValidateBranchPattern(pattern string) error {
+	if strings.TrimSpace(pattern) == "" {
+		return fmt.Errorf("empty branch pattern")
+	}
+	_, err := path.Match(pattern, "")
+	return err
+}
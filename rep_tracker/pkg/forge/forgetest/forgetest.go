@@ -0,0 +1,88 @@
+// Package forgetest serves recorded forge API responses to the tests of the forge clients.
+package forgetest
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// Response is a recorded answer. File names the body in the testdata directory of the test package,
+// Status defaults to 200 and Header is added to the response, e.g. pagination headers.
+type Response struct {
+	File   string
+	Status int
+	Header map[string]string
+}
+
+// Server answers the requests it has a route for and fails the test on any other.
+type Server struct {
+	*httptest.Server
+
+	t      *testing.T
+	routes map[string]Response
+
+	mx       sync.Mutex
+	requests []string
+}
+
+// NewServer starts a server for the routes, keyed by Route. It is closed when the test ends.
+func NewServer(t *testing.T, routes map[string]Response) *Server {
+	t.Helper()
+	s := &Server{t: t, routes: routes}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// Route returns the key of a request: its escaped path, so encoded slashes are told apart,
+// and the query in url.Values.Encode order.
+func Route(path string, query url.Values) string {
+	if len(query) == 0 {
+		return path
+	}
+	return path + "?" + query.Encode()
+}
+
+// Requests returns the keys of the requests served so far, in order.
+func (s *Server) Requests() []string {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return append([]string(nil), s.requests...)
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	key := Route(r.URL.EscapedPath(), r.URL.Query())
+	s.mx.Lock()
+	s.requests = append(s.requests, key)
+	s.mx.Unlock()
+
+	resp, ok := s.routes[key]
+	if !ok {
+		s.t.Errorf("unexpected request %v %v", r.Method, key)
+		http.Error(w, `{"message":"Not Found"}`, http.StatusNotFound)
+		return
+	}
+	for name, value := range resp.Header {
+		w.Header().Set(name, value)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	var body []byte
+	if resp.File != "" {
+		var err error
+		body, err = os.ReadFile(filepath.Join("testdata", resp.File))
+		if err != nil {
+			s.t.Errorf("read recorded response: %v", err)
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+	}
+	if resp.Status != 0 {
+		w.WriteHeader(resp.Status)
+	}
+	w.Write(body)
+}
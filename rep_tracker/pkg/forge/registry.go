@@ -0,0 +1,122 @@
+package forge
+
+import (
+	"context"
+	"strings"
+
+	"rep_tracker/pkg/errs"
+	"rep_tracker/pkg/repolink"
+)
+
+// Registry is a Forge that dispatches every call to the forge serving the link host.
+type Registry struct {
+	forges map[string]Forge
+}
+
+func NewRegistry() *Registry {
+	return &Registry{forges: make(map[string]Forge)}
+}
+
+// Register routes links on the hosts to f. Hosts must also be registered in repolink.
+func (r *Registry) Register(f Forge, hosts ...string) *Registry {
+	for _, host := range hosts {
+		r.forges[strings.ToLower(host)] = f
+	}
+	return r
+}
+
+func (r *Registry) ParseLink(raw string) (repolink.Link, error) {
+	link, err := repolink.Parse(raw)
+	if err != nil {
+		return repolink.Link{}, err
+	}
+	if _, ok := r.forges[link.Host]; !ok {
+		return repolink.Link{}, &errs.LinkError{Link: raw, Err: errs.ErrUnsupportedHost}
+	}
+	return link, nil
+}
+
+func (r *Registry) ValidateToken(ctx context.Context, host string, token string) (string, error) {
+	f, err := r.forHost(host)
+	if err != nil {
+		return "", err
+	}
+	return f.ValidateToken(ctx, host, token)
+}
+
+func (r *Registry) CheckRepo(ctx context.Context, token string, link string) (bool, error) {
+	f, err := r.forLink(link)
+	if err != nil {
+		return false, err
+	}
+	return f.CheckRepo(ctx, token, link)
+}
+
+func (r *Registry) ResolveBranches(ctx context.Context, token string, link string, patterns []string) ([]string, error) {
+	f, err := r.forLink(link)
+	if err != nil {
+		return nil, err
+	}
+	return f.ResolveBranches(ctx, token, link, patterns)
+}
+
+func (r *Registry) ListCommitsSince(ctx context.Context, token string, link string, branch string, cursor Cursor, limit int) (*CommitList, error) {
+	f, err := r.forLink(link)
+	if err != nil {
+		return nil, err
+	}
+	return f.ListCommitsSince(ctx, token, link, branch, cursor, limit)
+}
+
+func (r *Registry) GetCommitFiles(ctx context.Context, token string, link string, sha string) ([]string, error) {
+	f, err := r.forLink(link)
+	if err != nil {
+		return nil, err
+	}
+	return f.GetCommitFiles(ctx, token, link, sha)
+}
+
+// ListRepositories lists repositories on hosts whose forge implements RepoLister.
+func (r *Registry) ListRepositories(ctx context.Context, host string, token string, owner string, visibility string) ([]*Repository, error) {
+	f, err := r.forHost(host)
+	if err != nil {
+		return nil, err
+	}
+	lister, ok := f.(RepoLister)
+	if !ok {
+		return nil, &errs.LinkError{Link: host, Err: errs.ErrUnsupportedHost}
+	}
+	return lister.ListRepositories(ctx, host, token, owner, visibility)
+}
+
+// RateLimitStatus reports false for links on forges that do not implement RateLimiter.
+func (r *Registry) RateLimitStatus(ctx context.Context, token string, link string) (RateLimitStatus, bool) {
+	f, err := r.forLink(link)
+	if err != nil {
+		return RateLimitStatus{}, false
+	}
+	limiter, ok := f.(RateLimiter)
+	if !ok {
+		return RateLimitStatus{}, false
+	}
+	return limiter.RateLimitStatus(ctx, token, link)
+}
+
+func (r *Registry) forHost(host string) (Forge, error) {
+	if host == "" {
+		host = repolink.DefaultHost
+	}
+	f, ok := r.forges[strings.ToLower(host)]
+	if !ok {
+		return nil, &errs.LinkError{Link: host, Err: errs.ErrUnsupportedHost}
+	}
+	return f, nil
+}
+
+func (r *Registry) forLink(link string) (Forge, error) {
+	parsed, err := r.ParseLink(link)
+	if err != nil {
+		return nil, err
+	}
+	return r.forges[parsed.Host], nil
+}
@@ -0,0 +1,131 @@
+package forge
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"rep_tracker/pkg/errs"
+)
+
+// RESTClient issues authenticated JSON requests against a forge REST API.
+type RESTClient struct {
+	BaseURL *url.URL
+	HTTP    *http.Client
+	// Authorize puts the token on the request.
+	Authorize func(req *http.Request, token string)
+}
+
+// NewRESTClient parses the API base URL, which must be absolute.
+func NewRESTClient(apiBaseURL string, authorize func(req *http.Request, token string)) (*RESTClient, error) {
+	if !strings.HasSuffix(apiBaseURL, "/") {
+		apiBaseURL += "/"
+	}
+	baseURL, err := url.Parse(apiBaseURL)
+	if err != nil || baseURL.Host == "" {
+		return nil, fmt.Errorf("invalid api base url %q", apiBaseURL)
+	}
+	return &RESTClient{BaseURL: baseURL, HTTP: http.DefaultClient, Authorize: authorize}, nil
+}
+
+// APIError is a non-2xx response of a forge API.
+type APIError struct {
+	StatusCode int
+	Message    string
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("api responded %d: %v", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether the API answered 404.
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Get requests path (relative to the base URL) and decodes the JSON body into target.
+// The returned response has its body closed and is kept for pagination headers.
+func (c *RESTClient) Get(ctx context.Context, token string, path string, query url.Values, target any) (*http.Response, error) {
+	u := c.BaseURL.JoinPath(path)
+	u.RawQuery = query.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if token != "" {
+		c.Authorize(req, token)
+	}
+	resp, err := c.HTTP.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp, newAPIError(resp)
+	}
+	if target != nil {
+		if err := json.NewDecoder(resp.Body).Decode(target); err != nil {
+			return resp, fmt.Errorf("decode %v: %w", u.Path, err)
+		}
+	}
+	return resp, nil
+}
+
+func newAPIError(resp *http.Response) *APIError {
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	var body struct {
+		Message string `json:"message"`
+		Error   string `json:"error"`
+	}
+	message := strings.TrimSpace(string(raw))
+	if json.Unmarshal(raw, &body) == nil {
+		if body.Message != "" {
+			message = body.Message
+		} else if body.Error != "" {
+			message = body.Error
+		}
+	}
+	apiErr := &APIError{StatusCode: resp.StatusCode, Message: message}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		apiErr.RetryAfter = time.Duration(seconds) * time.Second
+	}
+	return apiErr
+}
+
+// ConvertError maps REST errors to the errs taxonomy; unknown errors are returned unchanged.
+func ConvertError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		switch {
+		case apiErr.StatusCode == http.StatusUnauthorized:
+			return errs.ErrInvalidToken
+		case apiErr.StatusCode == http.StatusTooManyRequests || (apiErr.StatusCode == http.StatusForbidden && apiErr.RetryAfter > 0):
+			retryAfter := apiErr.RetryAfter
+			if retryAfter <= 0 {
+				retryAfter = time.Minute
+			}
+			return &errs.RateLimitError{ResetAt: time.Now().Add(retryAfter), RetryAfter: retryAfter, Err: err}
+		case apiErr.StatusCode >= 500:
+			return fmt.Errorf("%w: %v", errs.ErrGithubUnavailable, err)
+		}
+		return err
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", errs.ErrGithubUnavailable, err)
+	}
+	return err
+}
@@ -0,0 +1,278 @@
+package gitea
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"rep_tracker/pkg/errs"
+	"rep_tracker/pkg/forge"
+	"rep_tracker/pkg/repolink"
+)
+
+const pageLimit = 50
+
+// GiteaClient talks to the configured Gitea (and Forgejo) instances through the v1 REST API,
+// picking the instance from the repository link host.
+type GiteaClient struct {
+	hosts map[string]*forge.RESTClient
+}
+
+// NewGiteaClient returns a client for the given Gitea hosts.
+// An empty APIBaseURL defaults to https://{Host}/api/v1/.
+func NewGiteaClient(hosts ...forge.HostConfig) (*GiteaClient, error) {
+	c := &GiteaClient{hosts: make(map[string]*forge.RESTClient)}
+	for _, cfg := range hosts {
+		host := strings.ToLower(cfg.Host)
+		apiBaseURL := cfg.APIBaseURL
+		if apiBaseURL == "" {
+			apiBaseURL = "https://" + host + "/api/v1/"
+		}
+		rest, err := forge.NewRESTClient(apiBaseURL, func(req *http.Request, token string) {
+			req.Header.Set("Authorization", "token "+token)
+		})
+		if err != nil {
+			return nil, err
+		}
+		c.hosts[host] = rest
+		repolink.RegisterHost(repolink.KindGitea, host, rest.BaseURL.Hostname())
+	}
+	return c, nil
+}
+
+// Hosts returns the hosts served by the client.
+func (c *GiteaClient) Hosts() []string {
+	hosts := make([]string, 0, len(c.hosts))
+	for host := range c.hosts {
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+func (c *GiteaClient) ParseLink(raw string) (repolink.Link, error) {
+	link, err := repolink.Parse(raw)
+	if err != nil {
+		return repolink.Link{}, err
+	}
+	if _, ok := c.hosts[link.Host]; !ok || link.Kind != repolink.KindGitea {
+		return repolink.Link{}, &errs.LinkError{Link: raw, Err: errs.ErrUnsupportedHost}
+	}
+	return link, nil
+}
+
+func (c *GiteaClient) ValidateToken(ctx context.Context, host string, token string) (string, error) {
+	rest, ok := c.hosts[strings.ToLower(host)]
+	if !ok {
+		return "", &errs.LinkError{Link: host, Err: errs.ErrUnsupportedHost}
+	}
+	var user struct {
+		Login string `json:"login"`
+	}
+	if _, err := rest.Get(ctx, token, "user", nil, &user); err != nil {
+		return "", forge.ConvertError(err)
+	}
+	return user.Login, nil
+}
+
+func (c *GiteaClient) CheckRepo(ctx context.Context, token string, link string) (bool, error) {
+	rest, repoPath, err := c.repoForLink(link)
+	if err != nil {
+		return false, err
+	}
+	if _, err := rest.Get(ctx, token, repoPath, nil, nil); err != nil {
+		if forge.IsNotFound(err) {
+			return false, nil
+		}
+		return false, forge.ConvertError(err)
+	}
+	return true, nil
+}
+
+func (c *GiteaClient) ResolveBranches(ctx context.Context, token string, link string, patterns []string) ([]string, error) {
+	rest, repoPath, err := c.repoForLink(link)
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) == 0 {
+		var repo struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		if _, err := rest.Get(ctx, token, repoPath, nil, &repo); err != nil {
+			return nil, forge.ConvertError(err)
+		}
+		return []string{repo.DefaultBranch}, nil
+	}
+
+	matched := make([]string, 0)
+	for page := 1; ; page++ {
+		var branches []struct {
+			Name string `json:"name"`
+		}
+		query := url.Values{"limit": {strconv.Itoa(pageLimit)}, "page": {strconv.Itoa(page)}}
+		if _, err := rest.Get(ctx, token, repoPath+"/branches", query, &branches); err != nil {
+			return nil, forge.ConvertError(err)
+		}
+		for _, branch := range branches {
+			if forge.MatchBranch(patterns, branch.Name) {
+				matched = append(matched, branch.Name)
+			}
+		}
+		if len(branches) < pageLimit {
+			break
+		}
+	}
+	return matched, nil
+}
+
+type apiCommit struct {
+	SHA     string `json:"sha"`
+	HTMLURL string `json:"html_url"`
+	Commit  struct {
+		Message string `json:"message"`
+		Author  struct {
+			Name string `json:"name"`
+		} `json:"author"`
+		Committer struct {
+			Date time.Time `json:"date"`
+		} `json:"committer"`
+	} `json:"commit"`
+	// Author is the account matched to the commit email; null when there is none.
+	Author *struct {
+		Login string `json:"login"`
+	} `json:"author"`
+}
+
+// ListCommitsSince compares the cursor SHA with the branch, reporting a rewritten history when the SHA
+// is no longer an ancestor of the branch, and lists the branch since the cursor time when the SHA is
+// unknown to Gitea. Commits not linked to an account fall back to the git author name.
+func (c *GiteaClient) ListCommitsSince(ctx context.Context, token string, link string, branch string, cursor forge.Cursor, limit int) (*forge.CommitList, error) {
+	rest, repoPath, err := c.repoForLink(link)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.SHA != "" {
+		result, err := compare(ctx, rest, token, repoPath, cursor.SHA, branch, limit)
+		if err == nil {
+			// Gitea reports no comparison status, so commits only reachable from the old head reveal a rewrite.
+			dropped, err := compare(ctx, rest, token, repoPath, branch, cursor.SHA, math.MaxInt)
+			if err != nil {
+				return nil, forge.ConvertError(err)
+			}
+			if len(dropped.Commits) > 0 {
+				var head struct {
+					Commit struct {
+						ID string `json:"id"`
+					} `json:"commit"`
+				}
+				if _, err := rest.Get(ctx, token, repoPath+"/branches/"+url.PathEscape(branch), nil, &head); err != nil {
+					return nil, forge.ConvertError(err)
+				}
+				result.Rewritten = true
+				result.Dropped = dropped.Commits
+				result.Head = head.Commit.ID
+			}
+			return result, nil
+		}
+		if !forge.IsNotFound(err) {
+			return nil, forge.ConvertError(err)
+		}
+	}
+	query := url.Values{
+		"sha":          {branch},
+		"limit":        {strconv.Itoa(pageLimit)},
+		"stat":         {"false"},
+		"verification": {"false"},
+		"files":        {"false"},
+	}
+	if !cursor.Since.IsZero() {
+		query.Set("since", cursor.Since.UTC().Format(time.RFC3339))
+	}
+	result := &forge.CommitList{Commits: make([]*forge.Commit, 0)}
+	for page := 1; ; page++ {
+		var commits []apiCommit
+		query.Set("page", strconv.Itoa(page))
+		if _, err := rest.Get(ctx, token, repoPath+"/commits", query, &commits); err != nil {
+			return nil, forge.ConvertError(err)
+		}
+		for _, commit := range commits {
+			if commit.SHA == "" {
+				continue
+			}
+			if converted := convertCommit(commit); cursor.Unseen(converted) {
+				result.Commits = append(result.Commits, converted)
+			}
+		}
+		if len(result.Commits) > limit || len(commits) < pageLimit {
+			result.Truncated = len(result.Commits) > limit
+			result.Commits = result.Commits[:min(limit, len(result.Commits))]
+			return result, nil
+		}
+	}
+}
+
+// compare returns up to limit commits reachable from head but not from base.
+func compare(ctx context.Context, rest *forge.RESTClient, token string, repoPath string, base string, head string, limit int) (*forge.CommitList, error) {
+	var comparison struct {
+		TotalCommits int         `json:"total_commits"`
+		Commits      []apiCommit `json:"commits"`
+	}
+	if _, err := rest.Get(ctx, token, repoPath+"/compare/"+base+"..."+head, nil, &comparison); err != nil {
+		return nil, err
+	}
+	commits := make([]*forge.Commit, 0, len(comparison.Commits))
+	for _, commit := range comparison.Commits {
+		if commit.SHA != "" {
+			commits = append(commits, convertCommit(commit))
+		}
+	}
+	// Unlike GitHub and GitLab, Gitea lists compared commits newest first.
+	total := max(comparison.TotalCommits, len(commits))
+	return &forge.CommitList{Commits: commits[:min(limit, len(commits))], Truncated: total > limit}, nil
+}
+
+func convertCommit(commit apiCommit) *forge.Commit {
+	login := commit.Commit.Author.Name
+	if commit.Author != nil && commit.Author.Login != "" {
+		login = commit.Author.Login
+	}
+	return &forge.Commit{
+		SHA:         commit.SHA,
+		Message:     commit.Commit.Message,
+		AuthorLogin: login,
+		HTMLURL:     commit.HTMLURL,
+		CommittedAt: commit.Commit.Committer.Date,
+	}
+}
+
+func (c *GiteaClient) GetCommitFiles(ctx context.Context, token string, link string, sha string) ([]string, error) {
+	rest, repoPath, err := c.repoForLink(link)
+	if err != nil {
+		return nil, err
+	}
+	var commit struct {
+		Files []struct {
+			Filename string `json:"filename"`
+		} `json:"files"`
+	}
+	if _, err := rest.Get(ctx, token, repoPath+"/git/commits/"+url.PathEscape(sha), nil, &commit); err != nil {
+		return nil, forge.ConvertError(err)
+	}
+	paths := make([]string, 0, len(commit.Files))
+	for _, file := range commit.Files {
+		paths = append(paths, file.Filename)
+	}
+	return paths, nil
+}
+
+// repoForLink returns the API client of the link host and the repos/{owner}/{name} path.
+func (c *GiteaClient) repoForLink(link string) (*forge.RESTClient, string, error) {
+	parsed, err := c.ParseLink(link)
+	if err != nil {
+		return nil, "", err
+	}
+	return c.hosts[parsed.Host], "repos/" + url.PathEscape(parsed.Owner) + "/" + url.PathEscape(parsed.Name), nil
+}
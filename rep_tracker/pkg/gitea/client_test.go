@@ -0,0 +1,224 @@
+package gitea
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"rep_tracker/pkg/forge"
+	"rep_tracker/pkg/forge/forgetest"
+)
+
+const (
+	testLink = "https://gitea.test/Owner/Repo"
+	repoPath = "/api/v1/repos/owner/repo"
+
+	shaA0 = "aaa0000000000000000000000000000000000000"
+	shaA1 = "aaa1000000000000000000000000000000000001"
+	shaB2 = "bbb2000000000000000000000000000000000002"
+	shaC3 = "ccc3000000000000000000000000000000000003"
+	shaE5 = "eee5000000000000000000000000000000000005"
+)
+
+var cursorTime = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+func newTestClient(t *testing.T, routes map[string]forgetest.Response) *GiteaClient {
+	t.Helper()
+	srv := forgetest.NewServer(t, routes)
+	c, err := NewGiteaClient(forge.HostConfig{Host: "gitea.test", APIBaseURL: srv.URL + "/api/v1/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func shas(commits []*forge.Commit) []string {
+	result := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		result = append(result, commit.SHA)
+	}
+	return result
+}
+
+func compareRoute(base string, head string) string {
+	return repoPath + "/compare/" + base + "..." + head
+}
+
+func commitsRoute(branch string, since time.Time, page string) string {
+	return forgetest.Route(repoPath+"/commits", url.Values{
+		"sha":          {branch},
+		"since":        {since.Format(time.RFC3339)},
+		"limit":        {"50"},
+		"page":         {page},
+		"stat":         {"false"},
+		"verification": {"false"},
+		"files":        {"false"},
+	})
+}
+
+func TestResolveBranches(t *testing.T) {
+	c := newTestClient(t, map[string]forgetest.Response{
+		repoPath: {File: "repo.json"},
+		// A full page of 50 branches means there may be more.
+		forgetest.Route(repoPath+"/branches", url.Values{"limit": {"50"}, "page": {"1"}}): {File: "branches_page1.json"},
+		forgetest.Route(repoPath+"/branches", url.Values{"limit": {"50"}, "page": {"2"}}): {File: "branches_page2.json"},
+	})
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{name: "default branch", want: []string{"main"}},
+		{name: "patterns across pages", patterns: []string{"release/*"}, want: []string{"release/1.0", "release/2.0"}},
+		{name: "exact name", patterns: []string{"main"}, want: []string{"main"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.ResolveBranches(context.Background(), "token", testLink, tt.patterns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("branches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListCommitsSince(t *testing.T) {
+	tests := []struct {
+		name          string
+		cursor        forge.Cursor
+		limit         int
+		routes        map[string]forgetest.Response
+		wantCommits   []string
+		wantTruncated bool
+		wantDropped   []string
+		wantHead      string
+	}{
+		{
+			// Gitea already lists compared commits newest first, unlike GitHub and GitLab.
+			name:   "compared commits keep the newest first order",
+			cursor: forge.Cursor{SHA: shaA1, Since: cursorTime},
+			limit:  10,
+			routes: map[string]forgetest.Response{
+				compareRoute(shaA1, "main"): {File: "compare_ahead.json"},
+				compareRoute("main", shaA1): {File: "compare_empty.json"},
+			},
+			wantCommits: []string{shaC3, shaB2},
+		},
+		{
+			name:   "compared commits beyond the limit",
+			cursor: forge.Cursor{SHA: shaA1, Since: cursorTime},
+			limit:  1,
+			routes: map[string]forgetest.Response{
+				compareRoute(shaA1, "main"): {File: "compare_ahead.json"},
+				compareRoute("main", shaA1): {File: "compare_empty.json"},
+			},
+			wantCommits:   []string{shaC3},
+			wantTruncated: true,
+		},
+		{
+			name:   "rewritten branch",
+			cursor: forge.Cursor{SHA: shaA1, Since: cursorTime},
+			limit:  1,
+			routes: map[string]forgetest.Response{
+				compareRoute(shaA1, "main"): {File: "compare_rewritten.json"},
+				compareRoute("main", shaA1): {File: "compare_dropped.json"},
+				repoPath + "/branches/main": {File: "branch_main.json"},
+			},
+			wantCommits: []string{shaE5},
+			// All dropped commits are returned regardless of the limit.
+			wantDropped: []string{shaA1, shaA0},
+			wantHead:    shaE5,
+		},
+		{
+			name:   "listed by date when the cursor commit is unknown",
+			cursor: forge.Cursor{SHA: "gone", Since: cursorTime},
+			limit:  2,
+			routes: map[string]forgetest.Response{
+				compareRoute("gone", "main"):          {File: "not_found.json", Status: http.StatusNotFound},
+				commitsRoute("main", cursorTime, "1"): {File: "commits.json"},
+			},
+			// The cursor commit is listed again since "since" is inclusive, it must not count as truncation.
+			wantCommits: []string{shaC3, shaB2},
+		},
+		{
+			name:   "listed by date beyond the limit",
+			cursor: forge.Cursor{Since: cursorTime},
+			limit:  1,
+			routes: map[string]forgetest.Response{
+				commitsRoute("main", cursorTime, "1"): {File: "commits.json"},
+			},
+			wantCommits:   []string{shaC3},
+			wantTruncated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, tt.routes)
+			got, err := c.ListCommitsSince(context.Background(), "token", testLink, "main", tt.cursor, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(shas(got.Commits), tt.wantCommits) {
+				t.Errorf("commits = %v, want %v", shas(got.Commits), tt.wantCommits)
+			}
+			if got.Truncated != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", got.Truncated, tt.wantTruncated)
+			}
+			if got.Rewritten != (tt.wantDropped != nil) {
+				t.Errorf("rewritten = %v, want %v", got.Rewritten, tt.wantDropped != nil)
+			}
+			if !slices.Equal(shas(got.Dropped), tt.wantDropped) {
+				t.Errorf("dropped = %v, want %v", shas(got.Dropped), tt.wantDropped)
+			}
+			if got.Head != tt.wantHead {
+				t.Errorf("head = %q, want %q", got.Head, tt.wantHead)
+			}
+		})
+	}
+}
+
+func TestListCommitsSinceConvertsCommits(t *testing.T) {
+	c := newTestClient(t, map[string]forgetest.Response{
+		compareRoute(shaA1, "main"): {File: "compare_ahead.json"},
+		compareRoute("main", shaA1): {File: "compare_empty.json"},
+	})
+	got, err := c.ListCommitsSince(context.Background(), "token", testLink, "main", forge.Cursor{SHA: shaA1}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newest, older := got.Commits[0], got.Commits[1]
+	if newest.AuthorLogin != "ann" {
+		t.Errorf("author of linked commit = %q, want the account login", newest.AuthorLogin)
+	}
+	// Commits not linked to an account fall back to the git author name.
+	if older.AuthorLogin != "Bo Chen" {
+		t.Errorf("author of unlinked commit = %q, want the git author name", older.AuthorLogin)
+	}
+	if want := "https://gitea.test/owner/repo/commit/" + shaC3; newest.HTMLURL != want {
+		t.Errorf("html url = %q, want %q", newest.HTMLURL, want)
+	}
+	if want := time.Date(2024, 5, 1, 10, 10, 0, 0, time.UTC); !newest.CommittedAt.Equal(want) {
+		t.Errorf("committed at = %v, want %v", newest.CommittedAt, want)
+	}
+}
+
+func TestGetCommitFiles(t *testing.T) {
+	c := newTestClient(t, map[string]forgetest.Response{
+		repoPath + "/git/commits/" + shaC3: {File: "commit_files.json"},
+	})
+	got, err := c.GetCommitFiles(context.Background(), "token", testLink, shaC3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"README.md", "pkg/parser/parser.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,284 @@
+package github
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"rep_tracker/pkg/repolink"
+
+	"github.com/google/go-github/github"
+	"go.uber.org/zap"
+)
+
+const (
+	// installationTokenRefresh is how long before expiry an installation token is replaced.
+	installationTokenRefresh = 5 * time.Minute
+	// missingInstallationTTL is how long a repository without an installation is not looked up again.
+	missingInstallationTTL = time.Hour
+	appJWTLifetime         = 9 * time.Minute
+)
+
+// AppConfig configures authentication as a GitHub App on one GitHub host.
+type AppConfig struct {
+	// Host defaults to github.com.
+	Host  string
+	AppID int64
+	// PrivateKey is the PEM encoded private key generated for the app.
+	PrivateKey []byte
+}
+
+// InstallationStore keeps which app installation covers a repository, 0 meaning none.
+type InstallationStore interface {
+	GetRepoInstallation(ctx context.Context, link string) (int64, error)
+	SaveRepoInstallation(ctx context.Context, link string, installationID int64) error
+}
+
+type installationToken struct {
+	token     string
+	expiresAt time.Time
+}
+
+// tokenMint is an in-flight CreateInstallationToken call shared by concurrent callers.
+type tokenMint struct {
+	done  chan struct{}
+	token string
+	err   error
+}
+
+// appAuth mints and caches installation tokens of one GitHub App.
+type appAuth struct {
+	hc    *hostClient
+	store InstallationStore
+	// client is authenticated as the app itself and only used for installation endpoints.
+	client *github.Client
+
+	mx      sync.Mutex
+	tokens  map[int64]installationToken
+	minting map[int64]*tokenMint
+	clients map[int64]*github.Client
+	missing map[string]time.Time
+}
+
+func newAppAuth(hc *hostClient, cfg AppConfig, store InstallationStore) (*appAuth, error) {
+	key, err := parsePrivateKey(cfg.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("github app %v private key: %w", cfg.AppID, err)
+	}
+	client := github.NewClient(&http.Client{Transport: &appJWTTransport{
+		appID: cfg.AppID,
+		key:   key,
+		base:  &metricsTransport{base: http.DefaultTransport},
+	}})
+	client.BaseURL = hc.baseURL
+	return &appAuth{
+		hc:      hc,
+		store:   store,
+		client:  client,
+		tokens:  make(map[int64]installationToken),
+		minting: make(map[int64]*tokenMint),
+		clients: make(map[int64]*github.Client),
+		missing: make(map[string]time.Time),
+	}, nil
+}
+
+// installationFor returns the installation covering the repository, or 0 when the app is not installed on it.
+func (a *appAuth) installationFor(ctx context.Context, link repolink.Link) (int64, error) {
+	id, err := a.store.GetRepoInstallation(ctx, link.String())
+	if err != nil {
+		return 0, err
+	}
+	if id != 0 {
+		return id, nil
+	}
+	a.mx.Lock()
+	checkedAt, ok := a.missing[link.FullName()]
+	a.mx.Unlock()
+	if ok && time.Since(checkedAt) < missingInstallationTTL {
+		return 0, nil
+	}
+
+	installation, resp, err := a.client.Apps.FindRepositoryInstallation(ctx, link.Owner, link.Name)
+	if err != nil {
+		if resp != nil && resp.StatusCode == http.StatusNotFound {
+			a.mx.Lock()
+			a.missing[link.FullName()] = time.Now()
+			a.mx.Unlock()
+			return 0, nil
+		}
+		return 0, a.hc.convertError(err)
+	}
+	id = installation.GetID()
+	if err := a.store.SaveRepoInstallation(ctx, link.String(), id); err != nil {
+		return 0, err
+	}
+	return id, nil
+}
+
+// installationClient returns a client authenticating as the installation.
+func (a *appAuth) installationClient(id int64) *github.Client {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+	if client, ok := a.clients[id]; ok {
+		return client
+	}
+	client := github.NewClient(&http.Client{Transport: &installationTransport{
+		app:            a,
+		installationID: id,
+		base:           a.hc.transport(installationKey(id)),
+	}})
+	client.BaseURL = a.hc.baseURL
+	a.clients[id] = client
+	return client
+}
+
+// token returns a cached installation token, minting a new one when it is about to expire.
+// The token is minted outside the lock and only once per installation however many calls wait for it.
+func (a *appAuth) token(ctx context.Context, id int64) (string, error) {
+	a.mx.Lock()
+	if cached, ok := a.tokens[id]; ok && time.Until(cached.expiresAt) > installationTokenRefresh {
+		a.mx.Unlock()
+		return cached.token, nil
+	}
+	if mint, ok := a.minting[id]; ok {
+		a.mx.Unlock()
+		select {
+		case <-mint.done:
+			return mint.token, mint.err
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+	mint := &tokenMint{done: make(chan struct{})}
+	a.minting[id] = mint
+	a.mx.Unlock()
+
+	minted, _, err := a.client.Apps.CreateInstallationToken(ctx, id)
+	a.mx.Lock()
+	delete(a.minting, id)
+	if err == nil {
+		a.tokens[id] = installationToken{token: minted.GetToken(), expiresAt: minted.GetExpiresAt()}
+		mint.token = minted.GetToken()
+	}
+	a.mx.Unlock()
+	mint.err = err
+	close(mint.done)
+	return mint.token, mint.err
+}
+
+// forget drops the installation of the repository after GitHub stopped accepting it,
+// so the next call looks it up again and falls back to the user token meanwhile.
+func (a *appAuth) forget(ctx context.Context, link repolink.Link, id int64) {
+	a.mx.Lock()
+	delete(a.tokens, id)
+	a.mx.Unlock()
+	if err := a.store.SaveRepoInstallation(ctx, link.String(), 0); err != nil {
+		zap.L().Warn("clearing repository installation failed",
+			zap.String("link", link.String()),
+			zap.Int64("installationId", id),
+			zap.Error(err))
+	}
+}
+
+// installationTransport authenticates requests with the current installation token.
+type installationTransport struct {
+	app            *appAuth
+	installationID int64
+	base           http.RoundTripper
+}
+
+func (t *installationTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	token, err := t.app.token(req.Context(), t.installationID)
+	if err != nil {
+		return nil, &installationError{installationID: t.installationID, err: err}
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "token "+token)
+	return t.base.RoundTrip(req)
+}
+
+// installationError means no token could be minted for the installation, e.g. because the app was uninstalled.
+type installationError struct {
+	installationID int64
+	err            error
+}
+
+func (e *installationError) Error() string {
+	return fmt.Sprintf("mint token of installation %v: %v", e.installationID, e.err)
+}
+
+func (e *installationError) Unwrap() error {
+	return e.err
+}
+
+// appJWTTransport authenticates requests as the app with a short-lived RS256 JWT.
+type appJWTTransport struct {
+	appID int64
+	key   *rsa.PrivateKey
+	base  http.RoundTripper
+}
+
+func (t *appJWTTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	jwt, err := signAppJWT(t.appID, t.key, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+jwt)
+	return t.base.RoundTrip(req)
+}
+
+func signAppJWT(appID int64, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	// iat is backdated to tolerate clock drift between us and GitHub.
+	claims, err := json.Marshal(map[string]any{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(appJWTLifetime).Unix(),
+		"iss": strconv.FormatInt(appID, 10),
+	})
+	if err != nil {
+		return "", err
+	}
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// parsePrivateKey accepts the PKCS#1 keys GitHub generates as well as PKCS#8.
+func parsePrivateKey(raw []byte) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an RSA key")
+	}
+	return key, nil
+}
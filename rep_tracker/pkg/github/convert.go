@@ -0,0 +1,65 @@
+package github
+
+import (
+	"strings"
+	"time"
+
+	"rep_tracker/pkg/forge"
+
+	"github.com/google/go-github/github"
+)
+
+func convertCommits(commits []*github.RepositoryCommit) []*forge.Commit {
+	result := make([]*forge.Commit, 0, len(commits))
+	for _, commit := range commits {
+		if commit == nil || commit.GetSHA() == "" {
+			continue
+		}
+		result = append(result, convertCommit(commit))
+	}
+	return result
+}
+
+func convertCommit(commit *github.RepositoryCommit) *forge.Commit {
+	link := commit.GetHTMLURL()
+	if link == "" {
+		link = commit.GetURL()
+	}
+	if link == "" && commit.GetCommit() != nil {
+		link = commit.GetCommit().GetURL()
+	}
+	return &forge.Commit{
+		SHA:         commit.GetSHA(),
+		Message:     commit.GetCommit().GetMessage(),
+		AuthorLogin: commit.GetAuthor().GetLogin(),
+		HTMLURL:     normalizeGitHubLink(link),
+		CommittedAt: commitTime(commit),
+	}
+}
+
+func commitTime(commit *github.RepositoryCommit) time.Time {
+	if commit.Commit == nil {
+		return time.Time{}
+	}
+	if commit.Commit.Committer != nil {
+		if t := commit.Commit.Committer.GetDate(); !t.IsZero() {
+			return t
+		}
+	}
+	if commit.Commit.Author != nil {
+		return commit.Commit.Author.GetDate()
+	}
+	return time.Time{}
+}
+
+// normalizeGitHubLink turns API commit URLs into the web URLs users can open.
+func normalizeGitHubLink(raw string) string {
+	trimmed := strings.TrimSpace(raw)
+	if trimmed == "" {
+		return ""
+	}
+	normalized := strings.Replace(trimmed, "api.github.com/repos/", "github.com/", 1)
+	normalized = strings.Replace(normalized, "/api/v3/repos/", "/", 1)
+	normalized = strings.Replace(normalized, "/commits/", "/commit/", 1)
+	return normalized
+}
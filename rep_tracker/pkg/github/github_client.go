@@ -4,115 +4,416 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/url"
+	"net/http"
 	"rep_tracker/pkg/errs"
+	"rep_tracker/pkg/forge"
+	"rep_tracker/pkg/httpcache"
+	"rep_tracker/pkg/repolink"
 	"strings"
-	"sync"
-	"time"
 
 	"github.com/google/go-github/github"
-	"golang.org/x/oauth2"
+	"go.uber.org/zap"
 )
 
+// GithubClient talks to github.com and the configured GitHub Enterprise instances,
+// picking the instance from the repository link host.
 type GithubClient struct {
-	clientsMx sync.RWMutex
-	clients   map[string]*github.Client
+	hosts map[string]*hostClient
 }
 
-func NewGithubClient() *GithubClient {
-	return &GithubClient{clients: make(map[string]*github.Client)}
+// NewGithubClient returns a client for github.com and the given GitHub Enterprise hosts.
+// An empty APIBaseURL defaults to https://{Host}/api/v3/.
+func NewGithubClient(hosts ...forge.HostConfig) (*GithubClient, error) {
+	c := &GithubClient{hosts: make(map[string]*hostClient)}
+	for _, cfg := range append([]forge.HostConfig{{Host: repolink.DefaultHost}}, hosts...) {
+		hc, err := newHostClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		c.hosts[hc.host] = hc
+		if hc.enterprise {
+			repolink.RegisterHost(repolink.KindGitHub, hc.host, hc.baseURL.Hostname())
+		}
+	}
+	return c, nil
+}
+
+// EnableApp makes calls for repositories the GitHub App is installed on authenticate as the installation
+// instead of with the user token, except CheckRepo, which always checks the user's own access.
+// The store links repositories to installations.
+func (c *GithubClient) EnableApp(cfg AppConfig, store InstallationStore) error {
+	hc, err := c.hostClient(cfg.Host)
+	if err != nil {
+		return err
+	}
+	app, err := newAppAuth(hc, cfg, store)
+	if err != nil {
+		return err
+	}
+	hc.app = app
+	return nil
 }
 
+// EnableResponseCache makes GET requests conditional on the ETag or Last-Modified of the previous
+// response of the same credential; GitHub does not count 304 answers against the rate limit.
+// It must be called before the first request.
+func (c *GithubClient) EnableResponseCache(store httpcache.Store) {
+	for _, hc := range c.hosts {
+		hc.cache = store
+	}
+}
+
+// RateLimitStatus returns the budget of the credentials used for the repository:
+// its app installation when there is one, the user token otherwise.
+func (c *GithubClient) RateLimitStatus(ctx context.Context, token string, link string) (forge.RateLimitStatus, bool) {
+	hc, parsed, err := c.hostForLink(link)
+	if err != nil {
+		return forge.RateLimitStatus{}, false
+	}
+	if hc.app != nil {
+		if id, err := hc.app.installationFor(ctx, parsed); err == nil && id != 0 {
+			return hc.limiter.status(installationKey(id))
+		}
+	}
+	return hc.limiter.status(tokenKey(token))
+}
+
+// Hosts returns the hosts served by the client.
+func (c *GithubClient) Hosts() []string {
+	hosts := make([]string, 0, len(c.hosts))
+	for host := range c.hosts {
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+func (c *GithubClient) ParseLink(raw string) (repolink.Link, error) {
+	link, err := repolink.Parse(raw)
+	if err != nil {
+		return repolink.Link{}, err
+	}
+	if _, ok := c.hosts[link.Host]; !ok || link.Kind != repolink.KindGitHub {
+		return repolink.Link{}, &errs.LinkError{Link: raw, Err: errs.ErrUnsupportedHost}
+	}
+	return link, nil
+}
+
+// CheckRepo always uses the user token: it is the access check for subscribing to and polling the
+// repository, so an app installation must not make a repository visible to users who cannot see it.
 func (c *GithubClient) CheckRepo(ctx context.Context, token string, link string) (bool, error) {
-	currClient := c.getOrCreateClient(ctx, token)
-	owner, repoName, err := c.getOwnerRepo(link)
+	if token == "" {
+		return false, errs.ErrTokenMissing
+	}
+	hc, parsed, err := c.hostForLink(link)
 	if err != nil {
 		return false, err
 	}
-	_, resp, err := currClient.Repositories.Get(ctx, owner, repoName)
+	_, resp, err := hc.getOrCreateClient(ctx, token).Repositories.Get(ctx, parsed.Owner, parsed.Name)
 	if err != nil {
-		if isInvalidToken(err) {
-			return false, errs.ErrInvalidToken
-		}
 		if resp != nil && resp.StatusCode == 404 {
 			return false, nil
 		}
-		return false, err
+		return false, hc.convertError(err)
 	}
 	return true, nil
 }
 
-func (c *GithubClient) GetCommitsSince(ctx context.Context, token string, link string, lastTime time.Time) ([]*github.RepositoryCommit, error) {
-	currClient := c.getOrCreateClient(ctx, token)
-	owner, repoName, err := c.getOwnerRepo(link)
+// ValidateToken checks the token against the GitHub host (github.com when empty)
+// and returns the login of its owner.
+func (c *GithubClient) ValidateToken(ctx context.Context, host string, token string) (string, error) {
+	hc, err := c.hostClient(host)
 	if err != nil {
-		return nil, err
+		return "", err
 	}
-	commits, _, err := currClient.Repositories.ListCommits(ctx, owner, repoName, &github.CommitsListOptions{Since: lastTime})
+	currClient := hc.getOrCreateClient(ctx, token)
+	user, _, err := currClient.Users.Get(ctx, "")
 	if err != nil {
-		if isInvalidToken(err) {
-			return nil, errs.ErrInvalidToken
-		}
+		return "", hc.convertError(err)
 	}
-	return commits, err
+	return user.GetLogin(), nil
 }
 
-func (c *GithubClient) getOrCreateClient(ctx context.Context, token string) *github.Client {
-	c.clientsMx.RLock()
-	if client, ok := c.clients[token]; ok {
-		c.clientsMx.RUnlock()
-		return client
+// ResolveBranches returns the branches of the repository matching the given names or glob patterns.
+// When no patterns are given only the default branch is returned.
+func (c *GithubClient) ResolveBranches(ctx context.Context, token string, link string, patterns []string) ([]string, error) {
+	currClient, owner, repoName, err := c.clientForLink(ctx, token, link)
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) == 0 {
+		repo, _, err := currClient.Repositories.Get(ctx, owner, repoName)
+		if err != nil {
+			return nil, currClient.convertError(ctx, err)
+		}
+		return []string{repo.GetDefaultBranch()}, nil
+	}
+
+	matched := make([]string, 0)
+	opts := &github.ListOptions{PerPage: 100}
+	for {
+		branches, resp, err := currClient.Repositories.ListBranches(ctx, owner, repoName, opts)
+		if err != nil {
+			return nil, currClient.convertError(ctx, err)
+		}
+		for _, branch := range branches {
+			if forge.MatchBranch(patterns, branch.GetName()) {
+				matched = append(matched, branch.GetName())
+			}
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		opts.Page = resp.NextPage
 	}
-	c.clientsMx.RUnlock()
-	newClient := c.newClientWithToken(ctx, token)
-	c.clientsMx.Lock()
-	c.clients[token] = newClient
-	c.clientsMx.Unlock()
-	return newClient
+	return matched, nil
 }
 
-func (c *GithubClient) newClientWithToken(ctx context.Context, token string) *github.Client {
-	ts := oauth2.StaticTokenSource(
-		&oauth2.Token{AccessToken: token},
-	)
-	tc := oauth2.NewClient(ctx, ts)
-	return github.NewClient(tc)
+// ListCommitsSince compares the cursor SHA with the branch, reporting a rewritten history when the SHA is
+// no longer an ancestor of the branch. When GitHub does not know the SHA, e.g. because it was garbage
+// collected after a force push, the branch is listed since the cursor time.
+func (c *GithubClient) ListCommitsSince(ctx context.Context, token string, link string, branch string, cursor forge.Cursor, limit int) (*forge.CommitList, error) {
+	currClient, owner, repoName, err := c.clientForLink(ctx, token, link)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.SHA != "" {
+		comparison, resp, err := currClient.Repositories.CompareCommits(ctx, owner, repoName, cursor.SHA, branch)
+		if err == nil {
+			result, err := currClient.compared(ctx, owner, repoName, branch, cursor.SHA, comparison, limit)
+			if err != nil {
+				return nil, err
+			}
+			// The old head is not an ancestor of the branch anymore, so its history was rewritten.
+			if status := comparison.GetStatus(); status == "diverged" || status == "behind" {
+				reverse, _, err := currClient.Repositories.CompareCommits(ctx, owner, repoName, branch, cursor.SHA)
+				if err != nil {
+					return nil, currClient.convertError(ctx, err)
+				}
+				dropped, err := currClient.dropped(ctx, owner, repoName, cursor.SHA, reverse)
+				if err != nil {
+					return nil, err
+				}
+				result.Rewritten = true
+				result.Dropped = dropped
+				result.Head = reverse.GetBaseCommit().GetSHA()
+			}
+			return result, nil
+		}
+		if resp == nil || resp.StatusCode != http.StatusNotFound {
+			return nil, currClient.convertError(ctx, err)
+		}
+		zap.L().Debug("cursor commit not found, listing commits by date",
+			zap.String("link", link),
+			zap.String("branch", branch),
+			zap.String("sha", cursor.SHA))
+	}
+	return currClient.listCommits(ctx, owner, repoName, &github.CommitsListOptions{SHA: branch, Since: cursor.Since}, cursor, limit)
 }
 
-func (c *GithubClient) getOwnerRepo(link string) (string, string, error) {
-	u, err := url.Parse(link)
+// compared returns the newest limit of the commits the branch is ahead of base by.
+func (rc *repoClient) compared(ctx context.Context, owner string, repoName string, branch string, base string, comparison *github.CommitsComparison, limit int) (*forge.CommitList, error) {
+	commits := comparedCommits(comparison)
+	aheadBy := comparison.GetAheadBy()
+	if len(commits) >= aheadBy {
+		return forge.NewestFirst(commits, aheadBy, limit), nil
+	}
+	// Comparisons hold the oldest 250 commits only, so the newest are listed back to the base.
+	result, err := rc.listCommits(ctx, owner, repoName, &github.CommitsListOptions{SHA: branch}, forge.Cursor{SHA: base}, limit)
 	if err != nil {
-		return "", "", err
+		return nil, err
 	}
-	path := strings.Trim(u.Path, "/")
-	parts := strings.Split(path, "/")
-	if len(parts) < 2 {
-		return "", "", fmt.Errorf("Invalid repository path: %v", link)
+	result.Truncated = aheadBy > limit
+	return result, nil
+}
+
+// dropped returns all commits the old head is ahead of the branch by, newest first.
+func (rc *repoClient) dropped(ctx context.Context, owner string, repoName string, oldHead string, reverse *github.CommitsComparison) ([]*forge.Commit, error) {
+	commits := comparedCommits(reverse)
+	aheadBy := reverse.GetAheadBy()
+	if len(commits) >= aheadBy {
+		return forge.NewestFirst(commits, aheadBy, len(commits)).Commits, nil
 	}
+	mergeBase := forge.Cursor{SHA: reverse.GetMergeBaseCommit().GetSHA()}
+	listed, err := rc.listCommits(ctx, owner, repoName, &github.CommitsListOptions{SHA: oldHead}, mergeBase, aheadBy)
+	if err != nil {
+		return nil, err
+	}
+	return listed.Commits, nil
+}
 
-	owner := parts[0]
-	repo := strings.TrimSuffix(parts[1], ".git")
-	if owner == "" {
-		return "", "", fmt.Errorf("Empty owner: %v", link)
+func comparedCommits(comparison *github.CommitsComparison) []*forge.Commit {
+	commits := make([]*forge.Commit, 0, len(comparison.Commits))
+	for i := range comparison.Commits {
+		if comparison.Commits[i].GetSHA() != "" {
+			commits = append(commits, convertCommit(&comparison.Commits[i]))
+		}
 	}
-	if repo == "" {
-		return "", "", fmt.Errorf("Empty gorm: %v", link)
+	return commits
+}
+
+// listCommits pages through the commits newest first until the cursor SHA, the limit or the last page,
+// skipping commits not newer than the cursor time.
+func (rc *repoClient) listCommits(ctx context.Context, owner string, repoName string, opts *github.CommitsListOptions, cursor forge.Cursor, limit int) (*forge.CommitList, error) {
+	result := &forge.CommitList{Commits: make([]*forge.Commit, 0)}
+	opts.PerPage = 100
+	for {
+		commits, resp, err := rc.Repositories.ListCommits(ctx, owner, repoName, opts)
+		if err != nil {
+			return nil, rc.convertError(ctx, err)
+		}
+		for _, commit := range convertCommits(commits) {
+			if cursor.SHA != "" && commit.SHA == cursor.SHA {
+				return result, nil
+			}
+			if !cursor.Unseen(commit) {
+				continue
+			}
+			if len(result.Commits) == limit {
+				result.Truncated = true
+				return result, nil
+			}
+			result.Commits = append(result.Commits, commit)
+		}
+		if resp.NextPage == 0 {
+			return result, nil
+		}
+		opts.Page = resp.NextPage
 	}
-	return owner, repo, nil
 }
 
-func isInvalidToken(err error) bool {
-	var ghErr *github.ErrorResponse
-	if errors.As(err, &ghErr) && ghErr.Response != nil {
-		if ghErr.Response.StatusCode == 401 {
-			return true
+// ListRepositories lists repositories of a user or organization on the GitHub host (github.com when empty),
+// or every repository visible to the token when owner is empty. Visibility is one of all, public or private.
+func (c *GithubClient) ListRepositories(ctx context.Context, host string, token string, owner string, visibility string) ([]*forge.Repository, error) {
+	hc, err := c.hostClient(host)
+	if err != nil {
+		return nil, err
+	}
+	currClient := hc.getOrCreateClient(ctx, token)
+	listPage := func(page int) ([]*github.Repository, *github.Response, error) {
+		listOpts := github.ListOptions{PerPage: 100, Page: page}
+		if owner == "" {
+			return currClient.Repositories.List(ctx, "", &github.RepositoryListOptions{Visibility: visibility, ListOptions: listOpts})
 		}
-		if ghErr.Response.StatusCode == 403 {
-			msg := strings.ToLower(ghErr.Message)
-			if strings.Contains(msg, "bad credentials") || strings.Contains(msg, "invalid token") {
-				return true
+		return currClient.Repositories.List(ctx, owner, &github.RepositoryListOptions{Type: "owner", ListOptions: listOpts})
+	}
+	if owner != "" {
+		user, resp, err := currClient.Users.Get(ctx, owner)
+		if err != nil {
+			if resp != nil && resp.StatusCode == 404 {
+				return nil, errs.ErrRepoNotFound
 			}
+			return nil, hc.convertError(err)
+		}
+		if user.GetType() == "Organization" {
+			listPage = func(page int) ([]*github.Repository, *github.Response, error) {
+				return currClient.Repositories.ListByOrg(ctx, owner, &github.RepositoryListByOrgOptions{
+					Type:        visibility,
+					ListOptions: github.ListOptions{PerPage: 100, Page: page},
+				})
+			}
+		}
+	}
+
+	result := make([]*forge.Repository, 0)
+	page := 0
+	for {
+		repos, resp, err := listPage(page)
+		if err != nil {
+			return nil, hc.convertError(err)
+		}
+		for _, repo := range repos {
+			result = append(result, &forge.Repository{
+				HTMLURL:  repo.GetHTMLURL(),
+				Name:     repo.GetName(),
+				Private:  repo.GetPrivate(),
+				Archived: repo.GetArchived(),
+			})
+		}
+		if resp.NextPage == 0 {
+			break
+		}
+		page = resp.NextPage
+	}
+	return result, nil
+}
+
+// GetCommitFiles returns the paths of the files touched by the commit.
+func (c *GithubClient) GetCommitFiles(ctx context.Context, token string, link string, sha string) ([]string, error) {
+	currClient, owner, repoName, err := c.clientForLink(ctx, token, link)
+	if err != nil {
+		return nil, err
+	}
+	commit, _, err := currClient.Repositories.GetCommit(ctx, owner, repoName, sha)
+	if err != nil {
+		return nil, currClient.convertError(ctx, err)
+	}
+	paths := make([]string, 0, len(commit.Files))
+	for _, file := range commit.Files {
+		paths = append(paths, file.GetFilename())
+	}
+	return paths, nil
+}
+
+func (c *GithubClient) hostClient(host string) (*hostClient, error) {
+	if host == "" {
+		host = repolink.DefaultHost
+	}
+	hc, ok := c.hosts[strings.ToLower(host)]
+	if !ok {
+		return nil, &errs.LinkError{Link: host, Err: errs.ErrUnsupportedHost}
+	}
+	return hc, nil
+}
+
+func (c *GithubClient) hostForLink(link string) (*hostClient, repolink.Link, error) {
+	parsed, err := c.ParseLink(link)
+	if err != nil {
+		return nil, repolink.Link{}, err
+	}
+	return c.hosts[parsed.Host], parsed, nil
+}
+
+// repoClient is the client used for one repository, authenticated either as
+// the app installation covering it or with the user token.
+type repoClient struct {
+	*github.Client
+	hc             *hostClient
+	link           repolink.Link
+	installationID int64
+}
+
+// clientForLink prefers the app installation of the repository over the user token.
+// Callers must have checked the user's access with CheckRepo first.
+func (c *GithubClient) clientForLink(ctx context.Context, token string, link string) (*repoClient, string, string, error) {
+	hc, parsed, err := c.hostForLink(link)
+	if err != nil {
+		return nil, "", "", err
+	}
+	if hc.app != nil {
+		id, err := hc.app.installationFor(ctx, parsed)
+		if err != nil {
+			zap.L().Warn("github app installation lookup failed, using user token",
+				zap.String("link", parsed.String()),
+				zap.Error(err))
+		} else if id != 0 {
+			client := &repoClient{Client: hc.app.installationClient(id), hc: hc, link: parsed, installationID: id}
+			return client, parsed.Owner, parsed.Name, nil
 		}
 	}
-	return false
+	client := &repoClient{Client: hc.getOrCreateClient(ctx, token), hc: hc, link: parsed}
+	return client, parsed.Owner, parsed.Name, nil
+}
+
+// convertError works like hostClient.convertError, except that a rejected installation
+// must not be reported as an invalid user token, which would disable the user's tracking.
+func (rc *repoClient) convertError(ctx context.Context, err error) error {
+	if rc.installationID == 0 {
+		return rc.hc.convertError(err)
+	}
+	var instErr *installationError
+	if errors.As(err, &instErr) || rc.hc.isInvalidToken(err) {
+		rc.hc.app.forget(ctx, rc.link, rc.installationID)
+		return fmt.Errorf("%w: github app installation %v rejected: %v", errs.ErrGithubUnavailable, rc.installationID, err)
+	}
+	return rc.hc.convertError(err)
 }
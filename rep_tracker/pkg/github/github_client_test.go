@@ -0,0 +1,250 @@
+package github
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"rep_tracker/pkg/forge"
+	"rep_tracker/pkg/forge/forgetest"
+)
+
+const (
+	testLink = "https://ghe.test/Owner/Repo/tree/main"
+	repoPath = "/api/v3/repos/owner/repo"
+
+	shaX9 = "fff9000000000000000000000000000000000009"
+	shaA0 = "aaa0000000000000000000000000000000000000"
+	shaA1 = "aaa1000000000000000000000000000000000001"
+	shaB2 = "bbb2000000000000000000000000000000000002"
+	shaC3 = "ccc3000000000000000000000000000000000003"
+	shaD4 = "ddd4000000000000000000000000000000000004"
+	shaE5 = "eee5000000000000000000000000000000000005"
+)
+
+var cursorTime = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+func newTestClient(t *testing.T, routes map[string]forgetest.Response) *GithubClient {
+	t.Helper()
+	srv := forgetest.NewServer(t, routes)
+	c, err := NewGithubClient(forge.HostConfig{Host: "ghe.test", APIBaseURL: srv.URL + "/api/v3/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c
+}
+
+func shas(commits []*forge.Commit) []string {
+	result := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		result = append(result, commit.SHA)
+	}
+	return result
+}
+
+func compareRoute(base string, head string) string {
+	return repoPath + "/compare/" + base + "..." + head
+}
+
+func commitsRoute(query url.Values) string {
+	query.Set("per_page", "100")
+	return forgetest.Route(repoPath+"/commits", query)
+}
+
+func TestResolveBranches(t *testing.T) {
+	c := newTestClient(t, map[string]forgetest.Response{
+		repoPath: {File: "repo.json"},
+		forgetest.Route(repoPath+"/branches", url.Values{"per_page": {"100"}}): {
+			File:   "branches_page1.json",
+			Header: map[string]string{"Link": `<` + repoPath + `/branches?page=2&per_page=100>; rel="next", <` + repoPath + `/branches?page=2&per_page=100>; rel="last"`},
+		},
+		forgetest.Route(repoPath+"/branches", url.Values{"per_page": {"100"}, "page": {"2"}}): {File: "branches_page2.json"},
+	})
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{name: "default branch", want: []string{"main"}},
+		{name: "patterns across pages", patterns: []string{"release/*"}, want: []string{"release/1.0", "release/2.0"}},
+		{name: "exact name", patterns: []string{"feature/x"}, want: []string{"feature/x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.ResolveBranches(context.Background(), "token", testLink, tt.patterns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("branches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListCommitsSince(t *testing.T) {
+	tests := []struct {
+		name          string
+		cursor        forge.Cursor
+		limit         int
+		routes        map[string]forgetest.Response
+		wantCommits   []string
+		wantTruncated bool
+		wantDropped   []string
+		wantHead      string
+	}{
+		{
+			name:   "compared commits newest first",
+			cursor: forge.Cursor{SHA: shaA1, Since: cursorTime},
+			limit:  10,
+			routes: map[string]forgetest.Response{
+				compareRoute(shaA1, "main"): {File: "compare_ahead.json"},
+			},
+			wantCommits: []string{shaC3, shaB2},
+		},
+		{
+			name:   "compared commits beyond the limit",
+			cursor: forge.Cursor{SHA: shaA1, Since: cursorTime},
+			limit:  1,
+			routes: map[string]forgetest.Response{
+				compareRoute(shaA1, "main"): {File: "compare_ahead.json"},
+			},
+			wantCommits:   []string{shaC3},
+			wantTruncated: true,
+		},
+		{
+			// Comparisons hold the oldest commits only, so the newest are listed back to the cursor.
+			name:   "incomplete comparison",
+			cursor: forge.Cursor{SHA: shaA1, Since: cursorTime},
+			limit:  10,
+			routes: map[string]forgetest.Response{
+				compareRoute(shaA1, "main"):               {File: "compare_partial.json"},
+				commitsRoute(url.Values{"sha": {"main"}}): {File: "commits_main.json"},
+			},
+			wantCommits: []string{shaD4, shaC3, shaB2},
+		},
+		{
+			name:   "incomplete comparison beyond the limit",
+			cursor: forge.Cursor{SHA: shaA1, Since: cursorTime},
+			limit:  2,
+			routes: map[string]forgetest.Response{
+				compareRoute(shaA1, "main"):               {File: "compare_partial.json"},
+				commitsRoute(url.Values{"sha": {"main"}}): {File: "commits_main.json"},
+			},
+			wantCommits:   []string{shaD4, shaC3},
+			wantTruncated: true,
+		},
+		{
+			name:   "diverged branch",
+			cursor: forge.Cursor{SHA: shaA1, Since: cursorTime},
+			limit:  1,
+			routes: map[string]forgetest.Response{
+				compareRoute(shaA1, "main"): {File: "compare_diverged.json"},
+				compareRoute("main", shaA1): {File: "compare_reverse.json"},
+			},
+			wantCommits: []string{shaE5},
+			// All dropped commits are returned regardless of the limit.
+			wantDropped: []string{shaA1, shaA0, shaX9},
+			wantHead:    shaE5,
+		},
+		{
+			name:   "diverged branch with dropped commits beyond the comparison",
+			cursor: forge.Cursor{SHA: shaA1, Since: cursorTime},
+			limit:  1,
+			routes: map[string]forgetest.Response{
+				compareRoute(shaA1, "main"):              {File: "compare_diverged.json"},
+				compareRoute("main", shaA1):              {File: "compare_reverse_partial.json"},
+				commitsRoute(url.Values{"sha": {shaA1}}): {File: "commits_old_head.json"},
+			},
+			wantCommits: []string{shaE5},
+			wantDropped: []string{shaA1, shaA0, shaX9},
+			wantHead:    shaE5,
+		},
+		{
+			name:   "listed by date when the cursor commit is unknown",
+			cursor: forge.Cursor{SHA: "gone", Since: cursorTime},
+			limit:  2,
+			routes: map[string]forgetest.Response{
+				compareRoute("gone", "main"): {File: "not_found.json", Status: http.StatusNotFound},
+				commitsRoute(url.Values{"sha": {"main"}, "since": {"2024-05-01T10:00:00Z"}}): {File: "commits_since.json"},
+			},
+			// The cursor commit is listed again since "since" is inclusive, it must not count as truncation.
+			wantCommits: []string{shaC3, shaB2},
+		},
+		{
+			name:   "listed by date beyond the limit",
+			cursor: forge.Cursor{Since: cursorTime},
+			limit:  1,
+			routes: map[string]forgetest.Response{
+				commitsRoute(url.Values{"sha": {"main"}, "since": {"2024-05-01T10:00:00Z"}}): {File: "commits_since.json"},
+			},
+			wantCommits:   []string{shaC3},
+			wantTruncated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, tt.routes)
+			got, err := c.ListCommitsSince(context.Background(), "token", testLink, "main", tt.cursor, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(shas(got.Commits), tt.wantCommits) {
+				t.Errorf("commits = %v, want %v", shas(got.Commits), tt.wantCommits)
+			}
+			if got.Truncated != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", got.Truncated, tt.wantTruncated)
+			}
+			if got.Rewritten != (tt.wantDropped != nil) {
+				t.Errorf("rewritten = %v, want %v", got.Rewritten, tt.wantDropped != nil)
+			}
+			if !slices.Equal(shas(got.Dropped), tt.wantDropped) {
+				t.Errorf("dropped = %v, want %v", shas(got.Dropped), tt.wantDropped)
+			}
+			if got.Head != tt.wantHead {
+				t.Errorf("head = %q, want %q", got.Head, tt.wantHead)
+			}
+		})
+	}
+}
+
+func TestListCommitsSinceConvertsCommits(t *testing.T) {
+	c := newTestClient(t, map[string]forgetest.Response{
+		compareRoute(shaA1, "main"): {File: "compare_ahead.json"},
+	})
+	got, err := c.ListCommitsSince(context.Background(), "token", testLink, "main", forge.Cursor{SHA: shaA1}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newest := got.Commits[0]
+	if newest.AuthorLogin != "ann" {
+		t.Errorf("author = %q, want %q", newest.AuthorLogin, "ann")
+	}
+	if want := "Add parser\n\nSplits the input.\n"; newest.Message != want {
+		t.Errorf("message = %q, want %q", newest.Message, want)
+	}
+	if want := "https://ghe.test/owner/repo/commit/" + shaC3; newest.HTMLURL != want {
+		t.Errorf("html url = %q, want %q", newest.HTMLURL, want)
+	}
+	if want := time.Date(2024, 5, 1, 10, 10, 0, 0, time.UTC); !newest.CommittedAt.Equal(want) {
+		t.Errorf("committed at = %v, want %v", newest.CommittedAt, want)
+	}
+}
+
+func TestGetCommitFiles(t *testing.T) {
+	c := newTestClient(t, map[string]forgetest.Response{
+		repoPath + "/commits/" + shaC3: {File: "commit_files.json"},
+	})
+	got, err := c.GetCommitFiles(context.Background(), "token", testLink, shaC3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"README.md", "pkg/parser/parser.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,167 @@
+package github
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"rep_tracker/pkg/errs"
+	"rep_tracker/pkg/forge"
+	"rep_tracker/pkg/httpcache"
+	"rep_tracker/pkg/repolink"
+
+	"github.com/google/go-github/github"
+	"golang.org/x/oauth2"
+)
+
+// hostClient keeps the API clients of one GitHub instance, one per token.
+type hostClient struct {
+	host       string
+	baseURL    *url.URL
+	enterprise bool
+
+	clientsMx sync.RWMutex
+	// clients are keyed by the token digest so tokens are not kept as map keys.
+	clients map[budgetKey]*github.Client
+	limiter *rateLimiter
+	// cache, when set, makes GET requests conditional on the previous response.
+	cache httpcache.Store
+
+	// app is set when a GitHub App is configured for the host.
+	app *appAuth
+}
+
+func newHostClient(cfg forge.HostConfig) (*hostClient, error) {
+	host := strings.ToLower(cfg.Host)
+	enterprise := host != repolink.DefaultHost
+	apiBaseURL := cfg.APIBaseURL
+	if apiBaseURL == "" {
+		if enterprise {
+			apiBaseURL = "https://" + host + "/api/v3/"
+		} else {
+			apiBaseURL = "https://api.github.com/"
+		}
+	}
+	if !strings.HasSuffix(apiBaseURL, "/") {
+		apiBaseURL += "/"
+	}
+	baseURL, err := url.Parse(apiBaseURL)
+	if err != nil || baseURL.Host == "" {
+		return nil, fmt.Errorf("invalid api base url %q for github host %v", apiBaseURL, host)
+	}
+	return &hostClient{
+		host:       host,
+		baseURL:    baseURL,
+		enterprise: enterprise,
+		clients:    make(map[budgetKey]*github.Client),
+		limiter:    newRateLimiter(),
+	}, nil
+}
+
+func (h *hostClient) getOrCreateClient(ctx context.Context, token string) *github.Client {
+	key := tokenKey(token)
+	h.clientsMx.RLock()
+	if client, ok := h.clients[key]; ok {
+		h.clientsMx.RUnlock()
+		return client
+	}
+	h.clientsMx.RUnlock()
+	newClient := h.newClientWithToken(ctx, token)
+	h.clientsMx.Lock()
+	h.clients[key] = newClient
+	h.clientsMx.Unlock()
+	return newClient
+}
+
+func (h *hostClient) newClientWithToken(ctx context.Context, token string) *github.Client {
+	ts := oauth2.StaticTokenSource(
+		&oauth2.Token{AccessToken: token},
+	)
+	tc := &http.Client{Transport: &oauth2.Transport{
+		Source: ts,
+		Base:   h.transport(tokenKey(token)),
+	}}
+	client := github.NewClient(tc)
+	client.BaseURL = h.baseURL
+	return client
+}
+
+// transport is the request chain shared by the clients of one credential:
+// rate limiting, then the conditional request cache, then metrics.
+func (h *hostClient) transport(key budgetKey) http.RoundTripper {
+	var base http.RoundTripper = &metricsTransport{base: http.DefaultTransport}
+	if h.cache != nil {
+		base = &httpcache.Transport{Store: h.cache, Key: hex.EncodeToString(key[:]), Base: base}
+	}
+	return &rateLimitTransport{limiter: h.limiter, key: key, base: base}
+}
+
+// isInvalidToken reports whether this instance rejected the token itself rather than the request.
+// Errors of other instances never count, so a bad token on one host does not disable another.
+func (h *hostClient) isInvalidToken(err error) bool {
+	var ghErr *github.ErrorResponse
+	if !errors.As(err, &ghErr) || ghErr.Response == nil {
+		return false
+	}
+	if req := ghErr.Response.Request; req != nil && req.URL != nil && !strings.EqualFold(req.URL.Host, h.baseURL.Host) {
+		return false
+	}
+	switch ghErr.Response.StatusCode {
+	case 401:
+		return true
+	case 403:
+		msg := strings.ToLower(ghErr.Message)
+		if strings.Contains(msg, "bad credentials") || strings.Contains(msg, "invalid token") {
+			return true
+		}
+		// Enterprise instances in private mode answer unauthenticated calls this way.
+		return h.enterprise && strings.Contains(msg, "must authenticate")
+	}
+	return false
+}
+
+// convertError maps go-github errors to the errs taxonomy; unknown errors are returned unchanged.
+func (h *hostClient) convertError(err error) error {
+	if err == nil {
+		return nil
+	}
+	// Requests held back by the rate limiter never reached GitHub.
+	var limited *errs.RateLimitError
+	if errors.As(err, &limited) {
+		return limited
+	}
+	if h.isInvalidToken(err) {
+		return errs.ErrInvalidToken
+	}
+	var rateErr *github.RateLimitError
+	if errors.As(err, &rateErr) {
+		resetAt := rateErr.Rate.Reset.Time
+		return &errs.RateLimitError{ResetAt: resetAt, RetryAfter: time.Until(resetAt), Err: err}
+	}
+	var abuseErr *github.AbuseRateLimitError
+	if errors.As(err, &abuseErr) {
+		retryAfter := time.Minute
+		if abuseErr.RetryAfter != nil {
+			retryAfter = *abuseErr.RetryAfter
+		}
+		return &errs.RateLimitError{ResetAt: time.Now().Add(retryAfter), RetryAfter: retryAfter, Err: err}
+	}
+	var ghErr *github.ErrorResponse
+	if errors.As(err, &ghErr) {
+		if ghErr.Response != nil && ghErr.Response.StatusCode >= 500 {
+			return fmt.Errorf("%w: %v", errs.ErrGithubUnavailable, err)
+		}
+		return err
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %v", errs.ErrGithubUnavailable, err)
+	}
+	return err
+}
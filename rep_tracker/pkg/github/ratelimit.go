@@ -0,0 +1,165 @@
+package github
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"rep_tracker/pkg/errs"
+	"rep_tracker/pkg/forge"
+)
+
+const (
+	// rateLimitMaxWait is the longest a request waits for its token budget instead of failing.
+	rateLimitMaxWait = 30 * time.Second
+	// Secondary limits without Retry-After are backed off exponentially between these bounds.
+	secondaryBackoffMin = time.Minute
+	secondaryBackoffMax = 15 * time.Minute
+)
+
+type budgetKey = [sha256.Size]byte
+
+func tokenKey(token string) budgetKey {
+	return sha256.Sum256([]byte(token))
+}
+
+func installationKey(id int64) budgetKey {
+	return sha256.Sum256([]byte("installation:" + strconv.FormatInt(id, 10)))
+}
+
+type rateBudget struct {
+	status forge.RateLimitStatus
+	// backoff is the last secondary limit backoff, doubled while limits keep coming.
+	backoff time.Duration
+}
+
+// rateLimiter tracks the request budget of every token used against one GitHub host,
+// as reported by the X-RateLimit-* headers and secondary limit responses.
+type rateLimiter struct {
+	mx      sync.Mutex
+	budgets map[budgetKey]*rateBudget
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{budgets: make(map[budgetKey]*rateBudget)}
+}
+
+func (l *rateLimiter) status(key budgetKey) (forge.RateLimitStatus, bool) {
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	budget, ok := l.budgets[key]
+	if !ok {
+		return forge.RateLimitStatus{}, false
+	}
+	return budget.status, true
+}
+
+// wait blocks until the budget allows a request. Budgets available later than rateLimitMaxWait
+// fail right away with *errs.RateLimitError so the caller can move on to other work.
+func (l *rateLimiter) wait(ctx context.Context, key budgetKey) error {
+	status, ok := l.status(key)
+	if !ok {
+		return nil
+	}
+	availableAt := status.AvailableAt(time.Now())
+	if availableAt.IsZero() {
+		return nil
+	}
+	delay := time.Until(availableAt)
+	if delay > rateLimitMaxWait {
+		return &errs.RateLimitError{ResetAt: availableAt, RetryAfter: delay, Err: errs.ErrRateLimited}
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (l *rateLimiter) update(key budgetKey, resp *http.Response) {
+	now := time.Now()
+	l.mx.Lock()
+	defer l.mx.Unlock()
+	budget, ok := l.budgets[key]
+	if !ok {
+		budget = &rateBudget{}
+		l.budgets[key] = budget
+	}
+	if limit, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Limit")); err == nil {
+		budget.status.Limit = limit
+	}
+	if remaining, err := strconv.Atoi(resp.Header.Get("X-RateLimit-Remaining")); err == nil {
+		budget.status.Remaining = remaining
+	}
+	if reset, err := strconv.ParseInt(resp.Header.Get("X-RateLimit-Reset"), 10, 64); err == nil {
+		budget.status.ResetAt = time.Unix(reset, 0)
+	}
+
+	if !isSecondaryLimit(resp) {
+		if resp.StatusCode < 400 {
+			budget.backoff = 0
+		}
+		return
+	}
+	var backoff time.Duration
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+		backoff = time.Duration(seconds) * time.Second
+	} else {
+		backoff = min(max(2*budget.backoff, secondaryBackoffMin), secondaryBackoffMax)
+		budget.backoff = backoff
+	}
+	budget.status.BlockedUntil = now.Add(backoff)
+}
+
+// isSecondaryLimit reports whether the response is a secondary (abuse) rate limit. GitHub also
+// answers 403 for missing permissions, so without Retry-After the message decides.
+func isSecondaryLimit(resp *http.Response) bool {
+	if resp.StatusCode != http.StatusForbidden && resp.StatusCode != http.StatusTooManyRequests {
+		return false
+	}
+	if resp.Header.Get("X-RateLimit-Remaining") == "0" {
+		// Primary limit, covered by the reset time.
+		return false
+	}
+	if resp.Header.Get("Retry-After") != "" || resp.StatusCode == http.StatusTooManyRequests {
+		return true
+	}
+	raw, err := io.ReadAll(io.LimitReader(resp.Body, 4096))
+	resp.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(raw), resp.Body), resp.Body}
+	if err != nil {
+		return false
+	}
+	msg := strings.ToLower(string(raw))
+	return strings.Contains(msg, "secondary rate limit") || strings.Contains(msg, "abuse")
+}
+
+// rateLimitTransport keeps the budget of one token up to date and holds requests back while it is exhausted.
+type rateLimitTransport struct {
+	limiter *rateLimiter
+	key     budgetKey
+	base    http.RoundTripper
+}
+
+func (t *rateLimitTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.wait(req.Context(), t.key); err != nil {
+		return nil, err
+	}
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	t.limiter.update(t.key, resp)
+	return resp, nil
+}
@@ -0,0 +1,56 @@
+package github
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"rep_tracker/pkg/metrics"
+)
+
+// metricsTransport records GitHub request counts and latency by endpoint and status.
+type metricsTransport struct {
+	base http.RoundTripper
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.base.RoundTrip(req)
+	endpoint := endpointLabel(req.URL.Path)
+	statusLabel := "error"
+	if err == nil {
+		statusLabel = strconv.Itoa(resp.StatusCode)
+	}
+	metrics.GithubRequests.WithLabelValues(endpoint, statusLabel).Inc()
+	metrics.GithubRequestDuration.WithLabelValues(endpoint).Observe(time.Since(start).Seconds())
+	return resp, err
+}
+
+// endpointLabel replaces owners, repositories and refs in the API path with placeholders
+// to keep the label cardinality bounded, e.g. /repos/o/r/commits/sha -> repos/commits/:ref.
+// GitHub Enterprise paths carry an /api/v3 prefix which is dropped.
+func endpointLabel(path string) string {
+	segments := strings.Split(strings.Trim(strings.TrimPrefix(path, "/api/v3"), "/"), "/")
+	switch {
+	case len(segments) >= 3 && segments[0] == "repos":
+		label := "repos"
+		if len(segments) > 3 {
+			label += "/" + segments[3]
+		}
+		if len(segments) > 4 {
+			label += "/:ref"
+		}
+		return label
+	case len(segments) >= 2 && (segments[0] == "users" || segments[0] == "orgs"):
+		label := segments[0] + "/:name"
+		if len(segments) > 2 {
+			label += "/" + segments[2]
+		}
+		return label
+	case len(segments) > 2:
+		return strings.Join(segments[:2], "/")
+	default:
+		return strings.Join(segments, "/")
+	}
+}
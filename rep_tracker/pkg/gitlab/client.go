@@ -0,0 +1,263 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"rep_tracker/pkg/errs"
+	"rep_tracker/pkg/forge"
+	"rep_tracker/pkg/repolink"
+)
+
+const perPage = 100
+
+// GitlabClient talks to the configured GitLab instances through the v4 REST API,
+// picking the instance from the repository link host.
+type GitlabClient struct {
+	hosts map[string]*forge.RESTClient
+}
+
+// NewGitlabClient returns a client for the given GitLab hosts.
+// An empty APIBaseURL defaults to https://{Host}/api/v4/.
+func NewGitlabClient(hosts ...forge.HostConfig) (*GitlabClient, error) {
+	c := &GitlabClient{hosts: make(map[string]*forge.RESTClient)}
+	for _, cfg := range hosts {
+		host := strings.ToLower(cfg.Host)
+		apiBaseURL := cfg.APIBaseURL
+		if apiBaseURL == "" {
+			apiBaseURL = "https://" + host + "/api/v4/"
+		}
+		rest, err := forge.NewRESTClient(apiBaseURL, func(req *http.Request, token string) {
+			req.Header.Set("PRIVATE-TOKEN", token)
+		})
+		if err != nil {
+			return nil, err
+		}
+		c.hosts[host] = rest
+		repolink.RegisterHost(repolink.KindGitLab, host, rest.BaseURL.Hostname())
+	}
+	return c, nil
+}
+
+// Hosts returns the hosts served by the client.
+func (c *GitlabClient) Hosts() []string {
+	hosts := make([]string, 0, len(c.hosts))
+	for host := range c.hosts {
+		hosts = append(hosts, host)
+	}
+	return hosts
+}
+
+func (c *GitlabClient) ParseLink(raw string) (repolink.Link, error) {
+	link, err := repolink.Parse(raw)
+	if err != nil {
+		return repolink.Link{}, err
+	}
+	if _, ok := c.hosts[link.Host]; !ok || link.Kind != repolink.KindGitLab {
+		return repolink.Link{}, &errs.LinkError{Link: raw, Err: errs.ErrUnsupportedHost}
+	}
+	return link, nil
+}
+
+func (c *GitlabClient) ValidateToken(ctx context.Context, host string, token string) (string, error) {
+	rest, ok := c.hosts[strings.ToLower(host)]
+	if !ok {
+		return "", &errs.LinkError{Link: host, Err: errs.ErrUnsupportedHost}
+	}
+	var user struct {
+		Username string `json:"username"`
+	}
+	if _, err := rest.Get(ctx, token, "user", nil, &user); err != nil {
+		return "", forge.ConvertError(err)
+	}
+	return user.Username, nil
+}
+
+func (c *GitlabClient) CheckRepo(ctx context.Context, token string, link string) (bool, error) {
+	rest, project, err := c.projectForLink(link)
+	if err != nil {
+		return false, err
+	}
+	if _, err := rest.Get(ctx, token, project, nil, nil); err != nil {
+		if forge.IsNotFound(err) {
+			return false, nil
+		}
+		return false, forge.ConvertError(err)
+	}
+	return true, nil
+}
+
+func (c *GitlabClient) ResolveBranches(ctx context.Context, token string, link string, patterns []string) ([]string, error) {
+	rest, project, err := c.projectForLink(link)
+	if err != nil {
+		return nil, err
+	}
+	if len(patterns) == 0 {
+		var repo struct {
+			DefaultBranch string `json:"default_branch"`
+		}
+		if _, err := rest.Get(ctx, token, project, nil, &repo); err != nil {
+			return nil, forge.ConvertError(err)
+		}
+		return []string{repo.DefaultBranch}, nil
+	}
+
+	matched := make([]string, 0)
+	page := "1"
+	for page != "" {
+		var branches []struct {
+			Name string `json:"name"`
+		}
+		query := url.Values{"per_page": {strconv.Itoa(perPage)}, "page": {page}}
+		resp, err := rest.Get(ctx, token, project+"/repository/branches", query, &branches)
+		if err != nil {
+			return nil, forge.ConvertError(err)
+		}
+		for _, branch := range branches {
+			if forge.MatchBranch(patterns, branch.Name) {
+				matched = append(matched, branch.Name)
+			}
+		}
+		page = resp.Header.Get("X-Next-Page")
+	}
+	return matched, nil
+}
+
+type apiCommit struct {
+	ID            string    `json:"id"`
+	Message       string    `json:"message"`
+	AuthorName    string    `json:"author_name"`
+	CommittedDate time.Time `json:"committed_date"`
+	WebURL        string    `json:"web_url"`
+}
+
+func (commit apiCommit) convert() *forge.Commit {
+	return &forge.Commit{
+		SHA:         commit.ID,
+		Message:     commit.Message,
+		AuthorLogin: commit.AuthorName,
+		HTMLURL:     commit.WebURL,
+		CommittedAt: commit.CommittedDate,
+	}
+}
+
+// ListCommitsSince compares the cursor SHA with the branch, reporting a rewritten history when the SHA
+// is no longer an ancestor of the branch, and lists the branch since the cursor time when the SHA is
+// unknown to GitLab. GitLab commits carry no account, so AuthorLogin is the git author name.
+func (c *GitlabClient) ListCommitsSince(ctx context.Context, token string, link string, branch string, cursor forge.Cursor, limit int) (*forge.CommitList, error) {
+	rest, project, err := c.projectForLink(link)
+	if err != nil {
+		return nil, err
+	}
+	if cursor.SHA != "" {
+		commits, err := compare(ctx, rest, token, project, cursor.SHA, branch)
+		if err == nil {
+			result := forge.NewestFirst(commits, len(commits), limit)
+			// GitLab reports no comparison status, so commits only reachable from the old head reveal a rewrite.
+			dropped, err := compare(ctx, rest, token, project, branch, cursor.SHA)
+			if err != nil {
+				return nil, forge.ConvertError(err)
+			}
+			if len(dropped) > 0 {
+				var head struct {
+					Commit apiCommit `json:"commit"`
+				}
+				if _, err := rest.Get(ctx, token, project+"/repository/branches/"+url.PathEscape(branch), nil, &head); err != nil {
+					return nil, forge.ConvertError(err)
+				}
+				result.Rewritten = true
+				result.Dropped = forge.NewestFirst(dropped, len(dropped), len(dropped)).Commits
+				result.Head = head.Commit.ID
+			}
+			return result, nil
+		}
+		if !forge.IsNotFound(err) {
+			return nil, forge.ConvertError(err)
+		}
+	}
+	query := url.Values{"ref_name": {branch}, "per_page": {strconv.Itoa(perPage)}}
+	if !cursor.Since.IsZero() {
+		query.Set("since", cursor.Since.UTC().Format(time.RFC3339))
+	}
+	result := &forge.CommitList{Commits: make([]*forge.Commit, 0)}
+	page := "1"
+	for page != "" {
+		var commits []apiCommit
+		query.Set("page", page)
+		resp, err := rest.Get(ctx, token, project+"/repository/commits", query, &commits)
+		if err != nil {
+			return nil, forge.ConvertError(err)
+		}
+		for _, commit := range commits {
+			if commit.ID == "" {
+				continue
+			}
+			if converted := commit.convert(); cursor.Unseen(converted) {
+				result.Commits = append(result.Commits, converted)
+			}
+		}
+		page = resp.Header.Get("X-Next-Page")
+		if len(result.Commits) > limit {
+			break
+		}
+	}
+	result.Truncated = len(result.Commits) > limit
+	result.Commits = result.Commits[:min(limit, len(result.Commits))]
+	return result, nil
+}
+
+// compare returns the commits reachable from to but not from from, oldest first.
+func compare(ctx context.Context, rest *forge.RESTClient, token string, project string, from string, to string) ([]*forge.Commit, error) {
+	var comparison struct {
+		Commits []apiCommit `json:"commits"`
+	}
+	query := url.Values{"from": {from}, "to": {to}}
+	if _, err := rest.Get(ctx, token, project+"/repository/compare", query, &comparison); err != nil {
+		return nil, err
+	}
+	commits := make([]*forge.Commit, 0, len(comparison.Commits))
+	for _, commit := range comparison.Commits {
+		if commit.ID != "" {
+			commits = append(commits, commit.convert())
+		}
+	}
+	return commits, nil
+}
+
+func (c *GitlabClient) GetCommitFiles(ctx context.Context, token string, link string, sha string) ([]string, error) {
+	rest, project, err := c.projectForLink(link)
+	if err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0)
+	page := "1"
+	for page != "" {
+		var diffs []struct {
+			NewPath string `json:"new_path"`
+		}
+		query := url.Values{"per_page": {strconv.Itoa(perPage)}, "page": {page}}
+		resp, err := rest.Get(ctx, token, project+"/repository/commits/"+url.PathEscape(sha)+"/diff", query, &diffs)
+		if err != nil {
+			return nil, forge.ConvertError(err)
+		}
+		for _, diff := range diffs {
+			paths = append(paths, diff.NewPath)
+		}
+		page = resp.Header.Get("X-Next-Page")
+	}
+	return paths, nil
+}
+
+// projectForLink returns the API client of the link host and the project path,
+// which addresses the project by its URL-encoded full path, e.g. projects/group%2Fsub%2Frepo.
+func (c *GitlabClient) projectForLink(link string) (*forge.RESTClient, string, error) {
+	parsed, err := c.ParseLink(link)
+	if err != nil {
+		return nil, "", err
+	}
+	return c.hosts[parsed.Host], "projects/" + url.PathEscape(parsed.FullName()), nil
+}
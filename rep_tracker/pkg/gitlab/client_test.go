@@ -0,0 +1,261 @@
+package gitlab
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"slices"
+	"testing"
+	"time"
+
+	"rep_tracker/pkg/forge"
+	"rep_tracker/pkg/forge/forgetest"
+)
+
+const (
+	testLink = "https://gitlab.test/Group/Sub/Repo.git"
+	// projectPath is the project addressed by its full path with the slashes encoded.
+	projectPath = "/api/v4/projects/group%2Fsub%2Frepo"
+
+	shaA0 = "aaa0000000000000000000000000000000000000"
+	shaA1 = "aaa1000000000000000000000000000000000001"
+	shaB2 = "bbb2000000000000000000000000000000000002"
+	shaC3 = "ccc3000000000000000000000000000000000003"
+	shaE5 = "eee5000000000000000000000000000000000005"
+)
+
+var cursorTime = time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+func newTestClient(t *testing.T, routes map[string]forgetest.Response) (*GitlabClient, *forgetest.Server) {
+	t.Helper()
+	srv := forgetest.NewServer(t, routes)
+	c, err := NewGitlabClient(forge.HostConfig{Host: "gitlab.test", APIBaseURL: srv.URL + "/api/v4/"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c, srv
+}
+
+func shas(commits []*forge.Commit) []string {
+	result := make([]string, 0, len(commits))
+	for _, commit := range commits {
+		result = append(result, commit.SHA)
+	}
+	return result
+}
+
+func compareRoute(from string, to string) string {
+	return forgetest.Route(projectPath+"/repository/compare", url.Values{"from": {from}, "to": {to}})
+}
+
+func pageRoute(path string, page string, query url.Values) string {
+	values := url.Values{"page": {page}, "per_page": {"100"}}
+	for key, value := range query {
+		values[key] = value
+	}
+	return forgetest.Route(path, values)
+}
+
+func TestResolveBranches(t *testing.T) {
+	c, _ := newTestClient(t, map[string]forgetest.Response{
+		projectPath: {File: "project.json"},
+		pageRoute(projectPath+"/repository/branches", "1", nil): {
+			File:   "branches_page1.json",
+			Header: map[string]string{"X-Next-Page": "2"},
+		},
+		pageRoute(projectPath+"/repository/branches", "2", nil): {
+			File:   "branches_page2.json",
+			Header: map[string]string{"X-Next-Page": ""},
+		},
+	})
+
+	tests := []struct {
+		name     string
+		patterns []string
+		want     []string
+	}{
+		{name: "default branch", want: []string{"main"}},
+		{name: "patterns across pages", patterns: []string{"release/*"}, want: []string{"release/1.0", "release/2.0"}},
+		{name: "exact name", patterns: []string{"feature/x"}, want: []string{"feature/x"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := c.ResolveBranches(context.Background(), "token", testLink, tt.patterns)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("branches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListCommitsSince(t *testing.T) {
+	tests := []struct {
+		name          string
+		branch        string
+		cursor        forge.Cursor
+		limit         int
+		routes        map[string]forgetest.Response
+		wantCommits   []string
+		wantTruncated bool
+		wantDropped   []string
+		wantHead      string
+	}{
+		{
+			name:   "compared commits newest first",
+			branch: "main",
+			cursor: forge.Cursor{SHA: shaA1, Since: cursorTime},
+			limit:  10,
+			routes: map[string]forgetest.Response{
+				compareRoute(shaA1, "main"): {File: "compare_ahead.json"},
+				compareRoute("main", shaA1): {File: "compare_empty.json"},
+			},
+			wantCommits: []string{shaC3, shaB2},
+		},
+		{
+			name:   "compared commits beyond the limit",
+			branch: "main",
+			cursor: forge.Cursor{SHA: shaA1, Since: cursorTime},
+			limit:  1,
+			routes: map[string]forgetest.Response{
+				compareRoute(shaA1, "main"): {File: "compare_ahead.json"},
+				compareRoute("main", shaA1): {File: "compare_empty.json"},
+			},
+			wantCommits:   []string{shaC3},
+			wantTruncated: true,
+		},
+		{
+			name:   "rewritten branch with a slash",
+			branch: "feature/x",
+			cursor: forge.Cursor{SHA: shaA1, Since: cursorTime},
+			limit:  1,
+			routes: map[string]forgetest.Response{
+				compareRoute(shaA1, "feature/x"):                 {File: "compare_rewritten.json"},
+				compareRoute("feature/x", shaA1):                 {File: "compare_dropped.json"},
+				projectPath + "/repository/branches/feature%2Fx": {File: "branch_feature.json"},
+			},
+			wantCommits: []string{shaE5},
+			// All dropped commits are returned regardless of the limit.
+			wantDropped: []string{shaA1, shaA0},
+			wantHead:    shaE5,
+		},
+		{
+			name:   "listed by date when the cursor commit is unknown",
+			branch: "main",
+			cursor: forge.Cursor{SHA: "gone", Since: cursorTime},
+			limit:  2,
+			routes: map[string]forgetest.Response{
+				compareRoute("gone", "main"): {File: "not_found.json", Status: http.StatusNotFound},
+				pageRoute(projectPath+"/repository/commits", "1", url.Values{"ref_name": {"main"}, "since": {"2024-05-01T10:00:00Z"}}): {
+					File:   "commits_page1.json",
+					Header: map[string]string{"X-Next-Page": "2"},
+				},
+				pageRoute(projectPath+"/repository/commits", "2", url.Values{"ref_name": {"main"}, "since": {"2024-05-01T10:00:00Z"}}): {
+					File:   "commits_page2.json",
+					Header: map[string]string{"X-Next-Page": ""},
+				},
+			},
+			// The cursor commit is listed again since "since" is inclusive, it must not count as truncation.
+			wantCommits: []string{shaC3, shaB2},
+		},
+		{
+			name:   "listed by date beyond the limit",
+			branch: "main",
+			cursor: forge.Cursor{Since: cursorTime},
+			limit:  1,
+			routes: map[string]forgetest.Response{
+				pageRoute(projectPath+"/repository/commits", "1", url.Values{"ref_name": {"main"}, "since": {"2024-05-01T10:00:00Z"}}): {
+					File:   "commits_page1.json",
+					Header: map[string]string{"X-Next-Page": "2"},
+				},
+			},
+			wantCommits:   []string{shaC3},
+			wantTruncated: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := newTestClient(t, tt.routes)
+			got, err := c.ListCommitsSince(context.Background(), "token", testLink, tt.branch, tt.cursor, tt.limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !slices.Equal(shas(got.Commits), tt.wantCommits) {
+				t.Errorf("commits = %v, want %v", shas(got.Commits), tt.wantCommits)
+			}
+			if got.Truncated != tt.wantTruncated {
+				t.Errorf("truncated = %v, want %v", got.Truncated, tt.wantTruncated)
+			}
+			if got.Rewritten != (tt.wantDropped != nil) {
+				t.Errorf("rewritten = %v, want %v", got.Rewritten, tt.wantDropped != nil)
+			}
+			if !slices.Equal(shas(got.Dropped), tt.wantDropped) {
+				t.Errorf("dropped = %v, want %v", shas(got.Dropped), tt.wantDropped)
+			}
+			if got.Head != tt.wantHead {
+				t.Errorf("head = %q, want %q", got.Head, tt.wantHead)
+			}
+		})
+	}
+}
+
+func TestListCommitsSinceConvertsCommits(t *testing.T) {
+	c, _ := newTestClient(t, map[string]forgetest.Response{
+		compareRoute(shaA1, "main"): {File: "compare_ahead.json"},
+		compareRoute("main", shaA1): {File: "compare_empty.json"},
+	})
+	got, err := c.ListCommitsSince(context.Background(), "token", testLink, "main", forge.Cursor{SHA: shaA1}, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := forge.Commit{
+		SHA:         shaC3,
+		Message:     "Add parser\n\nSplits the input.\n",
+		AuthorLogin: "Ann Lee",
+		HTMLURL:     "https://gitlab.test/group/sub/repo/-/commit/" + shaC3,
+		CommittedAt: time.Date(2024, 5, 1, 10, 10, 0, 0, time.UTC),
+	}
+	newest := *got.Commits[0]
+	if !newest.CommittedAt.Equal(want.CommittedAt) {
+		t.Errorf("committed at = %v, want %v", newest.CommittedAt, want.CommittedAt)
+	}
+	newest.CommittedAt = want.CommittedAt
+	if newest != want {
+		t.Errorf("commit = %+v, want %+v", newest, want)
+	}
+}
+
+func TestGetCommitFiles(t *testing.T) {
+	diffPath := projectPath + "/repository/commits/" + shaC3 + "/diff"
+	c, _ := newTestClient(t, map[string]forgetest.Response{
+		pageRoute(diffPath, "1", nil): {File: "diff_page1.json", Header: map[string]string{"X-Next-Page": "2"}},
+		pageRoute(diffPath, "2", nil): {File: "diff_page2.json", Header: map[string]string{"X-Next-Page": ""}},
+	})
+	got, err := c.GetCommitFiles(context.Background(), "token", testLink, shaC3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"README.md", "pkg/parser/parser.go"}
+	if !slices.Equal(got, want) {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
+
+func TestNotFoundRepo(t *testing.T) {
+	c, srv := newTestClient(t, map[string]forgetest.Response{
+		projectPath: {File: "not_found.json", Status: http.StatusNotFound},
+	})
+	ok, err := c.CheckRepo(context.Background(), "token", testLink)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok {
+		t.Error("CheckRepo = true for a missing project")
+	}
+	// The full path is one encoded segment, a decoded path would address another resource.
+	if got := srv.Requests(); !slices.Equal(got, []string{projectPath}) {
+		t.Errorf("requests = %v, want %v", got, []string{projectPath})
+	}
+}
@@ -1,6 +1,13 @@
 package gorm
 
-import "time"
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"rep_tracker/pkg/filters"
+)
 
 type FileState string
 
@@ -10,6 +17,103 @@ const (
 	FileStateDeleted  FileState = "DELETED"
 )
 
+// StringList is stored as a JSONB array.
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	raw, err := json.Marshal([]string(l))
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func (l *StringList) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for StringList: %T", src)
+	}
+	return json.Unmarshal(raw, (*[]string)(l))
+}
+
+// StringMap is stored as a JSONB object.
+type StringMap map[string]string
+
+func (m StringMap) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	raw, err := json.Marshal(map[string]string(m))
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func (m *StringMap) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for StringMap: %T", src)
+	}
+	return json.Unmarshal(raw, (*map[string]string)(m))
+}
+
+// TimeMap is stored as a JSONB object of RFC 3339 timestamps.
+type TimeMap map[string]time.Time
+
+func (m TimeMap) Value() (driver.Value, error) {
+	if m == nil {
+		return "{}", nil
+	}
+	raw, err := json.Marshal(map[string]time.Time(m))
+	if err != nil {
+		return nil, err
+	}
+	return string(raw), nil
+}
+
+func (m *TimeMap) Scan(src any) error {
+	var raw []byte
+	switch v := src.(type) {
+	case nil:
+		*m = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return fmt.Errorf("unsupported type for TimeMap: %T", src)
+	}
+	return json.Unmarshal(raw, (*map[string]time.Time)(m))
+}
+
+// Reasons stored in notifications.disable_reason.
+const (
+	DisableReasonPaused       = "PAUSED"
+	DisableReasonInvalidToken = "INVALID_TOKEN"
+	DisableReasonRepoNotFound = "REPO_NOT_FOUND"
+)
+
 type User struct {
 	ID        int       `gorm:"column:id;primaryKey;autoIncrement"`
 	ChatID    string    `gorm:"column:chat_id;unique;not null"`
@@ -24,10 +128,14 @@ type User struct {
 
 type Token struct {
 	ID             int        `gorm:"column:id;primaryKey;autoIncrement"`
-	UserID         int        `gorm:"column:user_id;unique;not null"`
+	UserID         int        `gorm:"column:user_id;not null"`
+	Host           string     `gorm:"column:host;not null;default:github.com"`
 	Token          string     `gorm:"column:token;size:256;unique;not null"`
 	CreatedAt      time.Time  `gorm:"column:created_at;autoCreateTime"`
 	LastValidateAt *time.Time `gorm:"column:last_validate_at"`
+	// KeyID and DataKey are set when Token is sealed, see tokencrypt.
+	KeyID   *string `gorm:"column:key_id"`
+	DataKey *string `gorm:"column:data_key"`
 
 	User User `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
 }
@@ -38,6 +146,8 @@ type Repo struct {
 	Owner   *string   `gorm:"column:owner"`
 	Name    *string   `gorm:"column:name"`
 	AddedAt time.Time `gorm:"column:added_at;autoCreateTime"`
+	// InstallationID is the GitHub App installation covering the repository, if any.
+	InstallationID *int64 `gorm:"column:installation_id"`
 
 	UserRepos     []UserRepo     `gorm:"foreignKey:RepoID;references:ID;constraint:OnDelete:CASCADE"`
 	Branches      []Branch       `gorm:"foreignKey:RepoID;references:ID;constraint:OnDelete:CASCADE"`
@@ -89,6 +199,8 @@ type Commit struct {
 	Message    *string   `gorm:"column:message"`
 	Pushing    *bool     `gorm:"column:pushing"`
 	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+	// DroppedAt is set once a force push removed the commit from its branch.
+	DroppedAt *time.Time `gorm:"column:dropped_at"`
 
 	Repo          Repo           `gorm:"foreignKey:RepoID;references:ID;constraint:OnDelete:CASCADE"`
 	Branch        *Branch        `gorm:"foreignKey:BranchID;references:ID;constraint:OnDelete:SET NULL"`
@@ -104,6 +216,15 @@ type CommitFile struct {
 	File   File   `gorm:"foreignKey:FileID;references:ID;constraint:OnDelete:CASCADE"`
 }
 
+// CommitBranch records every tracked branch a commit was seen on; Commit.BranchID is only the first.
+type CommitBranch struct {
+	CommitID int64 `gorm:"column:commit_id;primaryKey"`
+	BranchID int   `gorm:"column:branch_id;primaryKey"`
+
+	Commit Commit `gorm:"foreignKey:CommitID;references:ID;constraint:OnDelete:CASCADE"`
+	Branch Branch `gorm:"foreignKey:BranchID;references:ID;constraint:OnDelete:CASCADE"`
+}
+
 type EditorSession struct {
 	ID         int64      `gorm:"column:id;primaryKey;autoIncrement"`
 	FileID     int        `gorm:"column:file_id;not null"`
@@ -117,14 +238,39 @@ type EditorSession struct {
 }
 
 type Notification struct {
-	ID         int       `gorm:"column:id;primaryKey;autoIncrement"`
-	UserID     int       `gorm:"column:user_id;not null"`
-	RepoID     int       `gorm:"column:repo_id;not null"`
-	LastCommit *int64    `gorm:"column:last_commit"`
-	Enabled    bool      `gorm:"column:enabled;default:true"`
-	CreatedAt  time.Time `gorm:"column:created_at;autoCreateTime"`
+	ID            int             `gorm:"column:id;primaryKey;autoIncrement"`
+	UserID        int             `gorm:"column:user_id;not null"`
+	RepoID        int             `gorm:"column:repo_id;not null"`
+	LastCommit    *int64          `gorm:"column:last_commit"`
+	Enabled       bool            `gorm:"column:enabled;default:true"`
+	PausedUntil   *time.Time      `gorm:"column:paused_until"`
+	DisableReason *string         `gorm:"column:disable_reason"`
+	Branches      StringList      `gorm:"column:branches;type:jsonb;not null;default:'[]'"`
+	Filters       filters.Filters `gorm:"column:filters;type:jsonb;not null;default:'{}'"`
+	// BranchHeads maps each branch to the head this subscription was last notified up to.
+	BranchHeads StringMap `gorm:"column:branch_heads;type:jsonb;not null;default:'{}'"`
+	// BranchSince maps each branch to the commit time of its head in BranchHeads.
+	BranchSince TimeMap `gorm:"column:branch_since;type:jsonb;not null;default:'{}'"`
+	// CursorSince is where commits are listed from while no commit was delivered, see ResetCursor.
+	CursorSince *time.Time `gorm:"column:cursor_since"`
+	CreatedAt   time.Time  `gorm:"column:created_at;autoCreateTime"`
 
 	User             User    `gorm:"foreignKey:UserID;references:ID;constraint:OnDelete:CASCADE"`
 	Repo             Repo    `gorm:"foreignKey:RepoID;references:ID;constraint:OnDelete:CASCADE"`
 	LastCommitEntity *Commit `gorm:"foreignKey:LastCommit;references:ID;constraint:OnDelete:SET NULL"`
 }
+
+// HTTPCacheEntry is a stored API response of one credential, see httpcache. Body is sealed with
+// the token keyring like tokens, KeyID and DataKey are its envelope.
+type HTTPCacheEntry struct {
+	TokenKey     string    `gorm:"column:token_key;primaryKey"`
+	URL          string    `gorm:"column:url;primaryKey"`
+	ETag         *string   `gorm:"column:etag"`
+	LastModified *string   `gorm:"column:last_modified"`
+	ContentType  *string   `gorm:"column:content_type"`
+	Link         *string   `gorm:"column:link"`
+	Body         []byte    `gorm:"column:body;not null"`
+	KeyID        string    `gorm:"column:key_id;not null"`
+	DataKey      string    `gorm:"column:data_key;not null"`
+	UpdatedAt    time.Time `gorm:"column:updated_at;not null"`
+}
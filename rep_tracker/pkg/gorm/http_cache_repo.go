@@ -0,0 +1,93 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"rep_tracker/pkg/httpcache"
+	"service_common/tokencrypt"
+
+	gormio "gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// GormHTTPCache is an httpcache.Store kept in Postgres, so conditional requests survive restarts.
+// Bodies may hold private repository data, so they are sealed with the token keyring, and entries
+// older than the TTL are neither served nor kept (see PurgeBefore).
+type GormHTTPCache struct {
+	gorm    *gormio.DB
+	keyring *tokencrypt.Keyring
+	ttl     time.Duration
+}
+
+func NewGormHTTPCache(gorm *gormio.DB, keyring *tokencrypt.Keyring, ttl time.Duration) *GormHTTPCache {
+	return &GormHTTPCache{gorm: gorm, keyring: keyring, ttl: ttl}
+}
+
+// TTL returns how long entries are kept after their last refresh.
+func (r *GormHTTPCache) TTL() time.Duration {
+	return r.ttl
+}
+
+func (r *GormHTTPCache) Get(ctx context.Context, key string, url string) (*httpcache.Entry, error) {
+	entry, err := gormio.G[HTTPCacheEntry](r.gorm).
+		Where("token_key = ? AND url = ? AND updated_at >= ?", key, url, time.Now().UTC().Add(-r.ttl)).
+		First(ctx)
+	if err != nil {
+		if errors.Is(err, gormio.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	body, err := r.keyring.OpenBytes(tokencrypt.Sealed{
+		Token:   string(entry.Body),
+		KeyID:   &entry.KeyID,
+		DataKey: &entry.DataKey,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &httpcache.Entry{
+		ETag:         derefString(entry.ETag),
+		LastModified: derefString(entry.LastModified),
+		ContentType:  derefString(entry.ContentType),
+		Link:         derefString(entry.Link),
+		Body:         body,
+		UpdatedAt:    entry.UpdatedAt,
+	}, nil
+}
+
+func (r *GormHTTPCache) Put(ctx context.Context, key string, url string, entry *httpcache.Entry) error {
+	sealed, err := r.keyring.SealBytes(entry.Body)
+	if err != nil {
+		return err
+	}
+	return r.gorm.WithContext(ctx).Clauses(clause.OnConflict{UpdateAll: true}).Create(&HTTPCacheEntry{
+		TokenKey:     key,
+		URL:          url,
+		ETag:         ptrString(entry.ETag),
+		LastModified: ptrString(entry.LastModified),
+		ContentType:  ptrString(entry.ContentType),
+		Link:         ptrString(entry.Link),
+		Body:         []byte(sealed.Token),
+		KeyID:        *sealed.KeyID,
+		DataKey:      *sealed.DataKey,
+		UpdatedAt:    entry.UpdatedAt,
+	}).Error
+}
+
+// Touch refreshes the entry, keeping it from expiring while the server answers 304 for it.
+func (r *GormHTTPCache) Touch(ctx context.Context, key string, url string, updatedAt time.Time) error {
+	_, err := gormio.G[HTTPCacheEntry](r.gorm).
+		Where("token_key = ? AND url = ?", key, url).
+		Update(ctx, "updated_at", updatedAt)
+	return err
+}
+
+// PurgeBefore removes entries not refreshed since before, e.g. lists of commits since a cursor
+// that has moved on. It returns the number of removed entries.
+func (r *GormHTTPCache) PurgeBefore(ctx context.Context, before time.Time) (int, error) {
+	removed, err := gormio.G[HTTPCacheEntry](r.gorm).Where("updated_at < ?", before).Delete(ctx)
+	return removed, err
+}
@@ -0,0 +1,39 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rep_tracker/pkg/httpcache"
+)
+
+func TestGormHTTPCacheTouch(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	cache := NewGormHTTPCache(db, testKeyring(t, "new"), time.Hour)
+	const url = "https://api.github.com/repos/owner/repo/commits"
+
+	err := cache.Put(ctx, "key", url, &httpcache.Entry{ETag: `"v1"`, Body: []byte(`[1]`), UpdatedAt: time.Now().UTC().Add(-2 * time.Hour)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry, err := cache.Get(ctx, "key", url); err != nil || entry != nil {
+		t.Fatalf("expired entry = %v, %v, want nil", entry, err)
+	}
+
+	// A 304 keeps the entry current.
+	if err := cache.Touch(ctx, "key", url, time.Now().UTC()); err != nil {
+		t.Fatal(err)
+	}
+	entry, err := cache.Get(ctx, "key", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry == nil || entry.ETag != `"v1"` || string(entry.Body) != `[1]` {
+		t.Fatalf("touched entry = %+v, want the stored one", entry)
+	}
+	if removed, err := cache.PurgeBefore(ctx, time.Now().UTC().Add(-time.Hour)); err != nil || removed != 0 {
+		t.Errorf("purged = %v, %v, want the touched entry kept", removed, err)
+	}
+}
@@ -0,0 +1,52 @@
+package gorm
+
+import (
+	"context"
+	"errors"
+
+	"rep_tracker/pkg/repolink"
+
+	gormio "gorm.io/gorm"
+)
+
+// GetRepoInstallation returns the GitHub App installation linked to the repository,
+// or 0 when there is none or the repository is not tracked.
+func (r *GormSchedulerRepo) GetRepoInstallation(ctx context.Context, link string) (int64, error) {
+	parsed, err := repolink.Parse(link)
+	if err != nil {
+		return 0, err
+	}
+	repo, err := findRepo(ctx, r.gorm, parsed)
+	if err != nil {
+		if errors.Is(err, gormio.ErrRecordNotFound) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	if repo.InstallationID == nil {
+		return 0, nil
+	}
+	return *repo.InstallationID, nil
+}
+
+// SaveRepoInstallation links the repository to the installation; 0 removes the link.
+// Untracked repositories are ignored.
+func (r *GormSchedulerRepo) SaveRepoInstallation(ctx context.Context, link string, installationID int64) error {
+	parsed, err := repolink.Parse(link)
+	if err != nil {
+		return err
+	}
+	repo, err := findRepo(ctx, r.gorm, parsed)
+	if err != nil {
+		if errors.Is(err, gormio.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	var value *int64
+	if installationID != 0 {
+		value = &installationID
+	}
+	_, err = gormio.G[Repo](r.gorm).Where("id = ?", repo.ID).Update(ctx, "installation_id", value)
+	return err
+}
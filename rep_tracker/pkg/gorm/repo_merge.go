@@ -0,0 +1,151 @@
+package gorm
+
+import (
+	"context"
+
+	"rep_tracker/pkg/repolink"
+
+	"go.uber.org/zap"
+	gormio "gorm.io/gorm"
+)
+
+// MergeDuplicateRepos folds repos rows that point to the same repository under different spellings
+// of its link into the oldest row and rewrites that row to the canonical link, owner and name.
+// Subscriptions, branches, files and commits of the duplicates are moved to the kept row. It is
+// idempotent and returns the number of removed duplicates.
+func (r *GormSchedulerRepo) MergeDuplicateRepos(ctx context.Context) (int, error) {
+	repos, err := gormio.G[Repo](r.gorm).Order("id").Find(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	groups := make(map[repolink.Link][]Repo)
+	order := make([]repolink.Link, 0)
+	for _, repo := range repos {
+		link, err := repolink.Parse(repo.URL)
+		if err != nil {
+			zap.L().Warn("Skipping repository with unparsable link",
+				zap.Int("repoId", repo.ID),
+				zap.String("link", repo.URL),
+				zap.Error(err))
+			continue
+		}
+		if _, ok := groups[link]; !ok {
+			order = append(order, link)
+		}
+		groups[link] = append(groups[link], repo)
+	}
+
+	merged := 0
+	for _, link := range order {
+		group := groups[link]
+		keeper := group[0]
+		if len(group) == 1 && keeper.URL == link.String() &&
+			derefString(keeper.Owner) == link.Owner && derefString(keeper.Name) == link.Name {
+			continue
+		}
+		err := r.gorm.WithContext(ctx).Transaction(func(tx *gormio.DB) error {
+			for _, duplicate := range group[1:] {
+				if err := mergeRepoInto(tx, keeper.ID, duplicate.ID); err != nil {
+					return err
+				}
+			}
+			return tx.Model(&Repo{}).
+				Where("id = ?", keeper.ID).
+				Updates(map[string]any{
+					"url":   link.String(),
+					"owner": link.Owner,
+					"name":  link.Name,
+				}).Error
+		})
+		if err != nil {
+			return merged, err
+		}
+		merged += len(group) - 1
+		if len(group) > 1 {
+			zap.L().Info("Merged duplicate repositories",
+				zap.String("link", link.String()),
+				zap.Int("repoId", keeper.ID),
+				zap.Int("duplicates", len(group)-1))
+		}
+	}
+	return merged, nil
+}
+
+// mergeRepoInto moves everything that references the duplicate repo to the keeper and deletes the duplicate.
+// Rows that already exist on the keeper (same branch name, file path, commit hash or subscriber) win.
+func mergeRepoInto(tx *gormio.DB, keeperID int, duplicateID int) error {
+	statements := []string{
+		// Branches: commits of a branch that exists on both sides move to the keeper's branch.
+		`UPDATE commits c SET branch_id = k.id
+			FROM branches d JOIN branches k ON k.repo_id = @keeper AND k.name = d.name
+			WHERE d.repo_id = @duplicate AND c.branch_id = d.id`,
+		`INSERT INTO commit_branches (commit_id, branch_id)
+			SELECT cb.commit_id, k.id FROM commit_branches cb
+			JOIN branches d ON d.id = cb.branch_id
+			JOIN branches k ON k.repo_id = @keeper AND k.name = d.name
+			WHERE d.repo_id = @duplicate
+			ON CONFLICT DO NOTHING`,
+		`DELETE FROM branches d USING branches k
+			WHERE d.repo_id = @duplicate AND k.repo_id = @keeper AND k.name = d.name`,
+		`UPDATE branches SET repo_id = @keeper WHERE repo_id = @duplicate`,
+
+		// Files: references to a path that exists on both sides move to the keeper's file.
+		`INSERT INTO commit_files (commit_id, file_id)
+			SELECT cf.commit_id, k.id FROM commit_files cf
+			JOIN files d ON d.id = cf.file_id
+			JOIN files k ON k.repo_id = @keeper AND k.path = d.path
+			WHERE d.repo_id = @duplicate
+			ON CONFLICT DO NOTHING`,
+		`UPDATE editor_sessions s SET file_id = k.id
+			FROM files d JOIN files k ON k.repo_id = @keeper AND k.path = d.path
+			WHERE d.repo_id = @duplicate AND s.file_id = d.id`,
+		`DELETE FROM files d USING files k
+			WHERE d.repo_id = @duplicate AND k.repo_id = @keeper AND k.path = d.path`,
+		`UPDATE files SET repo_id = @keeper WHERE repo_id = @duplicate`,
+
+		// Commits: references to a hash that exists on both sides move to the keeper's commit.
+		`UPDATE notifications n SET last_commit = k.id
+			FROM commits d JOIN commits k ON k.repo_id = @keeper AND k.commit_hash = d.commit_hash
+			WHERE d.repo_id = @duplicate AND n.last_commit = d.id`,
+		`UPDATE branches b SET last_commit_id = k.id
+			FROM commits d JOIN commits k ON k.repo_id = @keeper AND k.commit_hash = d.commit_hash
+			WHERE d.repo_id = @duplicate AND b.last_commit_id = d.id`,
+		`INSERT INTO commit_files (commit_id, file_id)
+			SELECT k.id, cf.file_id FROM commit_files cf
+			JOIN commits d ON d.id = cf.commit_id
+			JOIN commits k ON k.repo_id = @keeper AND k.commit_hash = d.commit_hash
+			WHERE d.repo_id = @duplicate
+			ON CONFLICT DO NOTHING`,
+		`INSERT INTO commit_branches (commit_id, branch_id)
+			SELECT k.id, cb.branch_id FROM commit_branches cb
+			JOIN commits d ON d.id = cb.commit_id
+			JOIN commits k ON k.repo_id = @keeper AND k.commit_hash = d.commit_hash
+			WHERE d.repo_id = @duplicate
+			ON CONFLICT DO NOTHING`,
+		`DELETE FROM commits d USING commits k
+			WHERE d.repo_id = @duplicate AND k.repo_id = @keeper AND k.commit_hash = d.commit_hash`,
+		`UPDATE commits SET repo_id = @keeper WHERE repo_id = @duplicate`,
+
+		// Subscriptions: an enabled duplicate replaces a disabled keeper subscription of the same user,
+		// otherwise the keeper's subscription stays.
+		`DELETE FROM notifications k USING notifications d
+			WHERE k.repo_id = @keeper AND d.repo_id = @duplicate AND d.user_id = k.user_id
+			AND COALESCE(d.enabled, TRUE) AND NOT COALESCE(k.enabled, TRUE)`,
+		`DELETE FROM notifications d USING notifications k
+			WHERE d.repo_id = @duplicate AND k.repo_id = @keeper AND k.user_id = d.user_id`,
+		`UPDATE notifications SET repo_id = @keeper WHERE repo_id = @duplicate`,
+
+		`INSERT INTO user_repos (user_id, repo_id, added_at)
+			SELECT user_id, @keeper, added_at FROM user_repos WHERE repo_id = @duplicate
+			ON CONFLICT DO NOTHING`,
+		`DELETE FROM repos WHERE id = @duplicate`,
+	}
+	args := map[string]any{"keeper": keeperID, "duplicate": duplicateID}
+	for _, statement := range statements {
+		if err := tx.Exec(statement, args).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package gorm
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"rep_tracker/pkg/forge"
+
+	gormio "gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func TestMergeDuplicateRepos(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	scheduler := NewGormSchedulerRepo(db)
+	create := func(value any) {
+		t.Helper()
+		if err := db.Omit(clause.Associations).Create(value).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keeper := Repo{URL: "https://github.com/Owner/Repo", Owner: ptrString("Owner"), Name: ptrString("Repo")}
+	duplicate := Repo{URL: "git@github.com:owner/repo.git"}
+	other := Repo{URL: "https://github.com/owner/other", Owner: ptrString("owner"), Name: ptrString("other")}
+	create(&keeper)
+	create(&duplicate)
+	create(&other)
+
+	committedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	commit := func(sha string) *forge.Commit {
+		return &forge.Commit{SHA: sha, Message: sha, CommittedAt: committedAt}
+	}
+	if err := scheduler.SaveCommits(ctx, keeper.ID, "main", commit("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := scheduler.SaveCommits(ctx, duplicate.ID, "main", commit("b"), commit("a")); err != nil {
+		t.Fatal(err)
+	}
+	if err := scheduler.SaveCommits(ctx, duplicate.ID, "dev", commit("c")); err != nil {
+		t.Fatal(err)
+	}
+	duplicateA, err := gormio.G[Commit](db).Where("repo_id = ? AND commit_hash = ?", duplicate.ID, "a").First(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := createTestUser(t, db, "1001")
+	second := createTestUser(t, db, "1002")
+	// The first user's disabled subscription on the keeper is replaced by the enabled one on the duplicate.
+	disabled := Notification{UserID: first, RepoID: keeper.ID, Enabled: true}
+	create(&disabled)
+	// enabled has a default, so false is only stored by an update.
+	if err := db.Model(&disabled).Update("enabled", false).Error; err != nil {
+		t.Fatal(err)
+	}
+	create(&Notification{UserID: first, RepoID: duplicate.ID, Enabled: true, LastCommit: &duplicateA.ID})
+	create(&Notification{UserID: second, RepoID: duplicate.ID, Enabled: true})
+
+	merged, err := scheduler.MergeDuplicateRepos(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged != 1 {
+		t.Errorf("merged = %v, want 1", merged)
+	}
+
+	repos, err := gormio.G[Repo](db).Order("id").Find(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(repos) != 2 || repos[0].ID != keeper.ID || repos[1].ID != other.ID {
+		t.Fatalf("repos = %+v, want the keeper and the other repo", repos)
+	}
+	if got := repos[0]; got.URL != "https://github.com/owner/repo" || derefString(got.Owner) != "owner" || derefString(got.Name) != "repo" {
+		t.Errorf("keeper = %v %v/%v, want the canonical link", got.URL, derefString(got.Owner), derefString(got.Name))
+	}
+
+	commits, err := gormio.G[Commit](db).Where("repo_id = ?", keeper.ID).Order("commit_hash").Find(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var hashes []string
+	commitIDs := make(map[string]int64)
+	for _, c := range commits {
+		hashes = append(hashes, derefString(c.CommitHash))
+		commitIDs[derefString(c.CommitHash)] = c.ID
+	}
+	if want := []string{"a", "b", "c"}; !slices.Equal(hashes, want) {
+		t.Errorf("keeper commits = %v, want %v", hashes, want)
+	}
+
+	branches, err := gormio.G[Branch](db).Where("repo_id = ?", keeper.ID).Order("name").Find(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, b := range branches {
+		names = append(names, b.Name)
+	}
+	if want := []string{"dev", "main"}; !slices.Equal(names, want) {
+		t.Errorf("keeper branches = %v, want %v", names, want)
+	}
+	var onMain []string
+	err = db.Raw(`SELECT c.commit_hash FROM commit_branches cb
+		JOIN commits c ON c.id = cb.commit_id
+		JOIN branches b ON b.id = cb.branch_id
+		WHERE b.repo_id = ? AND b.name = 'main' ORDER BY c.commit_hash`, keeper.ID).Scan(&onMain).Error
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"a", "b"}; !slices.Equal(onMain, want) {
+		t.Errorf("commits on main = %v, want %v", onMain, want)
+	}
+
+	notifications, err := gormio.G[Notification](db).Order("user_id").Find(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notifications) != 2 {
+		t.Fatalf("notifications = %+v, want one per user", notifications)
+	}
+	for _, n := range notifications {
+		if n.RepoID != keeper.ID || !n.Enabled {
+			t.Errorf("notification of user %v = repo %v enabled %v, want the enabled one on the keeper", n.UserID, n.RepoID, n.Enabled)
+		}
+	}
+	if got := notifications[0].LastCommit; got == nil || *got != commitIDs["a"] {
+		t.Errorf("last commit = %v, want the keeper's commit %v", got, commitIDs["a"])
+	}
+
+	merged, err = scheduler.MergeDuplicateRepos(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if merged != 0 {
+		t.Errorf("second run merged = %v, want 0", merged)
+	}
+}
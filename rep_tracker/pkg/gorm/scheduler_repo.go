@@ -3,12 +3,12 @@ package gorm
 import (
 	"context"
 	"errors"
-	"fmt"
-	"net/url"
-	"strings"
+	"slices"
 	"time"
 
-	"github.com/google/go-github/github"
+	"rep_tracker/pkg/forge"
+	"rep_tracker/pkg/repolink"
+
 	"go.uber.org/zap"
 	gormio "gorm.io/gorm"
 	"gorm.io/gorm/clause"
@@ -22,28 +22,33 @@ func NewGormSchedulerRepo(gorm *gormio.DB) *GormSchedulerRepo {
 	return &GormSchedulerRepo{gorm: gorm}
 }
 
-func (r *GormSchedulerRepo) SaveCommitsAndUpdateNotification(ctx context.Context, commits ...*github.RepositoryCommit) error {
+// SaveCommits stores the new commits of the branch, newest first, and records the first one as the
+// branch head. Subscription cursors are moved separately by AdvanceCursor once notifications are delivered.
+func (r *GormSchedulerRepo) SaveCommits(ctx context.Context, repoID int, branchName string, commits ...*forge.Commit) error {
 	if len(commits) == 0 {
 		return nil
 	}
 
-	repoOwner, repoName, ok := parseOwnerRepo(commits[0])
-	if !ok {
-		return fmt.Errorf("unable to parse repo from commit")
-	}
-
 	return r.gorm.WithContext(ctx).Transaction(func(tx *gormio.DB) error {
-		repo, err := findRepo(ctx, tx, repoOwner, repoName)
+		repo, err := gormio.G[Repo](tx).Where("id = ?", repoID).First(ctx)
 		if err != nil {
 			return err
 		}
+		var branchID *int
+		if branchName != "" {
+			branch, err := ensureBranch(ctx, tx, repo.ID, branchName)
+			if err != nil {
+				return err
+			}
+			branchID = &branch.ID
+		}
 
 		hashes := make([]string, 0, len(commits))
 		for _, c := range commits {
-			if c == nil || c.GetSHA() == "" {
+			if c == nil || c.SHA == "" {
 				continue
 			}
-			hashes = append(hashes, c.GetSHA())
+			hashes = append(hashes, c.SHA)
 		}
 
 		existing := make([]Commit, 0, len(hashes))
@@ -57,23 +62,36 @@ func (r *GormSchedulerRepo) SaveCommitsAndUpdateNotification(ctx context.Context
 		}
 
 		known := make(map[string]Commit, len(existing))
+		restored := make([]int64, 0)
 		for _, c := range existing {
 			if c.CommitHash != nil {
 				known[*c.CommitHash] = c
 			}
+			if c.DroppedAt != nil {
+				restored = append(restored, c.ID)
+			}
+		}
+		// Commits dropped by a force push may come back with another one.
+		if len(restored) > 0 {
+			if _, err := gormio.G[Commit](tx).Where("id IN ?", restored).Update(ctx, "dropped_at", nil); err != nil {
+				return err
+			}
 		}
 
 		for _, c := range commits {
-			if c == nil || c.GetSHA() == "" {
+			if c == nil || c.SHA == "" {
 				continue
 			}
-			if _, ok := known[c.GetSHA()]; ok {
+			if _, ok := known[c.SHA]; ok {
 				continue
 			}
 
-			commitTime := getCommitTime(c)
+			commitTime := c.CommittedAt
+			if commitTime.IsZero() {
+				commitTime = time.Now().UTC()
+			}
 			var authorName *string
-			if login := getAuthorLogin(c); login != "" {
+			if login := c.AuthorLogin; login != "" {
 				zap.L().Debug("Finding user for commit", 
 					zap.String("github_username", login),
 					zap.Int("repo_id", repo.ID))
@@ -100,36 +118,90 @@ func (r *GormSchedulerRepo) SaveCommitsAndUpdateNotification(ctx context.Context
 			}
 
 			zap.L().Debug("Creating commit", 
-				zap.String("commit_hash", c.GetSHA()),
+				zap.String("commit_hash", c.SHA),
 				zap.Int("repo_id", repo.ID),
 				zap.Any("author_name", authorName))
 
 			newCommit := Commit{
 				RepoID:     repo.ID,
-				CommitHash: ptrString(c.GetSHA()),
+				BranchID:   branchID,
+				CommitHash: ptrString(c.SHA),
 				AuthorName: authorName, // Use AuthorName instead of AuthorID
-				Message:    ptrString(c.GetCommit().GetMessage()),
+				Message:    ptrString(c.Message),
 				Pushing:    ptrBool(false),
 				CreatedAt:  commitTime,
 			}
 			if err := gormio.G[Commit](tx).Create(ctx, &newCommit); err != nil {
 				return err
 			}
-			known[c.GetSHA()] = newCommit
+			known[c.SHA] = newCommit
 		}
 
-		latest, err := gormio.G[Commit](tx).
-			Where("repo_id = ?", repo.ID).
-			Order("created_at DESC").
-			First(ctx)
-		if err != nil {
-			return err
+		if branchID != nil {
+			// Commits already stored from another branch are linked to this one as well.
+			sightings := make([]CommitBranch, 0, len(known))
+			for _, c := range known {
+				sightings = append(sightings, CommitBranch{CommitID: c.ID, BranchID: *branchID})
+			}
+			if len(sightings) > 0 {
+				err := tx.Omit(clause.Associations).Clauses(clause.OnConflict{DoNothing: true}).Create(&sightings).Error
+				if err != nil {
+					return err
+				}
+			}
+			branchLatest, err := gormio.G[Commit](tx).
+				Where("branch_id = ?", *branchID).
+				Order("created_at DESC").
+				First(ctx)
+			if err == nil {
+				_, err = gormio.G[Branch](tx).
+					Where("id = ?", *branchID).
+					Update(ctx, "last_commit_id", branchLatest.ID)
+			}
+			if err != nil && !errors.Is(err, gormio.ErrRecordNotFound) {
+				return err
+			}
 		}
+		return nil
+	})
+}
 
-		_, err = gormio.G[Notification](tx).
-			Where("repo_id = ? AND enabled = ?", repo.ID, true).
-			Update(ctx, "last_commit", latest.ID)
-		return err
+// AdvanceCursor moves the subscription's cursor on the branch to head after its notifications were delivered.
+// headTime is kept as the time the branch is listed from once head is no longer found; when it is unknown,
+// the branch falls back to the subscription's time. last_commit follows when the head commit is stored.
+func (r *GormSchedulerRepo) AdvanceCursor(ctx context.Context, notificationID int, branchName string, head string, headTime time.Time) error {
+	values := map[string]any{
+		"branch_heads": gormio.Expr("branch_heads || jsonb_build_object(?::text, ?::text)", branchName, head),
+		"branch_since": gormio.Expr("branch_since - ?::text", branchName),
+		"last_commit":  gormio.Expr("COALESCE((SELECT id FROM commits WHERE commits.repo_id = notifications.repo_id AND commit_hash = ? ORDER BY id LIMIT 1), last_commit)", head),
+	}
+	if !headTime.IsZero() {
+		values["branch_since"] = gormio.Expr("branch_since || jsonb_build_object(?::text, ?::timestamptz)", branchName, headTime.UTC())
+	}
+	return r.gorm.WithContext(ctx).Transaction(func(tx *gormio.DB) error {
+		return tx.Model(&Notification{}).
+			Where("id = ?", notificationID).
+			Updates(values).Error
+	})
+}
+
+// markDroppedBatch bounds the hashes per update, a force push may drop any number of commits.
+const markDroppedBatch = 1000
+
+// MarkCommitsDropped marks the commits a force push removed from their branch.
+// The subscription cursors move to the new head through AdvanceCursor.
+func (r *GormSchedulerRepo) MarkCommitsDropped(ctx context.Context, repoID int, hashes ...string) error {
+	return r.gorm.WithContext(ctx).Transaction(func(tx *gormio.DB) error {
+		droppedAt := time.Now().UTC()
+		for batch := range slices.Chunk(hashes, markDroppedBatch) {
+			_, err := gormio.G[Commit](tx).
+				Where("repo_id = ? AND commit_hash IN ? AND dropped_at IS NULL", repoID, batch).
+				Update(ctx, "dropped_at", droppedAt)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
 	})
 }
 
@@ -179,89 +251,87 @@ func (r *GormSchedulerRepo) GetTrackingRepos(ctx context.Context, offset int, li
 
 func (r *GormSchedulerRepo) DisableTracking(ctx context.Context, notificationID int) error {
 	return r.gorm.WithContext(ctx).Transaction(func(tx *gormio.DB) error {
-		_, err := gormio.G[Notification](tx).
+		return tx.Model(&Notification{}).
 			Where("id = ?", notificationID).
-			Update(ctx, "enabled", false)
-		return err
+			Updates(map[string]any{
+				"enabled":        false,
+				"disable_reason": DisableReasonRepoNotFound,
+			}).Error
 	})
 }
 
-func (r *GormSchedulerRepo) DisableTrackingForUser(ctx context.Context, userID int) error {
+// DisableTrackingForUser disables the user's subscriptions on the GitHub host the token was rejected by.
+func (r *GormSchedulerRepo) DisableTrackingForUser(ctx context.Context, userID int, host string) error {
 	return r.gorm.WithContext(ctx).Transaction(func(tx *gormio.DB) error {
-		_, err := gormio.G[Notification](tx).
+		return tx.Model(&Notification{}).
 			Where("user_id = ? AND enabled = ?", userID, true).
-			Update(ctx, "enabled", false)
-		return err
+			Where(reposOnHost(host)).
+			Updates(map[string]any{
+				"enabled":        false,
+				"disable_reason": DisableReasonInvalidToken,
+			}).Error
 	})
 }
 
-func findRepo(ctx context.Context, db *gormio.DB, owner string, name string) (*Repo, error) {
-	repo, err := gormio.G[Repo](db).
-		Where("owner = ? AND name = ?", owner, name).
-		First(ctx)
-	if err == nil {
-		return &repo, nil
-	}
-	if !errors.Is(err, gormio.ErrRecordNotFound) {
+func (r *GormSchedulerRepo) ResumeExpiredPauses(ctx context.Context, now time.Time) (int, error) {
+	var resumed int
+	err := r.gorm.WithContext(ctx).Transaction(func(tx *gormio.DB) error {
+		result := tx.Model(&Notification{}).
+			Where("enabled = ? AND paused_until IS NOT NULL AND paused_until <= ?", false, now).
+			Updates(map[string]any{
+				"enabled":        true,
+				"paused_until":   nil,
+				"disable_reason": nil,
+			})
+		resumed = int(result.RowsAffected)
+		return result.Error
+	})
+	return resumed, err
+}
+
+func ensureBranch(ctx context.Context, db *gormio.DB, repoID int, name string) (*Branch, error) {
+	branch := Branch{RepoID: repoID, Name: name}
+	err := db.WithContext(ctx).
+		Clauses(clause.OnConflict{Columns: []clause.Column{{Name: "repo_id"}, {Name: "name"}}, DoNothing: true}).
+		Create(&branch).Error
+	if err != nil {
 		return nil, err
 	}
-	urlValue := fmt.Sprintf("https://github.com/%s/%s", owner, name)
-	repo, err = gormio.G[Repo](db).
-		Where("url = ? OR url = ?", urlValue, urlValue+".git").
+	if branch.ID != 0 {
+		return &branch, nil
+	}
+	branch, err = gormio.G[Branch](db).
+		Where("repo_id = ? AND name = ?", repoID, name).
 		First(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return &repo, nil
+	return &branch, nil
 }
 
-func parseOwnerRepo(commit *github.RepositoryCommit) (string, string, bool) {
-	if commit == nil {
-		return "", "", false
-	}
-	if owner, repo, ok := parseOwnerRepoFromURL(commit.GetHTMLURL()); ok {
-		return owner, repo, true
-	}
-	if owner, repo, ok := parseOwnerRepoFromURL(commit.GetURL()); ok {
-		return owner, repo, true
-	}
-	return "", "", false
-}
-
-func parseOwnerRepoFromURL(raw string) (string, string, bool) {
-	if raw == "" {
-		return "", "", false
-	}
-	u, err := url.Parse(raw)
+// findRepo looks the repository up by its canonical link. Rows stored before links were
+// canonicalized are matched by owner and name on the same host.
+func findRepo(ctx context.Context, db *gormio.DB, link repolink.Link) (*Repo, error) {
+	urlValue := link.String()
+	candidates, err := gormio.G[Repo](db).
+		Where("url IN ? OR (LOWER(owner) = ? AND LOWER(name) = ?)", []string{urlValue, urlValue + ".git"}, link.Owner, link.Name).
+		Order("id").
+		Find(ctx)
 	if err != nil {
-		return "", "", false
-	}
-	path := strings.Trim(u.Path, "/")
-	parts := strings.Split(path, "/")
-	if len(parts) < 2 {
-		return "", "", false
+		return nil, err
 	}
-	if parts[0] == "repos" && len(parts) >= 3 {
-		return parts[1], strings.TrimSuffix(parts[2], ".git"), true
+	// owner/name alone is ambiguous across hosts, so a fallback match must parse to the same link.
+	for _, repo := range candidates {
+		if parsed, err := repolink.Parse(repo.URL); err == nil && parsed == link {
+			return &repo, nil
+		}
 	}
-	return parts[0], strings.TrimSuffix(parts[1], ".git"), true
+	return nil, gormio.ErrRecordNotFound
 }
 
-func getCommitTime(commit *github.RepositoryCommit) time.Time {
-	if commit == nil || commit.Commit == nil {
-		return time.Now().UTC()
-	}
-	if commit.Commit.Committer != nil {
-		if t := commit.Commit.Committer.GetDate(); !t.IsZero() {
-			return t
-		}
-	}
-	if commit.Commit.Author != nil {
-		if t := commit.Commit.Author.GetDate(); !t.IsZero() {
-			return t
-		}
-	}
-	return time.Now().UTC()
+// reposOnHost restricts notifications to repositories whose canonical link is on host.
+func reposOnHost(host string) clause.Expr {
+	return gormio.Expr("repo_id IN (SELECT id FROM repos WHERE url LIKE ?)", "https://"+host+"/%")
 }
 
 func ptrString(v string) *string {
@@ -274,13 +344,3 @@ func ptrString(v string) *string {
 func ptrBool(v bool) *bool {
 	return &v
 }
-
-func getAuthorLogin(commit *github.RepositoryCommit) string {
-	if commit == nil {
-		return ""
-	}
-	if commit.Author != nil && commit.Author.GetLogin() != "" {
-		return commit.Author.GetLogin()
-	}
-	return ""
-}
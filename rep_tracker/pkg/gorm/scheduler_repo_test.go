@@ -0,0 +1,108 @@
+package gorm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"rep_tracker/pkg/forge"
+
+	gormio "gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func TestAdvanceCursor(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	scheduler := NewGormSchedulerRepo(db)
+
+	repo := Repo{URL: "https://github.com/owner/repo"}
+	if err := db.Omit(clause.Associations).Create(&repo).Error; err != nil {
+		t.Fatal(err)
+	}
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	a := &forge.Commit{SHA: "a", Message: "a", CommittedAt: base}
+	b := &forge.Commit{SHA: "b", Message: "b", CommittedAt: base.Add(time.Minute)}
+	if err := scheduler.SaveCommits(ctx, repo.ID, "main", b, a); err != nil {
+		t.Fatal(err)
+	}
+	commitID := func(sha string) int64 {
+		t.Helper()
+		c, err := gormio.G[Commit](db).Where("repo_id = ? AND commit_hash = ?", repo.ID, sha).First(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c.ID
+	}
+
+	first := Notification{UserID: createTestUser(t, db, "1001"), RepoID: repo.ID, Enabled: true}
+	second := Notification{UserID: createTestUser(t, db, "1002"), RepoID: repo.ID, Enabled: true}
+	for _, n := range []*Notification{&first, &second} {
+		if err := db.Omit(clause.Associations).Create(n).Error; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	type want struct {
+		heads      map[string]string
+		since      map[string]time.Time
+		lastCommit string
+	}
+	check := func(name string, id int, want want) {
+		t.Helper()
+		n, err := gormio.G[Notification](db).Where("id = ?", id).First(ctx)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(n.BranchHeads) != len(want.heads) {
+			t.Errorf("%v: branch heads = %v, want %v", name, n.BranchHeads, want.heads)
+		}
+		for branch, head := range want.heads {
+			if n.BranchHeads[branch] != head {
+				t.Errorf("%v: branch heads = %v, want %v", name, n.BranchHeads, want.heads)
+			}
+		}
+		if len(n.BranchSince) != len(want.since) {
+			t.Errorf("%v: branch since = %v, want %v", name, n.BranchSince, want.since)
+		}
+		for branch, since := range want.since {
+			if !n.BranchSince[branch].Equal(since) {
+				t.Errorf("%v: branch since = %v, want %v", name, n.BranchSince, want.since)
+			}
+		}
+		switch {
+		case want.lastCommit == "" && n.LastCommit != nil:
+			t.Errorf("%v: last commit = %v, want none", name, *n.LastCommit)
+		case want.lastCommit != "" && (n.LastCommit == nil || *n.LastCommit != commitID(want.lastCommit)):
+			t.Errorf("%v: last commit = %v, want the id of %v", name, n.LastCommit, want.lastCommit)
+		}
+	}
+
+	// Each subscription of the repository keeps its own cursor.
+	if err := scheduler.AdvanceCursor(ctx, first.ID, "main", "b", b.CommittedAt); err != nil {
+		t.Fatal(err)
+	}
+	check("first advanced", first.ID, want{heads: map[string]string{"main": "b"}, since: map[string]time.Time{"main": b.CommittedAt}, lastCommit: "b"})
+	check("second untouched", second.ID, want{})
+
+	if err := scheduler.AdvanceCursor(ctx, second.ID, "main", "a", a.CommittedAt); err != nil {
+		t.Fatal(err)
+	}
+	check("second advanced", second.ID, want{heads: map[string]string{"main": "a"}, since: map[string]time.Time{"main": a.CommittedAt}, lastCommit: "a"})
+	check("first kept", first.ID, want{heads: map[string]string{"main": "b"}, since: map[string]time.Time{"main": b.CommittedAt}, lastCommit: "b"})
+
+	// A head that is not stored, e.g. a force push to an older commit, keeps the last commit,
+	// and without a time the branch falls back to it.
+	if err := scheduler.AdvanceCursor(ctx, first.ID, "feature", "x", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	check("branch without a time", first.ID, want{
+		heads:      map[string]string{"main": "b", "feature": "x"},
+		since:      map[string]time.Time{"main": b.CommittedAt},
+		lastCommit: "b",
+	})
+	if err := scheduler.AdvanceCursor(ctx, first.ID, "main", "y", time.Time{}); err != nil {
+		t.Fatal(err)
+	}
+	check("time cleared", first.ID, want{heads: map[string]string{"main": "y", "feature": "x"}, lastCommit: "b"})
+}
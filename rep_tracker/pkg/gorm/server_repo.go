@@ -4,11 +4,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"net/url"
-	"strings"
+	"slices"
 
 	"rep_tracker/internal/server_model"
 	"rep_tracker/pkg/errs"
+	"rep_tracker/pkg/repolink"
 	"go.uber.org/zap"
 
 	gormio "gorm.io/gorm"
@@ -43,24 +43,92 @@ func (r *GormServerRepo) AddNotificationRep(ctx context.Context, trackingRepo *s
 			Where("user_id = ? AND repo_id = ?", userID, repoID).
 			First(ctx)
 		if err == nil {
-			_, err = gormio.G[Notification](tx).
+			// Re-adding an identical subscription is a no-op.
+			if existing.Enabled && slices.Equal([]string(existing.Branches), trackingRepo.Branches) {
+				return nil
+			}
+			return tx.Model(&Notification{}).
 				Where("id = ?", existing.ID).
-				Update(ctx, "enabled", true)
-			return err
+				Updates(map[string]any{
+					"enabled":        true,
+					"paused_until":   nil,
+					"disable_reason": nil,
+					"branches":       StringList(trackingRepo.Branches),
+				}).Error
 		}
 		if !errors.Is(err, gormio.ErrRecordNotFound) {
 			return err
 		}
 
 		newNotification := Notification{
-			UserID:  userID,
-			RepoID:  repoID,
-			Enabled: true,
+			UserID:   userID,
+			RepoID:   repoID,
+			Enabled:  true,
+			Branches: StringList(trackingRepo.Branches),
 		}
 		return gormio.G[Notification](tx).Create(ctx, &newNotification)
 	})
 }
 
+// AddNotificationReps subscribes the user to all links in one transaction.
+// Every link runs in its own savepoint, so one failing link does not roll back the others.
+func (r *GormServerRepo) AddNotificationReps(ctx context.Context, chatID string, links []string) ([]*server_model.ImportResult, error) {
+	results := make([]*server_model.ImportResult, 0, len(links))
+	err := r.gorm.WithContext(ctx).Transaction(func(tx *gormio.DB) error {
+		userID, err := resolveUserID(ctx, tx, chatID)
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			result := &server_model.ImportResult{Link: link}
+			err := tx.Transaction(func(linkTx *gormio.DB) error {
+				repoID, err := resolveRepoID(ctx, linkTx, link)
+				if err != nil {
+					return err
+				}
+				if err := ensureUserRepo(ctx, linkTx, userID, repoID); err != nil {
+					return err
+				}
+				existing, err := gormio.G[Notification](linkTx).
+					Where("user_id = ? AND repo_id = ?", userID, repoID).
+					First(ctx)
+				if err == nil {
+					// Paused and auto-disabled subscriptions keep their state;
+					// only a plain disabled subscription is switched back on.
+					if existing.Enabled || existing.DisableReason != nil {
+						result.Status = server_model.ImportStatusAlreadyTracked
+						return nil
+					}
+					result.Status = server_model.ImportStatusAdded
+					return linkTx.Model(&Notification{}).
+						Where("id = ?", existing.ID).
+						Update("enabled", true).Error
+				}
+				if !errors.Is(err, gormio.ErrRecordNotFound) {
+					return err
+				}
+				result.Status = server_model.ImportStatusAdded
+				return gormio.G[Notification](linkTx).Create(ctx, &Notification{
+					UserID:  userID,
+					RepoID:  repoID,
+					Enabled: true,
+				})
+			})
+			if err != nil {
+				zap.L().Warn("Failed to import repository",
+					zap.String("link", link),
+					zap.String("chatId", chatID),
+					zap.Error(err))
+				result.Status = server_model.ImportStatusFailed
+				result.Reason = err.Error()
+			}
+			results = append(results, result)
+		}
+		return nil
+	})
+	return results, err
+}
+
 func (r *GormServerRepo) RemoveNotificationRep(ctx context.Context, trackingRepo *server_model.TrackingRepo) error {
 	if trackingRepo == nil {
 		return fmt.Errorf("tracking repo is nil")
@@ -81,6 +149,188 @@ func (r *GormServerRepo) RemoveNotificationRep(ctx context.Context, trackingRepo
 	})
 }
 
+func (r *GormServerRepo) PauseNotificationRep(ctx context.Context, pause *server_model.PauseTrackingRepo) error {
+	if pause == nil {
+		return fmt.Errorf("tracking repo is nil")
+	}
+	return r.updateExistingNotification(ctx, &pause.TrackingRepo, map[string]any{
+		"enabled":        false,
+		"paused_until":   pause.PausedUntil,
+		"disable_reason": DisableReasonPaused,
+	})
+}
+
+func (r *GormServerRepo) ResumeNotificationRep(ctx context.Context, trackingRepo *server_model.TrackingRepo) error {
+	if trackingRepo == nil {
+		return fmt.Errorf("tracking repo is nil")
+	}
+	// last_commit is left untouched so the next check picks up everything missed during the pause.
+	return r.updateExistingNotification(ctx, trackingRepo, map[string]any{
+		"enabled":        true,
+		"paused_until":   nil,
+		"disable_reason": nil,
+	})
+}
+
+func (r *GormServerRepo) UpdateNotificationFilters(ctx context.Context, update *server_model.UpdateTrackingFilters) error {
+	if update == nil {
+		return fmt.Errorf("tracking repo is nil")
+	}
+	return r.updateExistingNotification(ctx, &update.TrackingRepo, map[string]any{
+		"filters": update.Filters,
+	})
+}
+
+func (r *GormServerRepo) ResetNotificationCursor(ctx context.Context, reset *server_model.ResetCursor) error {
+	if reset == nil {
+		return fmt.Errorf("tracking repo is nil")
+	}
+	// Without branch heads and last commit the next check lists every branch by time from cursor_since.
+	return r.updateExistingNotification(ctx, &reset.TrackingRepo, map[string]any{
+		"branch_heads": StringMap{},
+		"branch_since": TimeMap{},
+		"last_commit":  nil,
+		"cursor_since": reset.Since,
+	})
+}
+
+func (r *GormServerRepo) updateExistingNotification(ctx context.Context, trackingRepo *server_model.TrackingRepo, values map[string]any) error {
+	return r.gorm.WithContext(ctx).Transaction(func(tx *gormio.DB) error {
+		userID, err := resolveUserID(ctx, tx, trackingRepo.ChatID)
+		if err != nil {
+			return err
+		}
+		repoID, err := findRepoIDByLink(ctx, tx, trackingRepo.Link)
+		if err != nil {
+			return err
+		}
+		result := tx.Model(&Notification{}).
+			Where("user_id = ? AND repo_id = ?", userID, repoID).
+			Updates(values)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return errs.ErrRepoNotFound
+		}
+		return nil
+	})
+}
+
+func (r *GormServerRepo) ListNotificationReps(ctx context.Context, chatID string, afterID int, limit int) ([]*server_model.TrackingRepoInfo, error) {
+	var infos []*server_model.TrackingRepoInfo
+	err := r.gorm.WithContext(ctx).Transaction(func(tx *gormio.DB) error {
+		userID, err := resolveUserID(ctx, tx, chatID)
+		if err != nil {
+			return err
+		}
+		items, err := gormio.G[Notification](tx).
+			Where("user_id = ? AND id > ?", userID, afterID).
+			Preload("Repo", func(db gormio.PreloadBuilder) error { return nil }).
+			Preload("LastCommitEntity", func(db gormio.PreloadBuilder) error { return nil }).
+			Preload("LastCommitEntity.Branch", func(db gormio.PreloadBuilder) error { return nil }).
+			Order("id").
+			Limit(limit).
+			Find(ctx)
+		if err != nil {
+			return err
+		}
+		infos = make([]*server_model.TrackingRepoInfo, 0, len(items))
+		for i := range items {
+			infos = append(infos, convertNotificationToInfo(&items[i]))
+		}
+		return nil
+	})
+	return infos, err
+}
+
+func (r *GormServerRepo) ListRepoCommits(ctx context.Context, query *server_model.RepoHistoryQuery, after *server_model.HistoryCursor, limit int) ([]*server_model.CommitInfo, error) {
+	var infos []*server_model.CommitInfo
+	err := r.gorm.WithContext(ctx).Transaction(func(tx *gormio.DB) error {
+		userID, err := resolveUserID(ctx, tx, query.ChatID)
+		if err != nil {
+			return err
+		}
+		repoID, err := findRepoIDByLink(ctx, tx, query.Link)
+		if err != nil {
+			return err
+		}
+		_, err = gormio.G[Notification](tx).
+			Where("user_id = ? AND repo_id = ?", userID, repoID).
+			First(ctx)
+		if err != nil {
+			if errors.Is(err, gormio.ErrRecordNotFound) {
+				return errs.ErrRepoNotFound
+			}
+			return err
+		}
+
+		commitsQuery := gormio.G[Commit](tx).
+			Where("repo_id = ? AND dropped_at IS NULL", repoID)
+		if query.Since != nil {
+			commitsQuery = commitsQuery.Where("created_at >= ?", *query.Since)
+		}
+		if query.Until != nil {
+			commitsQuery = commitsQuery.Where("created_at < ?", *query.Until)
+		}
+		if query.Author != "" {
+			commitsQuery = commitsQuery.Where("LOWER(author_name) = LOWER(?)", query.Author)
+		}
+		if query.Branch != "" {
+			commitsQuery = commitsQuery.Where("id IN (SELECT commit_branches.commit_id FROM commit_branches JOIN branches ON branches.id = commit_branches.branch_id WHERE branches.repo_id = ? AND branches.name = ?)", repoID, query.Branch)
+		}
+		if after != nil {
+			commitsQuery = commitsQuery.Where("(created_at, id) < (?, ?)", after.CommittedAt, after.ID)
+		}
+		items, err := commitsQuery.
+			Preload("Branch", func(db gormio.PreloadBuilder) error { return nil }).
+			Order("created_at DESC, id DESC").
+			Limit(limit).
+			Find(ctx)
+		if err != nil {
+			return err
+		}
+		infos = make([]*server_model.CommitInfo, 0, len(items))
+		for i := range items {
+			infos = append(infos, convertCommitToInfo(&items[i]))
+		}
+		return nil
+	})
+	return infos, err
+}
+
+func convertCommitToInfo(commit *Commit) *server_model.CommitInfo {
+	info := &server_model.CommitInfo{
+		ID:          commit.ID,
+		Hash:        derefString(commit.CommitHash),
+		Message:     derefString(commit.Message),
+		Author:      derefString(commit.AuthorName),
+		CommittedAt: commit.CreatedAt,
+	}
+	if commit.Branch != nil {
+		info.Branch = commit.Branch.Name
+	}
+	return info
+}
+
+func convertNotificationToInfo(notification *Notification) *server_model.TrackingRepoInfo {
+	info := &server_model.TrackingRepoInfo{
+		ID:          notification.ID,
+		Link:        notification.Repo.URL,
+		Owner:       derefString(notification.Repo.Owner),
+		Name:        derefString(notification.Repo.Name),
+		Enabled:     notification.Enabled,
+		PausedUntil: notification.PausedUntil,
+		Branches:    notification.Branches,
+		Filters:     notification.Filters,
+		CreatedAt:   notification.CreatedAt,
+	}
+	if notification.LastCommitEntity != nil {
+		info.LastCommit = convertCommitToInfo(notification.LastCommitEntity)
+	}
+	return info
+}
+
 func resolveUserID(ctx context.Context, tx *gormio.DB, chatID string) (int, error) {
 	if chatID == "" {
 		return 0, fmt.Errorf("user chat_id is required")
@@ -101,57 +351,58 @@ func resolveRepoID(ctx context.Context, tx *gormio.DB, link string) (int, error)
 	if link == "" {
 		return 0, fmt.Errorf("repo url is required")
 	}
-	
-	zap.L().Debug("Looking for repository", 
-		zap.String("link", link), 
-		zap.String("linkWithGit", link+".git"))
-	
-	repo, err := gormio.G[Repo](tx).
-		Where("url = ? OR url = ?", link, link+".git").
-		First(ctx)
-	
+	parsed, err := repolink.Parse(link)
+	if err != nil {
+		return 0, err
+	}
+
+	repo, err := findRepo(ctx, tx, parsed)
 	if err == nil {
-		zap.L().Debug("Repository found", 
-			zap.String("link", link), 
+		zap.L().Debug("Repository found",
+			zap.String("link", parsed.String()),
 			zap.Int("repoId", repo.ID))
 		return repo.ID, nil
 	}
-	
 	if !errors.Is(err, gormio.ErrRecordNotFound) {
-		zap.L().Error("Database error while searching for repository", 
-			zap.String("link", link), 
+		zap.L().Error("Database error while searching for repository",
+			zap.String("link", parsed.String()),
 			zap.Error(err))
 		return 0, err
 	}
-	
-	zap.L().Warn("Repository not found, will create new one", 
-		zap.String("link", link))
 
-	owner, name := parseOwnerRepoFromLink(link)
-	zap.L().Debug("Parsed owner and name", 
-		zap.String("owner", owner), 
-		zap.String("name", name))
-	
 	newRepo := Repo{
-		URL:   link,
-		Owner: ptrString(owner),
-		Name:  ptrString(name),
+		URL:   parsed.String(),
+		Owner: ptrString(parsed.Owner),
+		Name:  ptrString(parsed.Name),
 	}
-	
 	if err := gormio.G[Repo](tx).Create(ctx, &newRepo); err != nil {
-		zap.L().Error("Failed to create repository", 
-			zap.String("link", link), 
+		zap.L().Error("Failed to create repository",
+			zap.String("link", parsed.String()),
 			zap.Error(err))
 		return 0, err
 	}
-	
-	zap.L().Info("Repository created successfully", 
-		zap.String("link", link), 
+
+	zap.L().Info("Repository created successfully",
+		zap.String("link", parsed.String()),
 		zap.Int("repoId", newRepo.ID))
-	
 	return newRepo.ID, nil
 }
 
+func findRepoIDByLink(ctx context.Context, tx *gormio.DB, link string) (int, error) {
+	parsed, err := repolink.Parse(link)
+	if err != nil {
+		return 0, err
+	}
+	repo, err := findRepo(ctx, tx, parsed)
+	if err != nil {
+		if errors.Is(err, gormio.ErrRecordNotFound) {
+			return 0, errs.ErrRepoNotFound
+		}
+		return 0, err
+	}
+	return repo.ID, nil
+}
+
 func ensureUserRepo(ctx context.Context, tx *gormio.DB, userID int, repoID int) error {
 	_, err := gormio.G[UserRepo](tx).
 		Where("user_id = ? AND repo_id = ?", userID, repoID).
@@ -166,20 +417,9 @@ func ensureUserRepo(ctx context.Context, tx *gormio.DB, userID int, repoID int)
 	return gormio.G[UserRepo](tx).Create(ctx, &newLink)
 }
 
-func parseOwnerRepoFromLink(raw string) (string, string) {
-	if raw == "" {
-		return "", ""
-	}
-	u, err := url.Parse(raw)
-	if err != nil {
-		return "", ""
-	}
-	path := strings.Trim(u.Path, "/")
-	parts := strings.Split(path, "/")
-	if len(parts) < 2 {
-		return "", ""
+func derefString(v *string) string {
+	if v == nil {
+		return ""
 	}
-	owner := parts[0]
-	repo := strings.TrimSuffix(parts[1], ".git")
-	return owner, repo
+	return *v
 }
@@ -0,0 +1,69 @@
+package gorm
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+
+	"rep_tracker/internal/server_model"
+	"rep_tracker/pkg/forge"
+)
+
+func TestListRepoCommitsByBranch(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	const (
+		chatID = "1001"
+		link   = "https://github.com/owner/repo"
+	)
+	createTestUser(t, db, chatID)
+	serverRepo := NewGormServerRepo(db)
+	if err := serverRepo.AddNotificationRep(ctx, &server_model.TrackingRepo{ChatID: chatID, Link: link, Branches: []string{"main", "feature"}}); err != nil {
+		t.Fatal(err)
+	}
+	scheduler := NewGormSchedulerRepo(db)
+	tracked, err := scheduler.GetTrackingRepos(ctx, 0, 1)
+	if err != nil || len(tracked) != 1 {
+		t.Fatalf("tracking repos = %v, %v", tracked, err)
+	}
+	repoID := tracked[0].RepoID
+
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	commit := func(sha string, minutes int) *forge.Commit {
+		return &forge.Commit{SHA: sha, Message: sha, CommittedAt: base.Add(time.Duration(minutes) * time.Minute)}
+	}
+	// b is shared: it is stored from main and seen again when feature branches off it.
+	if err := scheduler.SaveCommits(ctx, repoID, "main", commit("b", 2), commit("a", 1)); err != nil {
+		t.Fatal(err)
+	}
+	if err := scheduler.SaveCommits(ctx, repoID, "feature", commit("c", 3), commit("b", 2)); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		branch string
+		want   []string
+	}{
+		{name: "all branches", want: []string{"c", "b", "a"}},
+		{name: "first seen branch", branch: "main", want: []string{"b", "a"}},
+		{name: "commit first seen on another branch", branch: "feature", want: []string{"c", "b"}},
+		{name: "unknown branch", branch: "gone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			infos, err := serverRepo.ListRepoCommits(ctx, &server_model.RepoHistoryQuery{ChatID: chatID, Link: link, Branch: tt.branch}, nil, 10)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var got []string
+			for _, info := range infos {
+				got = append(got, info.Hash)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("commits = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
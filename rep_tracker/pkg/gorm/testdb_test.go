@@ -0,0 +1,97 @@
+package gorm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/driver/postgres"
+	gormio "gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// changesDir holds the Liquibase changes of the schema, applied in file name order.
+const changesDir = "../../../Server/src/main/resources/db/changelog/changes"
+
+// openTestDB returns a database with the current schema in a schema of its own, dropped when the test
+// ends. The Postgres server is taken from TEST_DB_DSN; without it the test is skipped.
+func openTestDB(t *testing.T) *gormio.DB {
+	t.Helper()
+	dsn := os.Getenv("TEST_DB_DSN")
+	if dsn == "" {
+		t.Skip("TEST_DB_DSN is not set")
+	}
+	config := &gormio.Config{Logger: logger.Discard}
+	admin, err := gormio.Open(postgres.Open(dsn), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema := fmt.Sprintf("rep_tracker_test_%d", time.Now().UnixNano())
+	if err := admin.Exec("CREATE SCHEMA " + schema).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	db, err := gormio.Open(postgres.Open(withSearchPath(dsn, schema)), config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+		if err := admin.Exec("DROP SCHEMA " + schema + " CASCADE").Error; err != nil {
+			t.Errorf("drop test schema: %v", err)
+		}
+		if sqlDB, err := admin.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	files, err := filepath.Glob(filepath.Join(changesDir, "*.sql"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	slices.Sort(files)
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, file := range files {
+		raw, err := os.ReadFile(file)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// Without arguments the file is sent as one simple query, so it may hold several statements.
+		if _, err := sqlDB.ExecContext(context.Background(), string(raw)); err != nil {
+			t.Fatalf("apply %v: %v", filepath.Base(file), err)
+		}
+	}
+	return db
+}
+
+// withSearchPath points connections of dsn, in URL or key/value form, at schema.
+func withSearchPath(dsn string, schema string) string {
+	if strings.Contains(dsn, "://") {
+		separator := "?"
+		if strings.Contains(dsn, "?") {
+			separator = "&"
+		}
+		return dsn + separator + "search_path=" + schema
+	}
+	return dsn + " search_path=" + schema
+}
+
+// createTestUser stores a user with the chat id and returns its id.
+func createTestUser(t *testing.T, db *gormio.DB, chatID string) int {
+	t.Helper()
+	user := User{ChatID: chatID}
+	if err := db.Create(&user).Error; err != nil {
+		t.Fatal(err)
+	}
+	return user.ID
+}
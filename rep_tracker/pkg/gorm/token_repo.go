@@ -2,33 +2,178 @@ package gorm
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"rep_tracker/pkg/errs"
+	"service_common/tokencrypt"
+	"time"
 
 	gormio "gorm.io/gorm"
 )
 
 type GormTokenRepo struct {
 	gorm *gormio.DB
+	// keyring seals stored tokens; without it tokens are written in plaintext.
+	keyring *tokencrypt.Keyring
 }
 
-func NewGormTokenRepo(gorm *gormio.DB) *GormTokenRepo {
-	return &GormTokenRepo{gorm: gorm}
+func NewGormTokenRepo(gorm *gormio.DB, keyring *tokencrypt.Keyring) *GormTokenRepo {
+	return &GormTokenRepo{gorm: gorm, keyring: keyring}
 }
 
-func (r *GormTokenRepo) GetToken(ctx context.Context, chatId string) (string, error) {
+// GetToken returns the user's token for the GitHub host.
+func (r *GormTokenRepo) GetToken(ctx context.Context, chatId string, host string) (string, error) {
 	var token Token
 	err := r.gorm.WithContext(ctx).Transaction(func(tx *gormio.DB) error {
 		var err error
 		err = tx.Table("tokens").
 			Select("tokens.*").
 			Joins("INNER JOIN users ON users.id = tokens.user_id").
-			Where("users.chat_id = ?", chatId).
+			Where("users.chat_id = ? AND tokens.host = ?", chatId, host).
 			Order("tokens.created_at DESC").
 			Limit(1).
 			First(&token).Error
 		return err
 	})
 	if err != nil {
+		if errors.Is(err, gormio.ErrRecordNotFound) {
+			return "", errs.ErrTokenMissing
+		}
 		return "", err
 	}
-	return token.Token, nil
+	return r.keyring.Open(sealedToken(token))
+}
+
+// SaveValidatedToken stores token as the user's current token for the host (if not empty) and records
+// the validation time. Subscriptions on the host disabled because of an invalid token are re-enabled
+// in the same transaction, the number of re-enabled subscriptions is returned.
+func (r *GormTokenRepo) SaveValidatedToken(ctx context.Context, chatId string, host string, token string, validatedAt time.Time) (int, error) {
+	var reenabled int
+	err := r.gorm.WithContext(ctx).Transaction(func(tx *gormio.DB) error {
+		userID, err := resolveUserID(ctx, tx, chatId)
+		if err != nil {
+			return err
+		}
+		values := map[string]any{"last_validate_at": validatedAt}
+		var sealed tokencrypt.Sealed
+		if token != "" {
+			sealed, err = r.seal(token)
+			if err != nil {
+				return err
+			}
+			values["token"] = sealed.Token
+			values["key_id"] = sealed.KeyID
+			values["data_key"] = sealed.DataKey
+		}
+		result := tx.Model(&Token{}).Where("user_id = ? AND host = ?", userID, host).Updates(values)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			if token == "" {
+				return errs.ErrTokenMissing
+			}
+			err = gormio.G[Token](tx).Create(ctx, &Token{
+				UserID:         userID,
+				Host:           host,
+				Token:          sealed.Token,
+				KeyID:          sealed.KeyID,
+				DataKey:        sealed.DataKey,
+				LastValidateAt: &validatedAt,
+			})
+			if err != nil {
+				return err
+			}
+		} else if token == "" {
+			// The Java server stores new tokens in plaintext before asking for validation; seal them now.
+			if err := r.sealStored(ctx, tx, userID, host); err != nil {
+				return err
+			}
+		}
+		result = tx.Model(&Notification{}).
+			Where("user_id = ? AND enabled = ? AND disable_reason = ?", userID, false, DisableReasonInvalidToken).
+			Where(reposOnHost(host)).
+			Updates(map[string]any{
+				"enabled":        true,
+				"disable_reason": nil,
+			})
+		reenabled = int(result.RowsAffected)
+		return result.Error
+	})
+	return reenabled, err
+}
+
+// ReencryptTokens seals plaintext tokens and tokens sealed under a retired master key with the active key.
+// It returns the number of rewritten rows.
+func (r *GormTokenRepo) ReencryptTokens(ctx context.Context, batchSize int) (int, error) {
+	if r.keyring == nil {
+		return 0, errors.New("token encryption is not configured")
+	}
+	rewritten := 0
+	lastID := 0
+	for {
+		tokens, err := gormio.G[Token](r.gorm).
+			Where("id > ? AND (key_id IS NULL OR key_id <> ?)", lastID, r.keyring.ActiveKeyID()).
+			Order("id").
+			Limit(batchSize).
+			Find(ctx)
+		if err != nil {
+			return rewritten, err
+		}
+		if len(tokens) == 0 {
+			return rewritten, nil
+		}
+		for _, token := range tokens {
+			lastID = token.ID
+			if err := r.reencrypt(ctx, r.gorm, token); err != nil {
+				return rewritten, fmt.Errorf("token %v: %w", token.ID, err)
+			}
+			rewritten++
+		}
+	}
+}
+
+// sealStored seals the user's token on the host if it is stored in plaintext.
+func (r *GormTokenRepo) sealStored(ctx context.Context, tx *gormio.DB, userID int, host string) error {
+	if r.keyring == nil {
+		return nil
+	}
+	token, err := gormio.G[Token](tx).Where("user_id = ? AND host = ? AND key_id IS NULL", userID, host).First(ctx)
+	if err != nil {
+		if errors.Is(err, gormio.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	return r.reencrypt(ctx, tx, token)
+}
+
+func (r *GormTokenRepo) reencrypt(ctx context.Context, tx *gormio.DB, token Token) error {
+	plaintext, err := r.keyring.Open(sealedToken(token))
+	if err != nil {
+		return err
+	}
+	sealed, err := r.keyring.Seal(plaintext)
+	if err != nil {
+		return err
+	}
+	// Matching the old ciphertext keeps a concurrent token update from being overwritten.
+	return tx.WithContext(ctx).Model(&Token{}).
+		Where("id = ? AND token = ?", token.ID, token.Token).
+		Updates(map[string]any{
+			"token":    sealed.Token,
+			"key_id":   sealed.KeyID,
+			"data_key": sealed.DataKey,
+		}).Error
+}
+
+func (r *GormTokenRepo) seal(token string) (tokencrypt.Sealed, error) {
+	if r.keyring == nil {
+		return tokencrypt.Sealed{Token: token}, nil
+	}
+	return r.keyring.Seal(token)
+}
+
+func sealedToken(token Token) tokencrypt.Sealed {
+	return tokencrypt.Sealed{Token: token.Token, KeyID: token.KeyID, DataKey: token.DataKey}
 }
@@ -0,0 +1,190 @@
+package gorm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"rep_tracker/pkg/errs"
+	"service_common/tokencrypt"
+
+	gormio "gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+func testKeyring(t *testing.T, active string) *tokencrypt.Keyring {
+	t.Helper()
+	keyring, err := tokencrypt.NewKeyring(active, map[string][]byte{
+		"old": bytes.Repeat([]byte{1}, 32),
+		"new": bytes.Repeat([]byte{2}, 32),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func storedToken(t *testing.T, db *gormio.DB, userID int, host string) Token {
+	t.Helper()
+	token, err := gormio.G[Token](db).Where("user_id = ? AND host = ?", userID, host).First(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestSaveValidatedToken(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	const chatID = "1001"
+	userID := createTestUser(t, db, chatID)
+	tokens := NewGormTokenRepo(db, testKeyring(t, "new"))
+	validatedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	if _, err := tokens.GetToken(ctx, chatID, "github.com"); !errors.Is(err, errs.ErrTokenMissing) {
+		t.Errorf("token before saving: err = %v, want %v", err, errs.ErrTokenMissing)
+	}
+	if _, err := tokens.SaveValidatedToken(ctx, chatID, "github.com", "", validatedAt); !errors.Is(err, errs.ErrTokenMissing) {
+		t.Errorf("validation without a token: err = %v, want %v", err, errs.ErrTokenMissing)
+	}
+
+	if _, err := tokens.SaveValidatedToken(ctx, chatID, "github.com", "ghp_first", validatedAt); err != nil {
+		t.Fatal(err)
+	}
+	stored := storedToken(t, db, userID, "github.com")
+	if stored.Token == "ghp_first" || stored.KeyID == nil || *stored.KeyID != "new" {
+		t.Errorf("stored token = %v key %v, want it sealed with the active key", stored.Token, stored.KeyID)
+	}
+	if got, err := tokens.GetToken(ctx, chatID, "github.com"); err != nil || got != "ghp_first" {
+		t.Errorf("GetToken = %q, %v, want %q", got, err, "ghp_first")
+	}
+
+	// A new token replaces the stored one.
+	if _, err := tokens.SaveValidatedToken(ctx, chatID, "github.com", "ghp_second", validatedAt); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := tokens.GetToken(ctx, chatID, "github.com"); err != nil || got != "ghp_second" {
+		t.Errorf("GetToken = %q, %v, want %q", got, err, "ghp_second")
+	}
+
+	// A plaintext token stored by an older server is sealed when it is validated.
+	if err := db.Omit(clause.Associations).Create(&Token{UserID: userID, Host: "gitlab.com", Token: "glpat_plain"}).Error; err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tokens.SaveValidatedToken(ctx, chatID, "gitlab.com", "", validatedAt); err != nil {
+		t.Fatal(err)
+	}
+	stored = storedToken(t, db, userID, "gitlab.com")
+	if stored.KeyID == nil || stored.Token == "glpat_plain" {
+		t.Errorf("validated plaintext token = %v key %v, want it sealed", stored.Token, stored.KeyID)
+	}
+	if stored.LastValidateAt == nil || !stored.LastValidateAt.Equal(validatedAt) {
+		t.Errorf("last validate at = %v, want %v", stored.LastValidateAt, validatedAt)
+	}
+	if got, err := tokens.GetToken(ctx, chatID, "gitlab.com"); err != nil || got != "glpat_plain" {
+		t.Errorf("GetToken = %q, %v, want %q", got, err, "glpat_plain")
+	}
+}
+
+func TestSaveValidatedTokenReenables(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	const chatID = "1001"
+	userID := createTestUser(t, db, chatID)
+	github := Repo{URL: "https://github.com/owner/repo"}
+	gitlab := Repo{URL: "https://gitlab.com/group/repo"}
+	for _, repo := range []*Repo{&github, &gitlab} {
+		if err := db.Omit(clause.Associations).Create(repo).Error; err != nil {
+			t.Fatal(err)
+		}
+		notification := Notification{UserID: userID, RepoID: repo.ID, Enabled: true}
+		if err := db.Omit(clause.Associations).Create(&notification).Error; err != nil {
+			t.Fatal(err)
+		}
+		// enabled has a default, so false is only stored by an update.
+		err := db.Model(&notification).Updates(map[string]any{"enabled": false, "disable_reason": DisableReasonInvalidToken}).Error
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reenabled, err := NewGormTokenRepo(db, nil).SaveValidatedToken(ctx, chatID, "github.com", "ghp_token", time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reenabled != 1 {
+		t.Errorf("reenabled = %v, want 1", reenabled)
+	}
+	// Without a keyring the token is stored in plaintext.
+	if stored := storedToken(t, db, userID, "github.com"); stored.Token != "ghp_token" || stored.KeyID != nil {
+		t.Errorf("stored token = %v key %v, want it in plaintext", stored.Token, stored.KeyID)
+	}
+	notifications, err := gormio.G[Notification](db).Order("repo_id").Find(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range notifications {
+		if want := n.RepoID == github.ID; n.Enabled != want {
+			t.Errorf("notification on repo %v enabled = %v, want %v", n.RepoID, n.Enabled, want)
+		}
+	}
+}
+
+func TestReencryptTokens(t *testing.T) {
+	db := openTestDB(t)
+	ctx := context.Background()
+	const chatID = "1001"
+	userID := createTestUser(t, db, chatID)
+
+	old, err := testKeyring(t, "old").Seal("ghp_old")
+	if err != nil {
+		t.Fatal(err)
+	}
+	current, err := testKeyring(t, "new").Seal("ghp_current")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rows := []Token{
+		{UserID: userID, Host: "github.com", Token: "ghp_plain"},
+		{UserID: userID, Host: "ghe.test", Token: old.Token, KeyID: old.KeyID, DataKey: old.DataKey},
+		{UserID: userID, Host: "gitlab.com", Token: current.Token, KeyID: current.KeyID, DataKey: current.DataKey},
+	}
+	if err := db.Omit(clause.Associations).Create(&rows).Error; err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewGormTokenRepo(db, nil).ReencryptTokens(ctx, 1); err == nil {
+		t.Error("reencrypt without a keyring: err = nil, want an error")
+	}
+
+	tokens := NewGormTokenRepo(db, testKeyring(t, "new"))
+	// A batch smaller than the rows to rewrite checks the paging.
+	rewritten, err := tokens.ReencryptTokens(ctx, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rewritten != 2 {
+		t.Errorf("rewritten = %v, want 2", rewritten)
+	}
+	if stored := storedToken(t, db, userID, "gitlab.com"); stored.Token != current.Token {
+		t.Error("token sealed with the active key was rewritten")
+	}
+	for host, want := range map[string]string{"github.com": "ghp_plain", "ghe.test": "ghp_old", "gitlab.com": "ghp_current"} {
+		if stored := storedToken(t, db, userID, host); stored.KeyID == nil || *stored.KeyID != "new" {
+			t.Errorf("token on %v key = %v, want %q", host, stored.KeyID, "new")
+		}
+		if got, err := tokens.GetToken(ctx, chatID, host); err != nil || got != want {
+			t.Errorf("GetToken(%v) = %q, %v, want %q", host, got, err, want)
+		}
+	}
+
+	rewritten, err = tokens.ReencryptTokens(ctx, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rewritten != 0 {
+		t.Errorf("second run rewritten = %v, want 0", rewritten)
+	}
+}
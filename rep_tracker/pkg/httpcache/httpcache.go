@@ -0,0 +1,183 @@
+// Package httpcache makes GET requests conditional on the ETag or Last-Modified of the previous
+// response, replaying the stored body when the server answers 304 Not Modified.
+package httpcache
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"rep_tracker/pkg/metrics"
+
+	"go.uber.org/zap"
+)
+
+// maxBodySize bounds the responses that are stored; larger ones are passed through uncached.
+const maxBodySize = 4 << 20
+
+// Entry is a stored response.
+type Entry struct {
+	ETag         string
+	LastModified string
+	ContentType  string
+	// Link is kept for pagination, 304 responses do not carry it.
+	Link      string
+	Body      []byte
+	UpdatedAt time.Time
+}
+
+// Store keeps entries per credential key and URL. Get returns nil when there is no entry.
+// Touch marks an entry as still current, so it is neither expired nor evicted while the server
+// keeps answering 304 for it.
+type Store interface {
+	Get(ctx context.Context, key string, url string) (*Entry, error)
+	Put(ctx context.Context, key string, url string, entry *Entry) error
+	Touch(ctx context.Context, key string, url string, updatedAt time.Time) error
+}
+
+// Transport serves GET requests of one credential through the store.
+// Key identifies the credential without revealing it, e.g. a token digest.
+type Transport struct {
+	Store Store
+	Key   string
+	Base  http.RoundTripper
+}
+
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method != http.MethodGet {
+		return t.Base.RoundTrip(req)
+	}
+	url := req.URL.String()
+	entry, err := t.Store.Get(req.Context(), t.Key, url)
+	if err != nil {
+		zap.L().Debug("http cache read failed", zap.String("url", url), zap.Error(err))
+		entry = nil
+	}
+	if entry != nil {
+		req = req.Clone(req.Context())
+		if entry.ETag != "" {
+			req.Header.Set("If-None-Match", entry.ETag)
+		}
+		if entry.LastModified != "" {
+			req.Header.Set("If-Modified-Since", entry.LastModified)
+		}
+	}
+	resp, err := t.Base.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusNotModified && entry != nil {
+		metrics.HTTPCacheRequests.WithLabelValues("hit").Inc()
+		if err := t.Store.Touch(req.Context(), t.Key, url, time.Now().UTC()); err != nil {
+			zap.L().Debug("http cache touch failed", zap.String("url", url), zap.Error(err))
+		}
+		return replay(resp, entry), nil
+	}
+	metrics.HTTPCacheRequests.WithLabelValues("miss").Inc()
+	if resp.StatusCode != http.StatusOK {
+		return resp, nil
+	}
+	etag, lastModified := resp.Header.Get("ETag"), resp.Header.Get("Last-Modified")
+	if etag == "" && lastModified == "" {
+		return resp, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBodySize+1))
+	if err != nil {
+		resp.Body.Close()
+		return nil, err
+	}
+	if len(body) > maxBodySize {
+		resp.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.MultiReader(bytes.NewReader(body), resp.Body), resp.Body}
+		return resp, nil
+	}
+	resp.Body.Close()
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+	err = t.Store.Put(req.Context(), t.Key, url, &Entry{
+		ETag:         etag,
+		LastModified: lastModified,
+		ContentType:  resp.Header.Get("Content-Type"),
+		Link:         resp.Header.Get("Link"),
+		Body:         body,
+		UpdatedAt:    time.Now().UTC(),
+	})
+	if err != nil {
+		zap.L().Debug("http cache write failed", zap.String("url", url), zap.Error(err))
+	}
+	return resp, nil
+}
+
+// replay turns a 304 into the stored 200 response, keeping the fresh headers (e.g. rate limits).
+func replay(resp *http.Response, entry *Entry) *http.Response {
+	resp.Body.Close()
+	replayed := *resp
+	replayed.StatusCode = http.StatusOK
+	replayed.Status = "200 OK"
+	replayed.Header = resp.Header.Clone()
+	if entry.ContentType != "" {
+		replayed.Header.Set("Content-Type", entry.ContentType)
+	}
+	if entry.Link != "" && replayed.Header.Get("Link") == "" {
+		replayed.Header.Set("Link", entry.Link)
+	}
+	replayed.Header.Set("Content-Length", strconv.Itoa(len(entry.Body)))
+	replayed.ContentLength = int64(len(entry.Body))
+	replayed.Body = io.NopCloser(bytes.NewReader(entry.Body))
+	return &replayed
+}
+
+// MemoryStore is a Store kept in process memory, evicting the oldest entries beyond its size.
+type MemoryStore struct {
+	mx         sync.Mutex
+	maxEntries int
+	entries    map[string]*Entry
+}
+
+func NewMemoryStore(maxEntries int) *MemoryStore {
+	return &MemoryStore{maxEntries: maxEntries, entries: make(map[string]*Entry)}
+}
+
+func (s *MemoryStore) Get(_ context.Context, key string, url string) (*Entry, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	return s.entries[key+" "+url], nil
+}
+
+func (s *MemoryStore) Put(_ context.Context, key string, url string, entry *Entry) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if _, ok := s.entries[key+" "+url]; !ok && len(s.entries) >= s.maxEntries {
+		s.evictOldest()
+	}
+	s.entries[key+" "+url] = entry
+	return nil
+}
+
+func (s *MemoryStore) Touch(_ context.Context, key string, url string, updatedAt time.Time) error {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if entry, ok := s.entries[key+" "+url]; ok {
+		// Entries may be held by callers of Get, so the touched one is a copy.
+		touched := *entry
+		touched.UpdatedAt = updatedAt
+		s.entries[key+" "+url] = &touched
+	}
+	return nil
+}
+
+func (s *MemoryStore) evictOldest() {
+	var oldestKey string
+	var oldest time.Time
+	for k, entry := range s.entries {
+		if oldestKey == "" || entry.UpdatedAt.Before(oldest) {
+			oldestKey, oldest = k, entry.UpdatedAt
+		}
+	}
+	delete(s.entries, oldestKey)
+}
@@ -0,0 +1,230 @@
+package httpcache
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"rep_tracker/pkg/metrics"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// server answers with body and the validators, and with 304 when the request carries them.
+type server struct {
+	etag         string
+	lastModified string
+	body         string
+	// conditional records the conditional headers of the last request.
+	conditional http.Header
+}
+
+func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.conditional = http.Header{}
+	for _, name := range []string{"If-None-Match", "If-Modified-Since"} {
+		if v := r.Header.Get(name); v != "" {
+			s.conditional.Set(name, v)
+		}
+	}
+	w.Header().Set("X-RateLimit-Remaining", "41")
+	if (s.etag != "" && r.Header.Get("If-None-Match") == s.etag) ||
+		(s.lastModified != "" && r.Header.Get("If-Modified-Since") == s.lastModified) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	if s.etag != "" {
+		w.Header().Set("ETag", s.etag)
+	}
+	if s.lastModified != "" {
+		w.Header().Set("Last-Modified", s.lastModified)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Link", `<https://api.test/next>; rel="next"`)
+	io.WriteString(w, s.body)
+}
+
+func cacheRequests(result string) float64 {
+	return testutil.ToFloat64(metrics.HTTPCacheRequests.WithLabelValues(result))
+}
+
+func get(t *testing.T, client *http.Client, url string) (*http.Response, string) {
+	t.Helper()
+	resp, err := client.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return resp, string(body)
+}
+
+func TestTransportConditional(t *testing.T) {
+	tests := []struct {
+		name            string
+		server          server
+		wantConditional http.Header
+	}{
+		{
+			name:            "etag",
+			server:          server{etag: `"v1"`, body: `[1]`},
+			wantConditional: http.Header{"If-None-Match": {`"v1"`}},
+		},
+		{
+			name:            "last modified",
+			server:          server{lastModified: "Wed, 01 May 2024 10:00:00 GMT", body: `[2]`},
+			wantConditional: http.Header{"If-Modified-Since": {"Wed, 01 May 2024 10:00:00 GMT"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(&tt.server)
+			defer srv.Close()
+			store := NewMemoryStore(10)
+			client := &http.Client{Transport: &Transport{Store: store, Key: "key", Base: http.DefaultTransport}}
+			url := srv.URL + "/repos/owner/repo/commits"
+
+			hits, misses := cacheRequests("hit"), cacheRequests("miss")
+			if _, body := get(t, client, url); body != tt.server.body {
+				t.Fatalf("first body = %q, want %q", body, tt.server.body)
+			}
+			if len(tt.server.conditional) != 0 {
+				t.Errorf("first request conditional headers = %v, want none", tt.server.conditional)
+			}
+			stored, _ := store.Get(context.Background(), "key", url)
+			if stored == nil {
+				t.Fatal("response is not stored")
+			}
+			if got := cacheRequests("miss") - misses; got != 1 {
+				t.Errorf("misses = %v, want 1", got)
+			}
+
+			resp, body := get(t, client, url)
+			if len(tt.server.conditional) != len(tt.wantConditional) ||
+				tt.server.conditional.Get("If-None-Match") != tt.wantConditional.Get("If-None-Match") ||
+				tt.server.conditional.Get("If-Modified-Since") != tt.wantConditional.Get("If-Modified-Since") {
+				t.Errorf("conditional headers = %v, want %v", tt.server.conditional, tt.wantConditional)
+			}
+			if resp.StatusCode != http.StatusOK || body != tt.server.body {
+				t.Errorf("replayed = %v %q, want 200 %q", resp.StatusCode, body, tt.server.body)
+			}
+			if got := resp.Header.Get("Content-Type"); got != "application/json" {
+				t.Errorf("replayed content type = %q, want the stored one", got)
+			}
+			if got := resp.Header.Get("Link"); got != `<https://api.test/next>; rel="next"` {
+				t.Errorf("replayed link = %q, want the stored one", got)
+			}
+			if got := resp.Header.Get("X-RateLimit-Remaining"); got != "41" {
+				t.Errorf("replayed rate limit = %q, want the fresh header", got)
+			}
+			if got := cacheRequests("hit") - hits; got != 1 {
+				t.Errorf("hits = %v, want 1", got)
+			}
+			if got := cacheRequests("miss") - misses; got != 1 {
+				t.Errorf("misses = %v, want still 1", got)
+			}
+			touched, _ := store.Get(context.Background(), "key", url)
+			if !touched.UpdatedAt.After(stored.UpdatedAt) {
+				t.Errorf("updated at = %v after the hit, want later than %v", touched.UpdatedAt, stored.UpdatedAt)
+			}
+		})
+	}
+}
+
+func TestTransportUncached(t *testing.T) {
+	large := strings.Repeat("x", maxBodySize+1)
+	tests := []struct {
+		name   string
+		method string
+		status int
+		etag   string
+		body   string
+	}{
+		{name: "not a get", method: http.MethodPost, status: http.StatusOK, etag: `"v1"`, body: "posted"},
+		{name: "error status", method: http.MethodGet, status: http.StatusNotFound, etag: `"v1"`, body: "missing"},
+		{name: "without validators", method: http.MethodGet, status: http.StatusOK, body: "fresh"},
+		{name: "too large", method: http.MethodGet, status: http.StatusOK, etag: `"v1"`, body: large},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if tt.etag != "" {
+					w.Header().Set("ETag", tt.etag)
+				}
+				w.WriteHeader(tt.status)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+			store := NewMemoryStore(10)
+			transport := &Transport{Store: store, Key: "key", Base: http.DefaultTransport}
+			req, err := http.NewRequest(tt.method, srv.URL, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer resp.Body.Close()
+			body, err := io.ReadAll(resp.Body)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != tt.status || string(body) != tt.body {
+				t.Errorf("response = %v with %v bytes, want %v with %v bytes", resp.StatusCode, len(body), tt.status, len(tt.body))
+			}
+			if entry, _ := store.Get(context.Background(), "key", srv.URL); entry != nil {
+				t.Error("response is stored")
+			}
+		})
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	ctx := context.Background()
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(2)
+	put := func(url string, updatedAt time.Time) {
+		t.Helper()
+		if err := store.Put(ctx, "key", url, &Entry{ETag: url, UpdatedAt: updatedAt}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	put("a", base)
+	put("b", base.Add(time.Minute))
+	// Entries are kept per credential.
+	if entry, _ := store.Get(ctx, "other", "a"); entry != nil {
+		t.Error("entry of another key is returned")
+	}
+
+	// Replacing an entry does not evict.
+	put("b", base.Add(2*time.Minute))
+	if entry, _ := store.Get(ctx, "key", "a"); entry == nil {
+		t.Fatal("a was evicted by replacing b")
+	}
+
+	// a is the oldest, unless it is touched by a hit.
+	if err := store.Touch(ctx, "key", "a", base.Add(3*time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	put("c", base.Add(4*time.Minute))
+	for url, want := range map[string]bool{"a": true, "b": false, "c": true} {
+		if entry, _ := store.Get(ctx, "key", url); (entry != nil) != want {
+			t.Errorf("%v stored = %v, want %v", url, entry != nil, want)
+		}
+	}
+
+	// Touching a missing entry does not create it.
+	if err := store.Touch(ctx, "key", "missing", base); err != nil {
+		t.Fatal(err)
+	}
+	if entry, _ := store.Get(ctx, "key", "missing"); entry != nil {
+		t.Error("touch created an entry")
+	}
+}
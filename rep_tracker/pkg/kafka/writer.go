@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"rep_tracker/pkg/dto"
+	"rep_tracker/pkg/metrics"
 	"time"
 
 	"github.com/segmentio/kafka-go"
@@ -42,10 +43,12 @@ func (kw *KafkaNotificationWriter) WriteNotification(ctx context.Context, chatId
 	if err != nil {
 		return err
 	}
-	return kw.writer.WriteMessages(ctx, kafka.Message{
+	err = kw.writer.WriteMessages(ctx, kafka.Message{
 		Key:   []byte(chatId),
 		Value: dtoBytes,
 	})
+	metrics.KafkaWrites.WithLabelValues(metrics.Result(err)).Inc()
+	return err
 }
 
 func (kw *KafkaNotificationWriter) Close() error {
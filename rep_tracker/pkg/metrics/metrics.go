@@ -0,0 +1,105 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.uber.org/zap"
+)
+
+const namespace = "rep_tracker"
+
+var (
+	CycleDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "check_cycle_duration_seconds",
+		Help:      "Duration of a full commit check cycle.",
+		Buckets:   []float64{1, 5, 10, 30, 60, 120, 300, 600, 1200},
+	})
+	ReposPerCycle = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "check_cycle_repos",
+		Help:      "Number of subscriptions checked by the last cycle.",
+	})
+	ReposChecked = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "repos_checked_total",
+		Help:      "Subscriptions checked across all cycles.",
+	})
+	ReposRateLimited = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "repos_rate_limited_total",
+		Help:      "Subscription checks deferred to a later cycle because the token budget was exhausted.",
+	})
+
+	GithubRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "github_requests_total",
+		Help:      "GitHub API requests by endpoint and response status.",
+	}, []string{"endpoint", "status"})
+	GithubRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "github_request_duration_seconds",
+		Help:      "GitHub API request latency by endpoint.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"endpoint"})
+
+	HTTPCacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "http_cache_requests_total",
+		Help:      "Cacheable API requests by result: hit when answered 304 from the cache, miss otherwise.",
+	}, []string{"result"})
+
+	KafkaWrites = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "kafka_writes_total",
+		Help:      "Notification writes to Kafka by result.",
+	}, []string{"result"})
+
+	GrpcRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "grpc_server_handled_total",
+		Help:      "gRPC calls handled by the server by method and code.",
+	}, []string{"method", "code"})
+	GrpcRequestDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "grpc_server_handling_seconds",
+		Help:      "gRPC call latency by method.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"method"})
+)
+
+// Result returns the result label for an operation error.
+func Result(err error) string {
+	if err != nil {
+		return "failure"
+	}
+	return "success"
+}
+
+// Serve exposes /metrics and the extra routes (e.g. health probes) on addr until ctx is done.
+func Serve(ctx context.Context, addr string, routes map[string]http.Handler) error {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.Handler())
+	for pattern, handler := range routes {
+		mux.Handle(pattern, handler)
+	}
+	server := &http.Server{Addr: addr, Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+	zap.L().Info("metrics server listening", zap.String("addr", addr))
+	if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
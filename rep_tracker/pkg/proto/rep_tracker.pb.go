@@ -10,6 +10,7 @@ import (
 	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
 	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
 	emptypb "google.golang.org/protobuf/types/known/emptypb"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
 	reflect "reflect"
 	sync "sync"
 	unsafe "unsafe"
@@ -22,10 +23,113 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+type RepoVisibility int32
+
+const (
+	RepoVisibility_REPO_VISIBILITY_ALL     RepoVisibility = 0
+	RepoVisibility_REPO_VISIBILITY_PUBLIC  RepoVisibility = 1
+	RepoVisibility_REPO_VISIBILITY_PRIVATE RepoVisibility = 2
+)
+
+// Enum value maps for RepoVisibility.
+var (
+	RepoVisibility_name = map[int32]string{
+		0: "REPO_VISIBILITY_ALL",
+		1: "REPO_VISIBILITY_PUBLIC",
+		2: "REPO_VISIBILITY_PRIVATE",
+	}
+	RepoVisibility_value = map[string]int32{
+		"REPO_VISIBILITY_ALL":     0,
+		"REPO_VISIBILITY_PUBLIC":  1,
+		"REPO_VISIBILITY_PRIVATE": 2,
+	}
+)
+
+func (x RepoVisibility) Enum() *RepoVisibility {
+	p := new(RepoVisibility)
+	*p = x
+	return p
+}
+
+func (x RepoVisibility) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (RepoVisibility) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_rep_tracker_proto_enumTypes[0].Descriptor()
+}
+
+func (RepoVisibility) Type() protoreflect.EnumType {
+	return &file_proto_rep_tracker_proto_enumTypes[0]
+}
+
+func (x RepoVisibility) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use RepoVisibility.Descriptor instead.
+func (RepoVisibility) EnumDescriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{0}
+}
+
+type ImportStatus int32
+
+const (
+	ImportStatus_IMPORT_STATUS_UNSPECIFIED     ImportStatus = 0
+	ImportStatus_IMPORT_STATUS_ADDED           ImportStatus = 1
+	ImportStatus_IMPORT_STATUS_ALREADY_TRACKED ImportStatus = 2
+	ImportStatus_IMPORT_STATUS_FAILED          ImportStatus = 3
+)
+
+// Enum value maps for ImportStatus.
+var (
+	ImportStatus_name = map[int32]string{
+		0: "IMPORT_STATUS_UNSPECIFIED",
+		1: "IMPORT_STATUS_ADDED",
+		2: "IMPORT_STATUS_ALREADY_TRACKED",
+		3: "IMPORT_STATUS_FAILED",
+	}
+	ImportStatus_value = map[string]int32{
+		"IMPORT_STATUS_UNSPECIFIED":     0,
+		"IMPORT_STATUS_ADDED":           1,
+		"IMPORT_STATUS_ALREADY_TRACKED": 2,
+		"IMPORT_STATUS_FAILED":          3,
+	}
+)
+
+func (x ImportStatus) Enum() *ImportStatus {
+	p := new(ImportStatus)
+	*p = x
+	return p
+}
+
+func (x ImportStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (ImportStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_proto_rep_tracker_proto_enumTypes[1].Descriptor()
+}
+
+func (ImportStatus) Type() protoreflect.EnumType {
+	return &file_proto_rep_tracker_proto_enumTypes[1]
+}
+
+func (x ImportStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use ImportStatus.Descriptor instead.
+func (ImportStatus) EnumDescriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{1}
+}
+
 type TrackingRepo struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Link          string                 `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
-	ChatId        string                 `protobuf:"bytes,2,opt,name=chat_id,json=chatId,proto3" json:"chat_id,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Link   string                 `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	ChatId string                 `protobuf:"bytes,2,opt,name=chat_id,json=chatId,proto3" json:"chat_id,omitempty"`
+	// Branch names or glob patterns such as "release/*". Empty means the default branch.
+	Branches      []string `protobuf:"bytes,3,rep,name=branches,proto3" json:"branches,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -74,17 +178,1308 @@ func (x *TrackingRepo) GetChatId() string {
 	return ""
 }
 
-var File_proto_rep_tracker_proto protoreflect.FileDescriptor
+func (x *TrackingRepo) GetBranches() []string {
+	if x != nil {
+		return x.Branches
+	}
+	return nil
+}
 
-const file_proto_rep_tracker_proto_rawDesc = "" +
-	"\n" +
-	"\x17proto/rep_tracker.proto\x12\vrep_tracker\x1a\x1bgoogle/protobuf/empty.proto\";\n" +
-	"\fTrackingRepo\x12\x12\n" +
-	"\x04link\x18\x01 \x01(\tR\x04link\x12\x17\n" +
-	"\achat_id\x18\x02 \x01(\tR\x06chatId2\xa2\x01\n" +
+type PauseTrackingRepoRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Link          string                 `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	ChatId        string                 `protobuf:"bytes,2,opt,name=chat_id,json=chatId,proto3" json:"chat_id,omitempty"`
+	PausedUntil   *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=paused_until,json=pausedUntil,proto3" json:"paused_until,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PauseTrackingRepoRequest) Reset() {
+	*x = PauseTrackingRepoRequest{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PauseTrackingRepoRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PauseTrackingRepoRequest) ProtoMessage() {}
+
+func (x *PauseTrackingRepoRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PauseTrackingRepoRequest.ProtoReflect.Descriptor instead.
+func (*PauseTrackingRepoRequest) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *PauseTrackingRepoRequest) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *PauseTrackingRepoRequest) GetChatId() string {
+	if x != nil {
+		return x.ChatId
+	}
+	return ""
+}
+
+func (x *PauseTrackingRepoRequest) GetPausedUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PausedUntil
+	}
+	return nil
+}
+
+type ResetCursorRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Link   string                 `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	ChatId string                 `protobuf:"bytes,2,opt,name=chat_id,json=chatId,proto3" json:"chat_id,omitempty"`
+	// Commits after this time are reported again by the next check.
+	Since         *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=since,proto3" json:"since,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ResetCursorRequest) Reset() {
+	*x = ResetCursorRequest{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ResetCursorRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ResetCursorRequest) ProtoMessage() {}
+
+func (x *ResetCursorRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ResetCursorRequest.ProtoReflect.Descriptor instead.
+func (*ResetCursorRequest) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *ResetCursorRequest) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *ResetCursorRequest) GetChatId() string {
+	if x != nil {
+		return x.ChatId
+	}
+	return ""
+}
+
+func (x *ResetCursorRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+type TrackingFilters struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Path globs matched against the files each commit touches. "**" matches any number of directories,
+	// "*" and "?" any characters and one character within a directory, "[a-z]" and "[!a-z]" one character of a class.
+	IncludePaths []string `protobuf:"bytes,1,rep,name=include_paths,json=includePaths,proto3" json:"include_paths,omitempty"`
+	ExcludePaths []string `protobuf:"bytes,2,rep,name=exclude_paths,json=excludePaths,proto3" json:"exclude_paths,omitempty"`
+	// GitHub logins, compared case-insensitively.
+	AllowAuthors []string `protobuf:"bytes,3,rep,name=allow_authors,json=allowAuthors,proto3" json:"allow_authors,omitempty"`
+	DenyAuthors  []string `protobuf:"bytes,4,rep,name=deny_authors,json=denyAuthors,proto3" json:"deny_authors,omitempty"`
+	// RE2 regular expression the commit message must match.
+	MessagePattern string `protobuf:"bytes,5,opt,name=message_pattern,json=messagePattern,proto3" json:"message_pattern,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TrackingFilters) Reset() {
+	*x = TrackingFilters{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackingFilters) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackingFilters) ProtoMessage() {}
+
+func (x *TrackingFilters) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackingFilters.ProtoReflect.Descriptor instead.
+func (*TrackingFilters) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *TrackingFilters) GetIncludePaths() []string {
+	if x != nil {
+		return x.IncludePaths
+	}
+	return nil
+}
+
+func (x *TrackingFilters) GetExcludePaths() []string {
+	if x != nil {
+		return x.ExcludePaths
+	}
+	return nil
+}
+
+func (x *TrackingFilters) GetAllowAuthors() []string {
+	if x != nil {
+		return x.AllowAuthors
+	}
+	return nil
+}
+
+func (x *TrackingFilters) GetDenyAuthors() []string {
+	if x != nil {
+		return x.DenyAuthors
+	}
+	return nil
+}
+
+func (x *TrackingFilters) GetMessagePattern() string {
+	if x != nil {
+		return x.MessagePattern
+	}
+	return ""
+}
+
+type UpdateTrackingFiltersRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Link          string                 `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	ChatId        string                 `protobuf:"bytes,2,opt,name=chat_id,json=chatId,proto3" json:"chat_id,omitempty"`
+	Filters       *TrackingFilters       `protobuf:"bytes,3,opt,name=filters,proto3" json:"filters,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateTrackingFiltersRequest) Reset() {
+	*x = UpdateTrackingFiltersRequest{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateTrackingFiltersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateTrackingFiltersRequest) ProtoMessage() {}
+
+func (x *UpdateTrackingFiltersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateTrackingFiltersRequest.ProtoReflect.Descriptor instead.
+func (*UpdateTrackingFiltersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *UpdateTrackingFiltersRequest) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *UpdateTrackingFiltersRequest) GetChatId() string {
+	if x != nil {
+		return x.ChatId
+	}
+	return ""
+}
+
+func (x *UpdateTrackingFiltersRequest) GetFilters() *TrackingFilters {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+type ListTrackingReposRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ChatId        string                 `protobuf:"bytes,1,opt,name=chat_id,json=chatId,proto3" json:"chat_id,omitempty"`
+	PageToken     string                 `protobuf:"bytes,2,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	PageSize      int32                  `protobuf:"varint,3,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrackingReposRequest) Reset() {
+	*x = ListTrackingReposRequest{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrackingReposRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrackingReposRequest) ProtoMessage() {}
+
+func (x *ListTrackingReposRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrackingReposRequest.ProtoReflect.Descriptor instead.
+func (*ListTrackingReposRequest) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListTrackingReposRequest) GetChatId() string {
+	if x != nil {
+		return x.ChatId
+	}
+	return ""
+}
+
+func (x *ListTrackingReposRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *ListTrackingReposRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type CommitInfo struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	Hash        string                 `protobuf:"bytes,1,opt,name=hash,proto3" json:"hash,omitempty"`
+	Message     string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	CommittedAt *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=committed_at,json=committedAt,proto3" json:"committed_at,omitempty"`
+	Author      string                 `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
+	// Tracked branch the commit was first seen on.
+	Branch        string `protobuf:"bytes,5,opt,name=branch,proto3" json:"branch,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CommitInfo) Reset() {
+	*x = CommitInfo{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CommitInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CommitInfo) ProtoMessage() {}
+
+func (x *CommitInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CommitInfo.ProtoReflect.Descriptor instead.
+func (*CommitInfo) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CommitInfo) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+func (x *CommitInfo) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+func (x *CommitInfo) GetCommittedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CommittedAt
+	}
+	return nil
+}
+
+func (x *CommitInfo) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *CommitInfo) GetBranch() string {
+	if x != nil {
+		return x.Branch
+	}
+	return ""
+}
+
+type TrackingRepoInfo struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Link          string                 `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	Owner         string                 `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	Name          string                 `protobuf:"bytes,3,opt,name=name,proto3" json:"name,omitempty"`
+	Enabled       bool                   `protobuf:"varint,4,opt,name=enabled,proto3" json:"enabled,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,5,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	LastCommit    *CommitInfo            `protobuf:"bytes,6,opt,name=last_commit,json=lastCommit,proto3" json:"last_commit,omitempty"`
+	PausedUntil   *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=paused_until,json=pausedUntil,proto3" json:"paused_until,omitempty"`
+	Branches      []string               `protobuf:"bytes,8,rep,name=branches,proto3" json:"branches,omitempty"`
+	Filters       *TrackingFilters       `protobuf:"bytes,9,opt,name=filters,proto3" json:"filters,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrackingRepoInfo) Reset() {
+	*x = TrackingRepoInfo{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrackingRepoInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrackingRepoInfo) ProtoMessage() {}
+
+func (x *TrackingRepoInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrackingRepoInfo.ProtoReflect.Descriptor instead.
+func (*TrackingRepoInfo) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *TrackingRepoInfo) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *TrackingRepoInfo) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *TrackingRepoInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *TrackingRepoInfo) GetEnabled() bool {
+	if x != nil {
+		return x.Enabled
+	}
+	return false
+}
+
+func (x *TrackingRepoInfo) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *TrackingRepoInfo) GetLastCommit() *CommitInfo {
+	if x != nil {
+		return x.LastCommit
+	}
+	return nil
+}
+
+func (x *TrackingRepoInfo) GetPausedUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.PausedUntil
+	}
+	return nil
+}
+
+func (x *TrackingRepoInfo) GetBranches() []string {
+	if x != nil {
+		return x.Branches
+	}
+	return nil
+}
+
+func (x *TrackingRepoInfo) GetFilters() *TrackingFilters {
+	if x != nil {
+		return x.Filters
+	}
+	return nil
+}
+
+type ListTrackingReposResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Repos         []*TrackingRepoInfo    `protobuf:"bytes,1,rep,name=repos,proto3" json:"repos,omitempty"`
+	NextPageToken string                 `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrackingReposResponse) Reset() {
+	*x = ListTrackingReposResponse{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrackingReposResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrackingReposResponse) ProtoMessage() {}
+
+func (x *ListTrackingReposResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrackingReposResponse.ProtoReflect.Descriptor instead.
+func (*ListTrackingReposResponse) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ListTrackingReposResponse) GetRepos() []*TrackingRepoInfo {
+	if x != nil {
+		return x.Repos
+	}
+	return nil
+}
+
+func (x *ListTrackingReposResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type GetRepoHistoryRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	ChatId string                 `protobuf:"bytes,1,opt,name=chat_id,json=chatId,proto3" json:"chat_id,omitempty"`
+	Link   string                 `protobuf:"bytes,2,opt,name=link,proto3" json:"link,omitempty"`
+	Since  *timestamppb.Timestamp `protobuf:"bytes,3,opt,name=since,proto3" json:"since,omitempty"`
+	Until  *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=until,proto3" json:"until,omitempty"`
+	Author string                 `protobuf:"bytes,5,opt,name=author,proto3" json:"author,omitempty"`
+	// Commits seen on this tracked branch, including ones first seen on another.
+	Branch        string `protobuf:"bytes,6,opt,name=branch,proto3" json:"branch,omitempty"`
+	PageToken     string `protobuf:"bytes,7,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	PageSize      int32  `protobuf:"varint,8,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRepoHistoryRequest) Reset() {
+	*x = GetRepoHistoryRequest{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRepoHistoryRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRepoHistoryRequest) ProtoMessage() {}
+
+func (x *GetRepoHistoryRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRepoHistoryRequest.ProtoReflect.Descriptor instead.
+func (*GetRepoHistoryRequest) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *GetRepoHistoryRequest) GetChatId() string {
+	if x != nil {
+		return x.ChatId
+	}
+	return ""
+}
+
+func (x *GetRepoHistoryRequest) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *GetRepoHistoryRequest) GetSince() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Since
+	}
+	return nil
+}
+
+func (x *GetRepoHistoryRequest) GetUntil() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Until
+	}
+	return nil
+}
+
+func (x *GetRepoHistoryRequest) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *GetRepoHistoryRequest) GetBranch() string {
+	if x != nil {
+		return x.Branch
+	}
+	return ""
+}
+
+func (x *GetRepoHistoryRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+func (x *GetRepoHistoryRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+type GetRepoHistoryResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Newest commits first.
+	Commits       []*CommitInfo `protobuf:"bytes,1,rep,name=commits,proto3" json:"commits,omitempty"`
+	NextPageToken string        `protobuf:"bytes,2,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRepoHistoryResponse) Reset() {
+	*x = GetRepoHistoryResponse{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRepoHistoryResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRepoHistoryResponse) ProtoMessage() {}
+
+func (x *GetRepoHistoryResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRepoHistoryResponse.ProtoReflect.Descriptor instead.
+func (*GetRepoHistoryResponse) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *GetRepoHistoryResponse) GetCommits() []*CommitInfo {
+	if x != nil {
+		return x.Commits
+	}
+	return nil
+}
+
+func (x *GetRepoHistoryResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type WatchChangesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Required; only changes of this chat are streamed.
+	ChatId string `protobuf:"bytes,1,opt,name=chat_id,json=chatId,proto3" json:"chat_id,omitempty"`
+	// Resume after this event; 0 starts from new events only.
+	LastEventId   uint64 `protobuf:"varint,2,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchChangesRequest) Reset() {
+	*x = WatchChangesRequest{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchChangesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchChangesRequest) ProtoMessage() {}
+
+func (x *WatchChangesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchChangesRequest.ProtoReflect.Descriptor instead.
+func (*WatchChangesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *WatchChangesRequest) GetChatId() string {
+	if x != nil {
+		return x.ChatId
+	}
+	return ""
+}
+
+func (x *WatchChangesRequest) GetLastEventId() uint64 {
+	if x != nil {
+		return x.LastEventId
+	}
+	return 0
+}
+
+type ChangeEvent struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	EventId       uint64                 `protobuf:"varint,1,opt,name=event_id,json=eventId,proto3" json:"event_id,omitempty"`
+	ChatId        string                 `protobuf:"bytes,2,opt,name=chat_id,json=chatId,proto3" json:"chat_id,omitempty"`
+	Link          string                 `protobuf:"bytes,3,opt,name=link,proto3" json:"link,omitempty"`
+	Author        string                 `protobuf:"bytes,4,opt,name=author,proto3" json:"author,omitempty"`
+	Title         string                 `protobuf:"bytes,5,opt,name=title,proto3" json:"title,omitempty"`
+	Branch        string                 `protobuf:"bytes,6,opt,name=branch,proto3" json:"branch,omitempty"`
+	UpdatedAt     *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ChangeEvent) Reset() {
+	*x = ChangeEvent{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ChangeEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ChangeEvent) ProtoMessage() {}
+
+func (x *ChangeEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ChangeEvent.ProtoReflect.Descriptor instead.
+func (*ChangeEvent) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ChangeEvent) GetEventId() uint64 {
+	if x != nil {
+		return x.EventId
+	}
+	return 0
+}
+
+func (x *ChangeEvent) GetChatId() string {
+	if x != nil {
+		return x.ChatId
+	}
+	return ""
+}
+
+func (x *ChangeEvent) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *ChangeEvent) GetAuthor() string {
+	if x != nil {
+		return x.Author
+	}
+	return ""
+}
+
+func (x *ChangeEvent) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *ChangeEvent) GetBranch() string {
+	if x != nil {
+		return x.Branch
+	}
+	return ""
+}
+
+func (x *ChangeEvent) GetUpdatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return nil
+}
+
+type ImportReposRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	ChatId string                 `protobuf:"bytes,1,opt,name=chat_id,json=chatId,proto3" json:"chat_id,omitempty"`
+	// GitHub user or organization; empty imports everything the token can see.
+	Owner string `protobuf:"bytes,2,opt,name=owner,proto3" json:"owner,omitempty"`
+	// Glob matched against the repository name, e.g. "service-*".
+	NamePattern     string         `protobuf:"bytes,3,opt,name=name_pattern,json=namePattern,proto3" json:"name_pattern,omitempty"`
+	Visibility      RepoVisibility `protobuf:"varint,4,opt,name=visibility,proto3,enum=rep_tracker.RepoVisibility" json:"visibility,omitempty"`
+	IncludeArchived bool           `protobuf:"varint,5,opt,name=include_archived,json=includeArchived,proto3" json:"include_archived,omitempty"`
+	// GitHub host to import from, e.g. "github.example.com"; empty means github.com.
+	Host          string `protobuf:"bytes,6,opt,name=host,proto3" json:"host,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportReposRequest) Reset() {
+	*x = ImportReposRequest{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportReposRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportReposRequest) ProtoMessage() {}
+
+func (x *ImportReposRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportReposRequest.ProtoReflect.Descriptor instead.
+func (*ImportReposRequest) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ImportReposRequest) GetChatId() string {
+	if x != nil {
+		return x.ChatId
+	}
+	return ""
+}
+
+func (x *ImportReposRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *ImportReposRequest) GetNamePattern() string {
+	if x != nil {
+		return x.NamePattern
+	}
+	return ""
+}
+
+func (x *ImportReposRequest) GetVisibility() RepoVisibility {
+	if x != nil {
+		return x.Visibility
+	}
+	return RepoVisibility_REPO_VISIBILITY_ALL
+}
+
+func (x *ImportReposRequest) GetIncludeArchived() bool {
+	if x != nil {
+		return x.IncludeArchived
+	}
+	return false
+}
+
+func (x *ImportReposRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+type ImportRepoResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Link          string                 `protobuf:"bytes,1,opt,name=link,proto3" json:"link,omitempty"`
+	Status        ImportStatus           `protobuf:"varint,2,opt,name=status,proto3,enum=rep_tracker.ImportStatus" json:"status,omitempty"`
+	Reason        string                 `protobuf:"bytes,3,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportRepoResult) Reset() {
+	*x = ImportRepoResult{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportRepoResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportRepoResult) ProtoMessage() {}
+
+func (x *ImportRepoResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportRepoResult.ProtoReflect.Descriptor instead.
+func (*ImportRepoResult) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *ImportRepoResult) GetLink() string {
+	if x != nil {
+		return x.Link
+	}
+	return ""
+}
+
+func (x *ImportRepoResult) GetStatus() ImportStatus {
+	if x != nil {
+		return x.Status
+	}
+	return ImportStatus_IMPORT_STATUS_UNSPECIFIED
+}
+
+func (x *ImportRepoResult) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+type ImportReposResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Results       []*ImportRepoResult    `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportReposResponse) Reset() {
+	*x = ImportReposResponse{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportReposResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportReposResponse) ProtoMessage() {}
+
+func (x *ImportReposResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportReposResponse.ProtoReflect.Descriptor instead.
+func (*ImportReposResponse) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *ImportReposResponse) GetResults() []*ImportRepoResult {
+	if x != nil {
+		return x.Results
+	}
+	return nil
+}
+
+type RefreshTokenRequest struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	ChatId string                 `protobuf:"bytes,1,opt,name=chat_id,json=chatId,proto3" json:"chat_id,omitempty"`
+	// New personal access token; when empty the stored token is re-validated.
+	Token string `protobuf:"bytes,2,opt,name=token,proto3" json:"token,omitempty"`
+	// GitHub host the token belongs to, e.g. "github.example.com"; empty means github.com.
+	Host          string `protobuf:"bytes,3,opt,name=host,proto3" json:"host,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshTokenRequest) Reset() {
+	*x = RefreshTokenRequest{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshTokenRequest) ProtoMessage() {}
+
+func (x *RefreshTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshTokenRequest.ProtoReflect.Descriptor instead.
+func (*RefreshTokenRequest) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *RefreshTokenRequest) GetChatId() string {
+	if x != nil {
+		return x.ChatId
+	}
+	return ""
+}
+
+func (x *RefreshTokenRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+func (x *RefreshTokenRequest) GetHost() string {
+	if x != nil {
+		return x.Host
+	}
+	return ""
+}
+
+type RefreshTokenResponse struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	GithubLogin string                 `protobuf:"bytes,1,opt,name=github_login,json=githubLogin,proto3" json:"github_login,omitempty"`
+	ValidatedAt *timestamppb.Timestamp `protobuf:"bytes,2,opt,name=validated_at,json=validatedAt,proto3" json:"validated_at,omitempty"`
+	// Subscriptions that were disabled because of an invalid token and are now tracked again.
+	ReenabledCount int32 `protobuf:"varint,3,opt,name=reenabled_count,json=reenabledCount,proto3" json:"reenabled_count,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RefreshTokenResponse) Reset() {
+	*x = RefreshTokenResponse{}
+	mi := &file_proto_rep_tracker_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshTokenResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshTokenResponse) ProtoMessage() {}
+
+func (x *RefreshTokenResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_rep_tracker_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshTokenResponse.ProtoReflect.Descriptor instead.
+func (*RefreshTokenResponse) Descriptor() ([]byte, []int) {
+	return file_proto_rep_tracker_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *RefreshTokenResponse) GetGithubLogin() string {
+	if x != nil {
+		return x.GithubLogin
+	}
+	return ""
+}
+
+func (x *RefreshTokenResponse) GetValidatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.ValidatedAt
+	}
+	return nil
+}
+
+func (x *RefreshTokenResponse) GetReenabledCount() int32 {
+	if x != nil {
+		return x.ReenabledCount
+	}
+	return 0
+}
+
+var File_proto_rep_tracker_proto protoreflect.FileDescriptor
+
+const file_proto_rep_tracker_proto_rawDesc = "" +
+	"\n" +
+	"\x17proto/rep_tracker.proto\x12\vrep_tracker\x1a\x1bgoogle/protobuf/empty.proto\x1a\x1fgoogle/protobuf/timestamp.proto\"W\n" +
+	"\fTrackingRepo\x12\x12\n" +
+	"\x04link\x18\x01 \x01(\tR\x04link\x12\x17\n" +
+	"\achat_id\x18\x02 \x01(\tR\x06chatId\x12\x1a\n" +
+	"\bbranches\x18\x03 \x03(\tR\bbranches\"\x86\x01\n" +
+	"\x18PauseTrackingRepoRequest\x12\x12\n" +
+	"\x04link\x18\x01 \x01(\tR\x04link\x12\x17\n" +
+	"\achat_id\x18\x02 \x01(\tR\x06chatId\x12=\n" +
+	"\fpaused_until\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vpausedUntil\"s\n" +
+	"\x12ResetCursorRequest\x12\x12\n" +
+	"\x04link\x18\x01 \x01(\tR\x04link\x12\x17\n" +
+	"\achat_id\x18\x02 \x01(\tR\x06chatId\x120\n" +
+	"\x05since\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\"\xcc\x01\n" +
+	"\x0fTrackingFilters\x12#\n" +
+	"\rinclude_paths\x18\x01 \x03(\tR\fincludePaths\x12#\n" +
+	"\rexclude_paths\x18\x02 \x03(\tR\fexcludePaths\x12#\n" +
+	"\rallow_authors\x18\x03 \x03(\tR\fallowAuthors\x12!\n" +
+	"\fdeny_authors\x18\x04 \x03(\tR\vdenyAuthors\x12'\n" +
+	"\x0fmessage_pattern\x18\x05 \x01(\tR\x0emessagePattern\"\x83\x01\n" +
+	"\x1cUpdateTrackingFiltersRequest\x12\x12\n" +
+	"\x04link\x18\x01 \x01(\tR\x04link\x12\x17\n" +
+	"\achat_id\x18\x02 \x01(\tR\x06chatId\x126\n" +
+	"\afilters\x18\x03 \x01(\v2\x1c.rep_tracker.TrackingFiltersR\afilters\"o\n" +
+	"\x18ListTrackingReposRequest\x12\x17\n" +
+	"\achat_id\x18\x01 \x01(\tR\x06chatId\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x02 \x01(\tR\tpageToken\x12\x1b\n" +
+	"\tpage_size\x18\x03 \x01(\x05R\bpageSize\"\xa9\x01\n" +
+	"\n" +
+	"CommitInfo\x12\x12\n" +
+	"\x04hash\x18\x01 \x01(\tR\x04hash\x12\x18\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\x12=\n" +
+	"\fcommitted_at\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\vcommittedAt\x12\x16\n" +
+	"\x06author\x18\x04 \x01(\tR\x06author\x12\x16\n" +
+	"\x06branch\x18\x05 \x01(\tR\x06branch\"\xf2\x02\n" +
+	"\x10TrackingRepoInfo\x12\x12\n" +
+	"\x04link\x18\x01 \x01(\tR\x04link\x12\x14\n" +
+	"\x05owner\x18\x02 \x01(\tR\x05owner\x12\x12\n" +
+	"\x04name\x18\x03 \x01(\tR\x04name\x12\x18\n" +
+	"\aenabled\x18\x04 \x01(\bR\aenabled\x129\n" +
+	"\n" +
+	"created_at\x18\x05 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x128\n" +
+	"\vlast_commit\x18\x06 \x01(\v2\x17.rep_tracker.CommitInfoR\n" +
+	"lastCommit\x12=\n" +
+	"\fpaused_until\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\vpausedUntil\x12\x1a\n" +
+	"\bbranches\x18\b \x03(\tR\bbranches\x126\n" +
+	"\afilters\x18\t \x01(\v2\x1c.rep_tracker.TrackingFiltersR\afilters\"x\n" +
+	"\x19ListTrackingReposResponse\x123\n" +
+	"\x05repos\x18\x01 \x03(\v2\x1d.rep_tracker.TrackingRepoInfoR\x05repos\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"\x94\x02\n" +
+	"\x15GetRepoHistoryRequest\x12\x17\n" +
+	"\achat_id\x18\x01 \x01(\tR\x06chatId\x12\x12\n" +
+	"\x04link\x18\x02 \x01(\tR\x04link\x120\n" +
+	"\x05since\x18\x03 \x01(\v2\x1a.google.protobuf.TimestampR\x05since\x120\n" +
+	"\x05until\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\x05until\x12\x16\n" +
+	"\x06author\x18\x05 \x01(\tR\x06author\x12\x16\n" +
+	"\x06branch\x18\x06 \x01(\tR\x06branch\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\a \x01(\tR\tpageToken\x12\x1b\n" +
+	"\tpage_size\x18\b \x01(\x05R\bpageSize\"s\n" +
+	"\x16GetRepoHistoryResponse\x121\n" +
+	"\acommits\x18\x01 \x03(\v2\x17.rep_tracker.CommitInfoR\acommits\x12&\n" +
+	"\x0fnext_page_token\x18\x02 \x01(\tR\rnextPageToken\"R\n" +
+	"\x13WatchChangesRequest\x12\x17\n" +
+	"\achat_id\x18\x01 \x01(\tR\x06chatId\x12\"\n" +
+	"\rlast_event_id\x18\x02 \x01(\x04R\vlastEventId\"\xd6\x01\n" +
+	"\vChangeEvent\x12\x19\n" +
+	"\bevent_id\x18\x01 \x01(\x04R\aeventId\x12\x17\n" +
+	"\achat_id\x18\x02 \x01(\tR\x06chatId\x12\x12\n" +
+	"\x04link\x18\x03 \x01(\tR\x04link\x12\x16\n" +
+	"\x06author\x18\x04 \x01(\tR\x06author\x12\x14\n" +
+	"\x05title\x18\x05 \x01(\tR\x05title\x12\x16\n" +
+	"\x06branch\x18\x06 \x01(\tR\x06branch\x129\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\tupdatedAt\"\xe2\x01\n" +
+	"\x12ImportReposRequest\x12\x17\n" +
+	"\achat_id\x18\x01 \x01(\tR\x06chatId\x12\x14\n" +
+	"\x05owner\x18\x02 \x01(\tR\x05owner\x12!\n" +
+	"\fname_pattern\x18\x03 \x01(\tR\vnamePattern\x12;\n" +
+	"\n" +
+	"visibility\x18\x04 \x01(\x0e2\x1b.rep_tracker.RepoVisibilityR\n" +
+	"visibility\x12)\n" +
+	"\x10include_archived\x18\x05 \x01(\bR\x0fincludeArchived\x12\x12\n" +
+	"\x04host\x18\x06 \x01(\tR\x04host\"q\n" +
+	"\x10ImportRepoResult\x12\x12\n" +
+	"\x04link\x18\x01 \x01(\tR\x04link\x121\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x19.rep_tracker.ImportStatusR\x06status\x12\x16\n" +
+	"\x06reason\x18\x03 \x01(\tR\x06reason\"N\n" +
+	"\x13ImportReposResponse\x127\n" +
+	"\aresults\x18\x01 \x03(\v2\x1d.rep_tracker.ImportRepoResultR\aresults\"X\n" +
+	"\x13RefreshTokenRequest\x12\x17\n" +
+	"\achat_id\x18\x01 \x01(\tR\x06chatId\x12\x14\n" +
+	"\x05token\x18\x02 \x01(\tR\x05token\x12\x12\n" +
+	"\x04host\x18\x03 \x01(\tR\x04host\"\xa1\x01\n" +
+	"\x14RefreshTokenResponse\x12!\n" +
+	"\fgithub_login\x18\x01 \x01(\tR\vgithubLogin\x12=\n" +
+	"\fvalidated_at\x18\x02 \x01(\v2\x1a.google.protobuf.TimestampR\vvalidatedAt\x12'\n" +
+	"\x0freenabled_count\x18\x03 \x01(\x05R\x0ereenabledCount*b\n" +
+	"\x0eRepoVisibility\x12\x17\n" +
+	"\x13REPO_VISIBILITY_ALL\x10\x00\x12\x1a\n" +
+	"\x16REPO_VISIBILITY_PUBLIC\x10\x01\x12\x1b\n" +
+	"\x17REPO_VISIBILITY_PRIVATE\x10\x02*\x83\x01\n" +
+	"\fImportStatus\x12\x1d\n" +
+	"\x19IMPORT_STATUS_UNSPECIFIED\x10\x00\x12\x17\n" +
+	"\x13IMPORT_STATUS_ADDED\x10\x01\x12!\n" +
+	"\x1dIMPORT_STATUS_ALREADY_TRACKED\x10\x02\x12\x18\n" +
+	"\x14IMPORT_STATUS_FAILED\x10\x032\x97\a\n" +
 	"\x11RepTrackerService\x12D\n" +
 	"\x0fAddTrackingRepo\x12\x19.rep_tracker.TrackingRepo\x1a\x16.google.protobuf.Empty\x12G\n" +
-	"\x12RemoveTrackingRepo\x12\x19.rep_tracker.TrackingRepo\x1a\x16.google.protobuf.EmptyB\x19Z\x17rep_tracker/proto;protob\x06proto3"
+	"\x12RemoveTrackingRepo\x12\x19.rep_tracker.TrackingRepo\x1a\x16.google.protobuf.Empty\x12R\n" +
+	"\x11PauseTrackingRepo\x12%.rep_tracker.PauseTrackingRepoRequest\x1a\x16.google.protobuf.Empty\x12G\n" +
+	"\x12ResumeTrackingRepo\x12\x19.rep_tracker.TrackingRepo\x1a\x16.google.protobuf.Empty\x12Z\n" +
+	"\x15UpdateTrackingFilters\x12).rep_tracker.UpdateTrackingFiltersRequest\x1a\x16.google.protobuf.Empty\x12F\n" +
+	"\vResetCursor\x12\x1f.rep_tracker.ResetCursorRequest\x1a\x16.google.protobuf.Empty\x12b\n" +
+	"\x11ListTrackingRepos\x12%.rep_tracker.ListTrackingReposRequest\x1a&.rep_tracker.ListTrackingReposResponse\x12Y\n" +
+	"\x0eGetRepoHistory\x12\".rep_tracker.GetRepoHistoryRequest\x1a#.rep_tracker.GetRepoHistoryResponse\x12P\n" +
+	"\vImportRepos\x12\x1f.rep_tracker.ImportReposRequest\x1a .rep_tracker.ImportReposResponse\x12S\n" +
+	"\fRefreshToken\x12 .rep_tracker.RefreshTokenRequest\x1a!.rep_tracker.RefreshTokenResponse\x12L\n" +
+	"\fWatchChanges\x12 .rep_tracker.WatchChangesRequest\x1a\x18.rep_tracker.ChangeEvent0\x01B\x19Z\x17rep_tracker/proto;protob\x06proto3"
 
 var (
 	file_proto_rep_tracker_proto_rawDescOnce sync.Once
@@ -98,21 +1493,77 @@ func file_proto_rep_tracker_proto_rawDescGZIP() []byte {
 	return file_proto_rep_tracker_proto_rawDescData
 }
 
-var file_proto_rep_tracker_proto_msgTypes = make([]protoimpl.MessageInfo, 1)
+var file_proto_rep_tracker_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_proto_rep_tracker_proto_msgTypes = make([]protoimpl.MessageInfo, 18)
 var file_proto_rep_tracker_proto_goTypes = []any{
-	(*TrackingRepo)(nil),  // 0: rep_tracker.TrackingRepo
-	(*emptypb.Empty)(nil), // 1: google.protobuf.Empty
+	(RepoVisibility)(0),                  // 0: rep_tracker.RepoVisibility
+	(ImportStatus)(0),                    // 1: rep_tracker.ImportStatus
+	(*TrackingRepo)(nil),                 // 2: rep_tracker.TrackingRepo
+	(*PauseTrackingRepoRequest)(nil),     // 3: rep_tracker.PauseTrackingRepoRequest
+	(*ResetCursorRequest)(nil),           // 4: rep_tracker.ResetCursorRequest
+	(*TrackingFilters)(nil),              // 5: rep_tracker.TrackingFilters
+	(*UpdateTrackingFiltersRequest)(nil), // 6: rep_tracker.UpdateTrackingFiltersRequest
+	(*ListTrackingReposRequest)(nil),     // 7: rep_tracker.ListTrackingReposRequest
+	(*CommitInfo)(nil),                   // 8: rep_tracker.CommitInfo
+	(*TrackingRepoInfo)(nil),             // 9: rep_tracker.TrackingRepoInfo
+	(*ListTrackingReposResponse)(nil),    // 10: rep_tracker.ListTrackingReposResponse
+	(*GetRepoHistoryRequest)(nil),        // 11: rep_tracker.GetRepoHistoryRequest
+	(*GetRepoHistoryResponse)(nil),       // 12: rep_tracker.GetRepoHistoryResponse
+	(*WatchChangesRequest)(nil),          // 13: rep_tracker.WatchChangesRequest
+	(*ChangeEvent)(nil),                  // 14: rep_tracker.ChangeEvent
+	(*ImportReposRequest)(nil),           // 15: rep_tracker.ImportReposRequest
+	(*ImportRepoResult)(nil),             // 16: rep_tracker.ImportRepoResult
+	(*ImportReposResponse)(nil),          // 17: rep_tracker.ImportReposResponse
+	(*RefreshTokenRequest)(nil),          // 18: rep_tracker.RefreshTokenRequest
+	(*RefreshTokenResponse)(nil),         // 19: rep_tracker.RefreshTokenResponse
+	(*timestamppb.Timestamp)(nil),        // 20: google.protobuf.Timestamp
+	(*emptypb.Empty)(nil),                // 21: google.protobuf.Empty
 }
 var file_proto_rep_tracker_proto_depIdxs = []int32{
-	0, // 0: rep_tracker.RepTrackerService.AddTrackingRepo:input_type -> rep_tracker.TrackingRepo
-	0, // 1: rep_tracker.RepTrackerService.RemoveTrackingRepo:input_type -> rep_tracker.TrackingRepo
-	1, // 2: rep_tracker.RepTrackerService.AddTrackingRepo:output_type -> google.protobuf.Empty
-	1, // 3: rep_tracker.RepTrackerService.RemoveTrackingRepo:output_type -> google.protobuf.Empty
-	2, // [2:4] is the sub-list for method output_type
-	0, // [0:2] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	20, // 0: rep_tracker.PauseTrackingRepoRequest.paused_until:type_name -> google.protobuf.Timestamp
+	20, // 1: rep_tracker.ResetCursorRequest.since:type_name -> google.protobuf.Timestamp
+	5,  // 2: rep_tracker.UpdateTrackingFiltersRequest.filters:type_name -> rep_tracker.TrackingFilters
+	20, // 3: rep_tracker.CommitInfo.committed_at:type_name -> google.protobuf.Timestamp
+	20, // 4: rep_tracker.TrackingRepoInfo.created_at:type_name -> google.protobuf.Timestamp
+	8,  // 5: rep_tracker.TrackingRepoInfo.last_commit:type_name -> rep_tracker.CommitInfo
+	20, // 6: rep_tracker.TrackingRepoInfo.paused_until:type_name -> google.protobuf.Timestamp
+	5,  // 7: rep_tracker.TrackingRepoInfo.filters:type_name -> rep_tracker.TrackingFilters
+	9,  // 8: rep_tracker.ListTrackingReposResponse.repos:type_name -> rep_tracker.TrackingRepoInfo
+	20, // 9: rep_tracker.GetRepoHistoryRequest.since:type_name -> google.protobuf.Timestamp
+	20, // 10: rep_tracker.GetRepoHistoryRequest.until:type_name -> google.protobuf.Timestamp
+	8,  // 11: rep_tracker.GetRepoHistoryResponse.commits:type_name -> rep_tracker.CommitInfo
+	20, // 12: rep_tracker.ChangeEvent.updated_at:type_name -> google.protobuf.Timestamp
+	0,  // 13: rep_tracker.ImportReposRequest.visibility:type_name -> rep_tracker.RepoVisibility
+	1,  // 14: rep_tracker.ImportRepoResult.status:type_name -> rep_tracker.ImportStatus
+	16, // 15: rep_tracker.ImportReposResponse.results:type_name -> rep_tracker.ImportRepoResult
+	20, // 16: rep_tracker.RefreshTokenResponse.validated_at:type_name -> google.protobuf.Timestamp
+	2,  // 17: rep_tracker.RepTrackerService.AddTrackingRepo:input_type -> rep_tracker.TrackingRepo
+	2,  // 18: rep_tracker.RepTrackerService.RemoveTrackingRepo:input_type -> rep_tracker.TrackingRepo
+	3,  // 19: rep_tracker.RepTrackerService.PauseTrackingRepo:input_type -> rep_tracker.PauseTrackingRepoRequest
+	2,  // 20: rep_tracker.RepTrackerService.ResumeTrackingRepo:input_type -> rep_tracker.TrackingRepo
+	6,  // 21: rep_tracker.RepTrackerService.UpdateTrackingFilters:input_type -> rep_tracker.UpdateTrackingFiltersRequest
+	4,  // 22: rep_tracker.RepTrackerService.ResetCursor:input_type -> rep_tracker.ResetCursorRequest
+	7,  // 23: rep_tracker.RepTrackerService.ListTrackingRepos:input_type -> rep_tracker.ListTrackingReposRequest
+	11, // 24: rep_tracker.RepTrackerService.GetRepoHistory:input_type -> rep_tracker.GetRepoHistoryRequest
+	15, // 25: rep_tracker.RepTrackerService.ImportRepos:input_type -> rep_tracker.ImportReposRequest
+	18, // 26: rep_tracker.RepTrackerService.RefreshToken:input_type -> rep_tracker.RefreshTokenRequest
+	13, // 27: rep_tracker.RepTrackerService.WatchChanges:input_type -> rep_tracker.WatchChangesRequest
+	21, // 28: rep_tracker.RepTrackerService.AddTrackingRepo:output_type -> google.protobuf.Empty
+	21, // 29: rep_tracker.RepTrackerService.RemoveTrackingRepo:output_type -> google.protobuf.Empty
+	21, // 30: rep_tracker.RepTrackerService.PauseTrackingRepo:output_type -> google.protobuf.Empty
+	21, // 31: rep_tracker.RepTrackerService.ResumeTrackingRepo:output_type -> google.protobuf.Empty
+	21, // 32: rep_tracker.RepTrackerService.UpdateTrackingFilters:output_type -> google.protobuf.Empty
+	21, // 33: rep_tracker.RepTrackerService.ResetCursor:output_type -> google.protobuf.Empty
+	10, // 34: rep_tracker.RepTrackerService.ListTrackingRepos:output_type -> rep_tracker.ListTrackingReposResponse
+	12, // 35: rep_tracker.RepTrackerService.GetRepoHistory:output_type -> rep_tracker.GetRepoHistoryResponse
+	17, // 36: rep_tracker.RepTrackerService.ImportRepos:output_type -> rep_tracker.ImportReposResponse
+	19, // 37: rep_tracker.RepTrackerService.RefreshToken:output_type -> rep_tracker.RefreshTokenResponse
+	14, // 38: rep_tracker.RepTrackerService.WatchChanges:output_type -> rep_tracker.ChangeEvent
+	28, // [28:39] is the sub-list for method output_type
+	17, // [17:28] is the sub-list for method input_type
+	17, // [17:17] is the sub-list for extension type_name
+	17, // [17:17] is the sub-list for extension extendee
+	0,  // [0:17] is the sub-list for field type_name
 }
 
 func init() { file_proto_rep_tracker_proto_init() }
@@ -125,13 +1576,14 @@ func file_proto_rep_tracker_proto_init() {
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_rep_tracker_proto_rawDesc), len(file_proto_rep_tracker_proto_rawDesc)),
-			NumEnums:      0,
-			NumMessages:   1,
+			NumEnums:      2,
+			NumMessages:   18,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
 		GoTypes:           file_proto_rep_tracker_proto_goTypes,
 		DependencyIndexes: file_proto_rep_tracker_proto_depIdxs,
+		EnumInfos:         file_proto_rep_tracker_proto_enumTypes,
 		MessageInfos:      file_proto_rep_tracker_proto_msgTypes,
 	}.Build()
 	File_proto_rep_tracker_proto = out.File
@@ -20,8 +20,17 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	RepTrackerService_AddTrackingRepo_FullMethodName    = "/rep_tracker.RepTrackerService/AddTrackingRepo"
-	RepTrackerService_RemoveTrackingRepo_FullMethodName = "/rep_tracker.RepTrackerService/RemoveTrackingRepo"
+	RepTrackerService_AddTrackingRepo_FullMethodName       = "/rep_tracker.RepTrackerService/AddTrackingRepo"
+	RepTrackerService_RemoveTrackingRepo_FullMethodName    = "/rep_tracker.RepTrackerService/RemoveTrackingRepo"
+	RepTrackerService_PauseTrackingRepo_FullMethodName     = "/rep_tracker.RepTrackerService/PauseTrackingRepo"
+	RepTrackerService_ResumeTrackingRepo_FullMethodName    = "/rep_tracker.RepTrackerService/ResumeTrackingRepo"
+	RepTrackerService_UpdateTrackingFilters_FullMethodName = "/rep_tracker.RepTrackerService/UpdateTrackingFilters"
+	RepTrackerService_ResetCursor_FullMethodName           = "/rep_tracker.RepTrackerService/ResetCursor"
+	RepTrackerService_ListTrackingRepos_FullMethodName     = "/rep_tracker.RepTrackerService/ListTrackingRepos"
+	RepTrackerService_GetRepoHistory_FullMethodName        = "/rep_tracker.RepTrackerService/GetRepoHistory"
+	RepTrackerService_ImportRepos_FullMethodName           = "/rep_tracker.RepTrackerService/ImportRepos"
+	RepTrackerService_RefreshToken_FullMethodName          = "/rep_tracker.RepTrackerService/RefreshToken"
+	RepTrackerService_WatchChanges_FullMethodName          = "/rep_tracker.RepTrackerService/WatchChanges"
 )
 
 // RepTrackerServiceClient is the client API for RepTrackerService service.
@@ -30,6 +39,19 @@ const (
 type RepTrackerServiceClient interface {
 	AddTrackingRepo(ctx context.Context, in *TrackingRepo, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	RemoveTrackingRepo(ctx context.Context, in *TrackingRepo, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	PauseTrackingRepo(ctx context.Context, in *PauseTrackingRepoRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ResumeTrackingRepo(ctx context.Context, in *TrackingRepo, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	UpdateTrackingFilters(ctx context.Context, in *UpdateTrackingFiltersRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ResetCursor(ctx context.Context, in *ResetCursorRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	ListTrackingRepos(ctx context.Context, in *ListTrackingReposRequest, opts ...grpc.CallOption) (*ListTrackingReposResponse, error)
+	GetRepoHistory(ctx context.Context, in *GetRepoHistoryRequest, opts ...grpc.CallOption) (*GetRepoHistoryResponse, error)
+	ImportRepos(ctx context.Context, in *ImportReposRequest, opts ...grpc.CallOption) (*ImportReposResponse, error)
+	RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*RefreshTokenResponse, error)
+	// Streams the changes notified to a chat. Delivery is at least once: a notification that is retried,
+	// e.g. because the subscription cursor could not be saved, is streamed again under a new event_id.
+	// The stream is best-effort and does not hold notifications back; a subscriber that falls behind or
+	// resumes from an expired event_id gets an error and has to start over from new events.
+	WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChangeEvent], error)
 }
 
 type repTrackerServiceClient struct {
@@ -60,12 +82,124 @@ func (c *repTrackerServiceClient) RemoveTrackingRepo(ctx context.Context, in *Tr
 	return out, nil
 }
 
+func (c *repTrackerServiceClient) PauseTrackingRepo(ctx context.Context, in *PauseTrackingRepoRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, RepTrackerService_PauseTrackingRepo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *repTrackerServiceClient) ResumeTrackingRepo(ctx context.Context, in *TrackingRepo, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, RepTrackerService_ResumeTrackingRepo_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *repTrackerServiceClient) UpdateTrackingFilters(ctx context.Context, in *UpdateTrackingFiltersRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, RepTrackerService_UpdateTrackingFilters_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *repTrackerServiceClient) ResetCursor(ctx context.Context, in *ResetCursorRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, RepTrackerService_ResetCursor_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *repTrackerServiceClient) ListTrackingRepos(ctx context.Context, in *ListTrackingReposRequest, opts ...grpc.CallOption) (*ListTrackingReposResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTrackingReposResponse)
+	err := c.cc.Invoke(ctx, RepTrackerService_ListTrackingRepos_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *repTrackerServiceClient) GetRepoHistory(ctx context.Context, in *GetRepoHistoryRequest, opts ...grpc.CallOption) (*GetRepoHistoryResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetRepoHistoryResponse)
+	err := c.cc.Invoke(ctx, RepTrackerService_GetRepoHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *repTrackerServiceClient) ImportRepos(ctx context.Context, in *ImportReposRequest, opts ...grpc.CallOption) (*ImportReposResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ImportReposResponse)
+	err := c.cc.Invoke(ctx, RepTrackerService_ImportRepos_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *repTrackerServiceClient) RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*RefreshTokenResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RefreshTokenResponse)
+	err := c.cc.Invoke(ctx, RepTrackerService_RefreshToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *repTrackerServiceClient) WatchChanges(ctx context.Context, in *WatchChangesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ChangeEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RepTrackerService_ServiceDesc.Streams[0], RepTrackerService_WatchChanges_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchChangesRequest, ChangeEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RepTrackerService_WatchChangesClient = grpc.ServerStreamingClient[ChangeEvent]
+
 // RepTrackerServiceServer is the server API for RepTrackerService service.
 // All implementations must embed UnimplementedRepTrackerServiceServer
 // for forward compatibility.
 type RepTrackerServiceServer interface {
 	AddTrackingRepo(context.Context, *TrackingRepo) (*emptypb.Empty, error)
 	RemoveTrackingRepo(context.Context, *TrackingRepo) (*emptypb.Empty, error)
+	PauseTrackingRepo(context.Context, *PauseTrackingRepoRequest) (*emptypb.Empty, error)
+	ResumeTrackingRepo(context.Context, *TrackingRepo) (*emptypb.Empty, error)
+	UpdateTrackingFilters(context.Context, *UpdateTrackingFiltersRequest) (*emptypb.Empty, error)
+	ResetCursor(context.Context, *ResetCursorRequest) (*emptypb.Empty, error)
+	ListTrackingRepos(context.Context, *ListTrackingReposRequest) (*ListTrackingReposResponse, error)
+	GetRepoHistory(context.Context, *GetRepoHistoryRequest) (*GetRepoHistoryResponse, error)
+	ImportRepos(context.Context, *ImportReposRequest) (*ImportReposResponse, error)
+	RefreshToken(context.Context, *RefreshTokenRequest) (*RefreshTokenResponse, error)
+	// Streams the changes notified to a chat. Delivery is at least once: a notification that is retried,
+	// e.g. because the subscription cursor could not be saved, is streamed again under a new event_id.
+	// The stream is best-effort and does not hold notifications back; a subscriber that falls behind or
+	// resumes from an expired event_id gets an error and has to start over from new events.
+	WatchChanges(*WatchChangesRequest, grpc.ServerStreamingServer[ChangeEvent]) error
 	mustEmbedUnimplementedRepTrackerServiceServer()
 }
 
@@ -82,6 +216,33 @@ func (UnimplementedRepTrackerServiceServer) AddTrackingRepo(context.Context, *Tr
 func (UnimplementedRepTrackerServiceServer) RemoveTrackingRepo(context.Context, *TrackingRepo) (*emptypb.Empty, error) {
 	return nil, status.Error(codes.Unimplemented, "method RemoveTrackingRepo not implemented")
 }
+func (UnimplementedRepTrackerServiceServer) PauseTrackingRepo(context.Context, *PauseTrackingRepoRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method PauseTrackingRepo not implemented")
+}
+func (UnimplementedRepTrackerServiceServer) ResumeTrackingRepo(context.Context, *TrackingRepo) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method ResumeTrackingRepo not implemented")
+}
+func (UnimplementedRepTrackerServiceServer) UpdateTrackingFilters(context.Context, *UpdateTrackingFiltersRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method UpdateTrackingFilters not implemented")
+}
+func (UnimplementedRepTrackerServiceServer) ResetCursor(context.Context, *ResetCursorRequest) (*emptypb.Empty, error) {
+	return nil, status.Error(codes.Unimplemented, "method ResetCursor not implemented")
+}
+func (UnimplementedRepTrackerServiceServer) ListTrackingRepos(context.Context, *ListTrackingReposRequest) (*ListTrackingReposResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTrackingRepos not implemented")
+}
+func (UnimplementedRepTrackerServiceServer) GetRepoHistory(context.Context, *GetRepoHistoryRequest) (*GetRepoHistoryResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetRepoHistory not implemented")
+}
+func (UnimplementedRepTrackerServiceServer) ImportRepos(context.Context, *ImportReposRequest) (*ImportReposResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ImportRepos not implemented")
+}
+func (UnimplementedRepTrackerServiceServer) RefreshToken(context.Context, *RefreshTokenRequest) (*RefreshTokenResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method RefreshToken not implemented")
+}
+func (UnimplementedRepTrackerServiceServer) WatchChanges(*WatchChangesRequest, grpc.ServerStreamingServer[ChangeEvent]) error {
+	return status.Error(codes.Unimplemented, "method WatchChanges not implemented")
+}
 func (UnimplementedRepTrackerServiceServer) mustEmbedUnimplementedRepTrackerServiceServer() {}
 func (UnimplementedRepTrackerServiceServer) testEmbeddedByValue()                           {}
 
@@ -139,6 +300,161 @@ func _RepTrackerService_RemoveTrackingRepo_Handler(srv interface{}, ctx context.
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RepTrackerService_PauseTrackingRepo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PauseTrackingRepoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepTrackerServiceServer).PauseTrackingRepo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RepTrackerService_PauseTrackingRepo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepTrackerServiceServer).PauseTrackingRepo(ctx, req.(*PauseTrackingRepoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RepTrackerService_ResumeTrackingRepo_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TrackingRepo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepTrackerServiceServer).ResumeTrackingRepo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RepTrackerService_ResumeTrackingRepo_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepTrackerServiceServer).ResumeTrackingRepo(ctx, req.(*TrackingRepo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RepTrackerService_UpdateTrackingFilters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(UpdateTrackingFiltersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepTrackerServiceServer).UpdateTrackingFilters(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RepTrackerService_UpdateTrackingFilters_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepTrackerServiceServer).UpdateTrackingFilters(ctx, req.(*UpdateTrackingFiltersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RepTrackerService_ResetCursor_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResetCursorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepTrackerServiceServer).ResetCursor(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RepTrackerService_ResetCursor_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepTrackerServiceServer).ResetCursor(ctx, req.(*ResetCursorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RepTrackerService_ListTrackingRepos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTrackingReposRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepTrackerServiceServer).ListTrackingRepos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RepTrackerService_ListTrackingRepos_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepTrackerServiceServer).ListTrackingRepos(ctx, req.(*ListTrackingReposRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RepTrackerService_GetRepoHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetRepoHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepTrackerServiceServer).GetRepoHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RepTrackerService_GetRepoHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepTrackerServiceServer).GetRepoHistory(ctx, req.(*GetRepoHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RepTrackerService_ImportRepos_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ImportReposRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepTrackerServiceServer).ImportRepos(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RepTrackerService_ImportRepos_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepTrackerServiceServer).ImportRepos(ctx, req.(*ImportReposRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RepTrackerService_RefreshToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RepTrackerServiceServer).RefreshToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RepTrackerService_RefreshToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RepTrackerServiceServer).RefreshToken(ctx, req.(*RefreshTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RepTrackerService_WatchChanges_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchChangesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RepTrackerServiceServer).WatchChanges(m, &grpc.GenericServerStream[WatchChangesRequest, ChangeEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RepTrackerService_WatchChangesServer = grpc.ServerStreamingServer[ChangeEvent]
+
 // RepTrackerService_ServiceDesc is the grpc.ServiceDesc for RepTrackerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -154,7 +470,45 @@ var RepTrackerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RemoveTrackingRepo",
 			Handler:    _RepTrackerService_RemoveTrackingRepo_Handler,
 		},
+		{
+			MethodName: "PauseTrackingRepo",
+			Handler:    _RepTrackerService_PauseTrackingRepo_Handler,
+		},
+		{
+			MethodName: "ResumeTrackingRepo",
+			Handler:    _RepTrackerService_ResumeTrackingRepo_Handler,
+		},
+		{
+			MethodName: "UpdateTrackingFilters",
+			Handler:    _RepTrackerService_UpdateTrackingFilters_Handler,
+		},
+		{
+			MethodName: "ResetCursor",
+			Handler:    _RepTrackerService_ResetCursor_Handler,
+		},
+		{
+			MethodName: "ListTrackingRepos",
+			Handler:    _RepTrackerService_ListTrackingRepos_Handler,
+		},
+		{
+			MethodName: "GetRepoHistory",
+			Handler:    _RepTrackerService_GetRepoHistory_Handler,
+		},
+		{
+			MethodName: "ImportRepos",
+			Handler:    _RepTrackerService_ImportRepos_Handler,
+		},
+		{
+			MethodName: "RefreshToken",
+			Handler:    _RepTrackerService_RefreshToken_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchChanges",
+			Handler:       _RepTrackerService_WatchChanges_Handler,
+			ServerStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto/rep_tracker.proto",
 }
@@ -0,0 +1,214 @@
+package repolink
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+
+	"rep_tracker/pkg/errs"
+)
+
+// DefaultHost is used for owner/repo shorthand links.
+const DefaultHost = "github.com"
+
+// Kind is the forge software serving a host; it decides how link paths are read.
+type Kind string
+
+const (
+	KindGitHub Kind = "github"
+	KindGitLab Kind = "gitlab"
+	KindGitea  Kind = "gitea"
+)
+
+type hostEntry struct {
+	host string
+	kind Kind
+}
+
+var (
+	hostsMx sync.RWMutex
+	// hosts maps every accepted host to the host used in canonical links.
+	hosts = map[string]hostEntry{
+		DefaultHost:      {host: DefaultHost, kind: KindGitHub},
+		"www.github.com": {host: DefaultHost, kind: KindGitHub},
+		"api.github.com": {host: DefaultHost, kind: KindGitHub},
+	}
+)
+
+// RegisterHost makes links on host (e.g. a GitHub Enterprise or GitLab instance) and its aliases parseable.
+// Links on an alias are canonicalized to host.
+func RegisterHost(kind Kind, host string, aliases ...string) {
+	host = strings.ToLower(host)
+	hostsMx.Lock()
+	defer hostsMx.Unlock()
+	hosts[host] = hostEntry{host: host, kind: kind}
+	for _, alias := range aliases {
+		hosts[strings.ToLower(alias)] = hostEntry{host: host, kind: kind}
+	}
+}
+
+var (
+	scpLikeLink = regexp.MustCompile(`^(?:[\w.-]+@)?([\w.-]+):([^/].*)$`)
+	ownerRe     = regexp.MustCompile(`^[a-z0-9](?:[a-z0-9-]*[a-z0-9])?$`)
+	nameRe      = regexp.MustCompile(`^[a-z0-9._-]+$`)
+)
+
+// Link identifies a repository independently of how the user spelled it.
+// Host, Owner and Name are lower-cased since forges treat them case-insensitively.
+// On GitLab Owner is the full group path, e.g. group/subgroup.
+type Link struct {
+	Kind  Kind
+	Host  string
+	Owner string
+	Name  string
+}
+
+// String returns the canonical link, e.g. https://github.com/owner/name.
+func (l Link) String() string {
+	return "https://" + l.Host + "/" + l.Owner + "/" + l.Name
+}
+
+// CompareURL returns the web page comparing base with head, e.g. https://github.com/owner/name/compare/a...b.
+func (l Link) CompareURL(base string, head string) string {
+	if l.Kind == KindGitLab {
+		return l.String() + "/-/compare/" + base + "..." + head
+	}
+	return l.String() + "/compare/" + base + "..." + head
+}
+
+// FullName returns owner/name.
+func (l Link) FullName() string {
+	return l.Owner + "/" + l.Name
+}
+
+// Parse accepts the forms users paste and the URLs returned by the GitHub API:
+//
+//	https://github.com/owner/repo
+//	http://github.com/owner/repo/
+//	https://github.com/owner/repo.git
+//	https://github.com/owner/repo/tree/main
+//	git@github.com:owner/repo.git
+//	ssh://git@github.com/owner/repo.git
+//	github.com/owner/repo
+//	https://api.github.com/repos/owner/repo/commits/sha
+//	https://github.example.com/api/v3/repos/owner/repo (registered hosts only)
+//	https://gitlab.example.com/group/subgroup/repo/-/tree/main (registered hosts only)
+//	owner/repo
+//
+// Errors are *errs.LinkError wrapping errs.ErrInvalidLink or errs.ErrUnsupportedHost.
+func Parse(raw string) (Link, error) {
+	host, path, err := split(strings.TrimSpace(raw))
+	if err != nil {
+		return Link{}, &errs.LinkError{Link: raw, Err: err}
+	}
+	hostsMx.RLock()
+	entry, ok := hosts[strings.ToLower(host)]
+	hostsMx.RUnlock()
+	if !ok {
+		return Link{}, &errs.LinkError{Link: raw, Err: errs.ErrUnsupportedHost}
+	}
+
+	var owner, name string
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	switch entry.kind {
+	case KindGitLab:
+		owner, name, ok = gitlabPath(parts)
+	case KindGitea:
+		owner, name, ok = apiPath(parts, "v1", false)
+	default:
+		// API URLs look like https://api.github.com/repos/owner/name/... on github.com
+		// and https://host/api/v3/repos/owner/name/... on GitHub Enterprise.
+		owner, name, ok = apiPath(parts, "v3", strings.HasPrefix(strings.ToLower(host), "api."))
+	}
+	if ok && entry.kind == KindGitHub {
+		ok = ownerRe.MatchString(owner)
+	}
+	if !ok || !validSegments(owner) || !nameRe.MatchString(name) || name == "." || name == ".." {
+		return Link{}, &errs.LinkError{Link: raw, Err: errs.ErrInvalidLink}
+	}
+	return Link{Kind: entry.kind, Host: entry.host, Owner: owner, Name: name}, nil
+}
+
+// apiPath reads owner/name from the first two segments, skipping an api/{version}/repos prefix.
+func apiPath(parts []string, version string, isAPI bool) (string, string, bool) {
+	if len(parts) >= 5 && parts[0] == "api" && parts[1] == version {
+		parts = parts[2:]
+		isAPI = true
+	}
+	if isAPI {
+		// Only repository endpoints name a repository, e.g. not /users/owner.
+		if len(parts) < 3 || parts[0] != "repos" {
+			return "", "", false
+		}
+		parts = parts[1:]
+	}
+	if len(parts) < 2 {
+		return "", "", false
+	}
+	return strings.ToLower(parts[0]), strings.ToLower(strings.TrimSuffix(parts[1], ".git")), true
+}
+
+// gitlabPath reads a project path of any depth; GitLab separates it from sub-pages with "/-/".
+func gitlabPath(parts []string) (string, string, bool) {
+	for i, part := range parts {
+		if part == "-" {
+			parts = parts[:i]
+			break
+		}
+	}
+	if len(parts) < 2 {
+		return "", "", false
+	}
+	name := strings.ToLower(strings.TrimSuffix(parts[len(parts)-1], ".git"))
+	return strings.ToLower(strings.Join(parts[:len(parts)-1], "/")), name, true
+}
+
+func validSegments(path string) bool {
+	for _, segment := range strings.Split(path, "/") {
+		if !nameRe.MatchString(segment) || segment == "." || segment == ".." {
+			return false
+		}
+	}
+	return true
+}
+
+// Canonical returns the canonical form of the link.
+func Canonical(raw string) (string, error) {
+	link, err := Parse(raw)
+	if err != nil {
+		return "", err
+	}
+	return link.String(), nil
+}
+
+// split returns the host and path of the link.
+func split(raw string) (string, string, error) {
+	if raw == "" {
+		return "", "", errs.ErrInvalidLink
+	}
+	if strings.Contains(raw, "://") {
+		u, err := url.Parse(raw)
+		if err != nil || u.Host == "" {
+			return "", "", errs.ErrInvalidLink
+		}
+		switch strings.ToLower(u.Scheme) {
+		case "http", "https", "ssh", "git":
+		default:
+			return "", "", errs.ErrInvalidLink
+		}
+		return u.Hostname(), u.Path, nil
+	}
+	if m := scpLikeLink.FindStringSubmatch(raw); m != nil {
+		return m[1], m[2], nil
+	}
+	// Without a scheme the first segment is a host only when it looks like one.
+	first, rest, _ := strings.Cut(raw, "/")
+	if strings.Contains(first, ".") {
+		return first, rest, nil
+	}
+	if strings.Count(strings.Trim(raw, "/"), "/") == 1 {
+		return DefaultHost, raw, nil
+	}
+	return "", "", errs.ErrInvalidLink
+}
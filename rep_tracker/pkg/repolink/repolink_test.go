@@ -0,0 +1,172 @@
+package repolink
+
+import (
+	"errors"
+	"testing"
+
+	"rep_tracker/pkg/errs"
+)
+
+func init() {
+	RegisterHost(KindGitHub, "ghe.test", "api.ghe.test")
+	RegisterHost(KindGitLab, "gitlab.test")
+	RegisterHost(KindGitea, "gitea.test")
+}
+
+func TestParse(t *testing.T) {
+	github := Link{Kind: KindGitHub, Host: "github.com", Owner: "owner", Name: "repo"}
+	tests := []struct {
+		name string
+		raw  string
+		want Link
+	}{
+		{name: "https", raw: "https://github.com/owner/repo", want: github},
+		{name: "http with trailing slash", raw: "http://github.com/owner/repo/", want: github},
+		{name: "mixed case", raw: "https://GitHub.com/Owner/Repo", want: github},
+		{name: "www alias", raw: "https://www.github.com/owner/repo", want: github},
+		{name: "surrounding spaces", raw: "  https://github.com/owner/repo\n", want: github},
+		{name: ".git suffix", raw: "https://github.com/owner/repo.git", want: github},
+		{name: "tree page", raw: "https://github.com/owner/repo/tree/main", want: github},
+		{name: "blob page", raw: "https://github.com/owner/repo/blob/main/docs/README.md", want: github},
+		{name: "scp-style", raw: "git@github.com:owner/repo.git", want: github},
+		{name: "scp-style without user", raw: "github.com:owner/repo", want: github},
+		{name: "ssh", raw: "ssh://git@github.com/owner/repo.git", want: github},
+		{name: "ssh with port", raw: "ssh://git@github.com:22/owner/repo.git", want: github},
+		{name: "without scheme", raw: "github.com/owner/repo", want: github},
+		{name: "owner/repo shorthand", raw: "owner/repo", want: github},
+		{name: "api url", raw: "https://api.github.com/repos/owner/repo/commits/abc", want: github},
+		{name: "repo named like a dot file", raw: "https://github.com/owner/.github", want: Link{Kind: KindGitHub, Host: "github.com", Owner: "owner", Name: ".github"}},
+		{
+			name: "enterprise",
+			raw:  "https://ghe.test/Owner/Repo/tree/main",
+			want: Link{Kind: KindGitHub, Host: "ghe.test", Owner: "owner", Name: "repo"},
+		},
+		{
+			name: "enterprise api v3",
+			raw:  "https://ghe.test/api/v3/repos/owner/repo/pulls/1",
+			want: Link{Kind: KindGitHub, Host: "ghe.test", Owner: "owner", Name: "repo"},
+		},
+		{
+			name: "enterprise api host alias",
+			raw:  "https://api.ghe.test/repos/owner/repo",
+			want: Link{Kind: KindGitHub, Host: "ghe.test", Owner: "owner", Name: "repo"},
+		},
+		{
+			name: "enterprise repo named repos",
+			raw:  "https://ghe.test/owner/repos",
+			want: Link{Kind: KindGitHub, Host: "ghe.test", Owner: "owner", Name: "repos"},
+		},
+		{
+			name: "gitlab subgroups",
+			raw:  "https://gitlab.test/Group/Sub/Repo",
+			want: Link{Kind: KindGitLab, Host: "gitlab.test", Owner: "group/sub", Name: "repo"},
+		},
+		{
+			name: "gitlab sub-page",
+			raw:  "https://gitlab.test/group/sub/repo/-/tree/main/docs",
+			want: Link{Kind: KindGitLab, Host: "gitlab.test", Owner: "group/sub", Name: "repo"},
+		},
+		{
+			name: "gitlab scp-style",
+			raw:  "git@gitlab.test:group/sub/repo.git",
+			want: Link{Kind: KindGitLab, Host: "gitlab.test", Owner: "group/sub", Name: "repo"},
+		},
+		{
+			name: "gitea",
+			raw:  "https://gitea.test/Owner/Repo.git",
+			want: Link{Kind: KindGitea, Host: "gitea.test", Owner: "owner", Name: "repo"},
+		},
+		{
+			name: "gitea api v1",
+			raw:  "https://gitea.test/api/v1/repos/owner/repo/commits",
+			want: Link{Kind: KindGitea, Host: "gitea.test", Owner: "owner", Name: "repo"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.raw)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", tt.raw, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.raw, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		want error
+	}{
+		{name: "empty", raw: " ", want: errs.ErrInvalidLink},
+		{name: "owner only", raw: "https://github.com/owner", want: errs.ErrInvalidLink},
+		{name: "single word", raw: "repo", want: errs.ErrInvalidLink},
+		{name: "shorthand with more segments", raw: "owner/repo/tree", want: errs.ErrInvalidLink},
+		{name: "unknown scheme", raw: "ftp://github.com/owner/repo", want: errs.ErrInvalidLink},
+		{name: "no host", raw: "https:///owner/repo", want: errs.ErrInvalidLink},
+		{name: "github owner with underscore", raw: "https://github.com/own_er/repo", want: errs.ErrInvalidLink},
+		{name: "github owner with leading dash", raw: "https://github.com/-owner/repo", want: errs.ErrInvalidLink},
+		{name: "name with space", raw: "https://github.com/owner/re%20po", want: errs.ErrInvalidLink},
+		{name: "dot dot name", raw: "https://github.com/owner/..", want: errs.ErrInvalidLink},
+		{name: "api url without repo", raw: "https://api.github.com/repos/owner", want: errs.ErrInvalidLink},
+		{name: "api url of a user", raw: "https://api.github.com/users/owner/repos", want: errs.ErrInvalidLink},
+		{name: "gitlab project without group", raw: "https://gitlab.test/repo/-/tree/main", want: errs.ErrInvalidLink},
+		{name: "gitlab dot dot group", raw: "https://gitlab.test/group/../repo", want: errs.ErrInvalidLink},
+		{name: "unregistered host", raw: "https://bitbucket.org/owner/repo", want: errs.ErrUnsupportedHost},
+		{name: "unregistered scp host", raw: "git@example.com:owner/repo.git", want: errs.ErrUnsupportedHost},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.raw)
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Parse(%q) = %+v, %v, want %v", tt.raw, got, err, tt.want)
+			}
+			var linkErr *errs.LinkError
+			if !errors.As(err, &linkErr) || linkErr.Link != tt.raw {
+				t.Errorf("Parse(%q) error = %#v, want a *errs.LinkError for the link", tt.raw, err)
+			}
+		})
+	}
+}
+
+func TestCanonical(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want string
+	}{
+		{raw: "git@github.com:Owner/Repo.git", want: "https://github.com/owner/repo"},
+		{raw: "owner/repo", want: "https://github.com/owner/repo"},
+		{raw: "https://api.ghe.test/repos/owner/repo", want: "https://ghe.test/owner/repo"},
+		{raw: "https://gitlab.test/group/sub/repo/-/merge_requests/1", want: "https://gitlab.test/group/sub/repo"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.raw, func(t *testing.T) {
+			got, err := Canonical(tt.raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("Canonical(%q) = %q, want %q", tt.raw, got, tt.want)
+			}
+			// The canonical link parses to itself, so stored links are stable.
+			again, err := Canonical(got)
+			if err != nil || again != got {
+				t.Errorf("Canonical(%q) = %q, %v, want it unchanged", got, again, err)
+			}
+		})
+	}
+}
+
+func TestCompareURL(t *testing.T) {
+	github := Link{Kind: KindGitHub, Host: "github.com", Owner: "owner", Name: "repo"}
+	gitlab := Link{Kind: KindGitLab, Host: "gitlab.test", Owner: "group/sub", Name: "repo"}
+	if got, want := github.CompareURL("a", "b"), "https://github.com/owner/repo/compare/a...b"; got != want {
+		t.Errorf("github compare url = %q, want %q", got, want)
+	}
+	if got, want := gitlab.CompareURL("a", "b"), "https://gitlab.test/group/sub/repo/-/compare/a...b"; got != want {
+		t.Errorf("gitlab compare url = %q, want %q", got, want)
+	}
+}
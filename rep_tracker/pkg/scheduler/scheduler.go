@@ -2,15 +2,20 @@ package scheduler
 
 import (
 	"context"
+	"fmt"
 	"sync/atomic"
 	"time"
 )
 
 type ScheduledTask func(ctx context.Context)
 
-type Scheduler struct{}
+type Scheduler struct {
+	startedAt     atomic.Int64
+	lastCompleted atomic.Int64
+}
 
 func (scheduler *Scheduler) Run(ctx context.Context, duration time.Duration, task ScheduledTask) {
+	scheduler.startedAt.Store(time.Now().UnixNano())
 	ticker := time.NewTicker(duration)
 	defer ticker.Stop()
 	var running atomic.Bool
@@ -29,7 +34,25 @@ func (scheduler *Scheduler) Run(ctx context.Context, duration time.Duration, tas
 				running.Store(true)
 				task(localCtx)
 				running.Store(false)
+				scheduler.lastCompleted.Store(time.Now().UnixNano())
 			}()
 		}
 	}
 }
+
+// LivenessProbe fails when no task run has completed within staleAfter (counted from Run when none has completed yet).
+func (scheduler *Scheduler) LivenessProbe(staleAfter time.Duration) func() error {
+	return func() error {
+		last := scheduler.lastCompleted.Load()
+		if last == 0 {
+			last = scheduler.startedAt.Load()
+		}
+		if last == 0 {
+			return fmt.Errorf("scheduler is not running")
+		}
+		if since := time.Since(time.Unix(0, last)); since > staleAfter {
+			return fmt.Errorf("no completed tick for %v", since.Truncate(time.Second))
+		}
+		return nil
+	}
+}
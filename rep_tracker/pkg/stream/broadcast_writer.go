@@ -0,0 +1,165 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"rep_tracker/pkg/dto"
+)
+
+var (
+	ErrEventsExpired  = errors.New("requested events are no longer buffered")
+	ErrSlowSubscriber = errors.New("subscriber is too slow, events dropped")
+	ErrWriterClosed   = errors.New("notification writer closed")
+)
+
+type BroadcastNotificationWriterConfig struct {
+	// BacklogSize is the number of recent events kept for resuming subscribers.
+	BacklogSize int
+	// SubscriberBuffer is the number of undelivered events a subscriber may lag behind before it is dropped.
+	SubscriberBuffer int
+}
+
+type Event struct {
+	ID     uint64
+	ChatID string
+	Change *dto.ChangingDTO
+}
+
+type Subscription struct {
+	chatID string
+	events chan Event
+	err    error
+	owner  *BroadcastNotificationWriter
+}
+
+// BroadcastNotificationWriter fans notifications out to in-process subscribers.
+type BroadcastNotificationWriter struct {
+	mx          sync.Mutex
+	cfg         BroadcastNotificationWriterConfig
+	nextID      uint64
+	backlog     []Event
+	subscribers map[*Subscription]struct{}
+	closed      bool
+}
+
+func NewBroadcastNotificationWriter(cfg BroadcastNotificationWriterConfig) *BroadcastNotificationWriter {
+	if cfg.BacklogSize <= 0 {
+		cfg.BacklogSize = 1024
+	}
+	if cfg.SubscriberBuffer <= 0 {
+		cfg.SubscriberBuffer = 256
+	}
+	return &BroadcastNotificationWriter{
+		cfg: cfg,
+		// Seeding with the start time keeps IDs increasing across restarts, so stale resume IDs are detected.
+		nextID:      uint64(time.Now().UnixNano()),
+		backlog:     make([]Event, 0, cfg.BacklogSize),
+		subscribers: make(map[*Subscription]struct{}),
+	}
+}
+
+func (w *BroadcastNotificationWriter) WriteNotification(ctx context.Context, chatId string, change *dto.ChangingDTO) error {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+	if w.closed {
+		return ErrWriterClosed
+	}
+	w.nextID++
+	event := Event{ID: w.nextID, ChatID: chatId, Change: change}
+	if len(w.backlog) == w.cfg.BacklogSize {
+		copy(w.backlog, w.backlog[1:])
+		w.backlog = w.backlog[:len(w.backlog)-1]
+	}
+	w.backlog = append(w.backlog, event)
+
+	for sub := range w.subscribers {
+		if !sub.matches(chatId) {
+			continue
+		}
+		select {
+		case sub.events <- event:
+		default:
+			w.dropLocked(sub, ErrSlowSubscriber)
+		}
+	}
+	return nil
+}
+
+// Subscribe registers a subscriber for the chat (all chats when empty).
+// When lastEventID is set, buffered events after it are replayed first.
+func (w *BroadcastNotificationWriter) Subscribe(chatID string, lastEventID uint64) (*Subscription, error) {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+	if w.closed {
+		return nil, ErrWriterClosed
+	}
+
+	replay := make([]Event, 0)
+	if lastEventID != 0 && lastEventID < w.nextID {
+		if len(w.backlog) == 0 || lastEventID < w.backlog[0].ID-1 {
+			return nil, ErrEventsExpired
+		}
+		for _, event := range w.backlog {
+			if event.ID > lastEventID && (chatID == "" || event.ChatID == chatID) {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	sub := &Subscription{
+		chatID: chatID,
+		events: make(chan Event, w.cfg.SubscriberBuffer+len(replay)),
+		owner:  w,
+	}
+	for _, event := range replay {
+		sub.events <- event
+	}
+	w.subscribers[sub] = struct{}{}
+	return sub, nil
+}
+
+func (w *BroadcastNotificationWriter) Close() error {
+	w.mx.Lock()
+	defer w.mx.Unlock()
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	for sub := range w.subscribers {
+		w.dropLocked(sub, ErrWriterClosed)
+	}
+	return nil
+}
+
+func (w *BroadcastNotificationWriter) dropLocked(sub *Subscription, err error) {
+	if _, ok := w.subscribers[sub]; !ok {
+		return
+	}
+	delete(w.subscribers, sub)
+	sub.err = err
+	close(sub.events)
+}
+
+// Events is closed when the subscription ends; Err then tells why.
+func (s *Subscription) Events() <-chan Event {
+	return s.events
+}
+
+func (s *Subscription) Err() error {
+	s.owner.mx.Lock()
+	defer s.owner.mx.Unlock()
+	return s.err
+}
+
+func (s *Subscription) Close() {
+	s.owner.mx.Lock()
+	defer s.owner.mx.Unlock()
+	s.owner.dropLocked(s, nil)
+}
+
+func (s *Subscription) matches(chatID string) bool {
+	return s.chatID == "" || s.chatID == chatID
+}
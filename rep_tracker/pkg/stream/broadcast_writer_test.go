@@ -0,0 +1,173 @@
+package stream
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"rep_tracker/pkg/dto"
+)
+
+func write(t *testing.T, w *BroadcastNotificationWriter, chatID string, title string) {
+	t.Helper()
+	if err := w.WriteNotification(context.Background(), chatID, &dto.ChangingDTO{Title: title}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// received drains the events buffered for the subscription.
+func received(sub *Subscription) []string {
+	var titles []string
+	for {
+		select {
+		case event, ok := <-sub.Events():
+			if !ok {
+				return titles
+			}
+			titles = append(titles, event.Change.Title)
+		default:
+			return titles
+		}
+	}
+}
+
+func TestSubscribeResume(t *testing.T) {
+	w := NewBroadcastNotificationWriter(BroadcastNotificationWriterConfig{BacklogSize: 10})
+	defer w.Close()
+	first, err := w.Subscribe("", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	write(t, w, "a", "a1")
+	write(t, w, "b", "b1")
+	write(t, w, "a", "a2")
+	write(t, w, "a", "a3")
+	var ids []uint64
+	for range 4 {
+		ids = append(ids, (<-first.Events()).ID)
+	}
+	if !slices.IsSorted(ids) {
+		t.Fatalf("event ids = %v, want increasing", ids)
+	}
+
+	tests := []struct {
+		name        string
+		chatID      string
+		lastEventID uint64
+		want        []string
+	}{
+		{name: "new events only", chatID: "a"},
+		{name: "after an event", chatID: "a", lastEventID: ids[0], want: []string{"a2", "a3"}},
+		{name: "other chats are not replayed", chatID: "b", lastEventID: ids[0], want: []string{"b1"}},
+		{name: "all chats", lastEventID: ids[1], want: []string{"a2", "a3"}},
+		{name: "after the last event", chatID: "a", lastEventID: ids[3]},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := w.Subscribe(tt.chatID, tt.lastEventID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer sub.Close()
+			if got := received(sub); !slices.Equal(got, tt.want) {
+				t.Errorf("replayed = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSubscribeResumeThenLive(t *testing.T) {
+	w := NewBroadcastNotificationWriter(BroadcastNotificationWriterConfig{BacklogSize: 10, SubscriberBuffer: 1})
+	defer w.Close()
+	write(t, w, "a", "a1")
+	write(t, w, "a", "a2")
+	sub, err := w.Subscribe("a", w.backlog[0].ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The replayed events do not count against the buffer of live events.
+	write(t, w, "a", "a3")
+	if got, want := received(sub), []string{"a2", "a3"}; !slices.Equal(got, want) {
+		t.Errorf("events = %v, want %v", got, want)
+	}
+	if err := sub.Err(); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+}
+
+func TestSubscribeExpired(t *testing.T) {
+	w := NewBroadcastNotificationWriter(BroadcastNotificationWriterConfig{BacklogSize: 2})
+	defer w.Close()
+	for _, title := range []string{"a1", "a2", "a3", "a4"} {
+		write(t, w, "a", title)
+	}
+	oldest := w.backlog[0].ID
+
+	if _, err := w.Subscribe("a", oldest-2); !errors.Is(err, ErrEventsExpired) {
+		t.Errorf("subscribe before the backlog: err = %v, want %v", err, ErrEventsExpired)
+	}
+	// Resuming right before the oldest buffered event loses nothing.
+	sub, err := w.Subscribe("a", oldest-1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer sub.Close()
+	if got, want := received(sub), []string{"a3", "a4"}; !slices.Equal(got, want) {
+		t.Errorf("replayed = %v, want %v", got, want)
+	}
+}
+
+func TestSlowSubscriber(t *testing.T) {
+	w := NewBroadcastNotificationWriter(BroadcastNotificationWriterConfig{SubscriberBuffer: 1})
+	defer w.Close()
+	slow, err := w.Subscribe("a", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	other, err := w.Subscribe("b", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer other.Close()
+
+	// Writing never blocks on a subscriber, the one that lags behind is dropped instead.
+	write(t, w, "a", "a1")
+	write(t, w, "a", "a2")
+	write(t, w, "b", "b1")
+	if got, want := received(slow), []string{"a1"}; !slices.Equal(got, want) {
+		t.Errorf("slow subscriber events = %v, want %v", got, want)
+	}
+	if _, ok := <-slow.Events(); ok {
+		t.Error("events of a dropped subscriber are not closed")
+	}
+	if err := slow.Err(); !errors.Is(err, ErrSlowSubscriber) {
+		t.Errorf("err = %v, want %v", err, ErrSlowSubscriber)
+	}
+	if got, want := received(other), []string{"b1"}; !slices.Equal(got, want) {
+		t.Errorf("other subscriber events = %v, want %v", got, want)
+	}
+}
+
+func TestClose(t *testing.T) {
+	w := NewBroadcastNotificationWriter(BroadcastNotificationWriterConfig{})
+	sub, err := w.Subscribe("a", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := <-sub.Events(); ok {
+		t.Error("events are not closed")
+	}
+	if err := sub.Err(); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("err = %v, want %v", err, ErrWriterClosed)
+	}
+	if _, err := w.Subscribe("a", 0); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("subscribe err = %v, want %v", err, ErrWriterClosed)
+	}
+	if err := w.WriteNotification(context.Background(), "a", &dto.ChangingDTO{}); !errors.Is(err, ErrWriterClosed) {
+		t.Errorf("write err = %v, want %v", err, ErrWriterClosed)
+	}
+}
@@ -0,0 +1,328 @@
+package grpc_kit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"service_common/logctx"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	defaultAuthReloadInterval = 30 * time.Second
+	jwtClockSkew              = 30 * time.Second
+)
+
+// Health checks and reflection stay reachable for probes and tooling.
+var defaultAuthExemptPrefixes = []string{"/grpc.health.v1.Health/", "/grpc.reflection."}
+
+// AuthConfig enables authentication of callers when KeysFile is set.
+//
+// KeysFile is a JSON document with static bearer keys and HMAC secrets for JWTs:
+//
+//	{
+//	  "api_keys": [{"identity": "server", "key": "..."}],
+//	  "jwt_keys": [{"id": "2024-01", "secret": "..."}]
+//	}
+//
+// JWTs must be signed with HS256, HS384 or HS512 and carry "sub" (caller identity) and "exp".
+type AuthConfig struct {
+	KeysFile string
+	// ReloadInterval is how often the keys file is checked for changes.
+	ReloadInterval time.Duration
+	// ExemptMethodPrefixes are full method prefixes served without credentials.
+	ExemptMethodPrefixes []string
+}
+
+func (cfg AuthConfig) Enabled() bool {
+	return cfg.KeysFile != ""
+}
+
+type apiKey struct {
+	Identity string `json:"identity"`
+	Key      string `json:"key"`
+}
+
+type jwtKey struct {
+	ID     string `json:"id"`
+	Secret string `json:"secret"`
+}
+
+type keySet struct {
+	APIKeys []apiKey `json:"api_keys"`
+	JWTKeys []jwtKey `json:"jwt_keys"`
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+type jwtClaims struct {
+	Subject   string `json:"sub"`
+	ExpiresAt *int64 `json:"exp"`
+	NotBefore *int64 `json:"nbf"`
+}
+
+type callerIdentityKey struct{}
+
+// CallerIdentity returns the identity of the authenticated caller.
+func CallerIdentity(ctx context.Context) (string, bool) {
+	identity, ok := ctx.Value(callerIdentityKey{}).(string)
+	return identity, ok
+}
+
+// Authenticator checks bearer credentials of incoming calls against a reloadable key set.
+type Authenticator struct {
+	cfg AuthConfig
+
+	mx      sync.RWMutex
+	keys    *keySet
+	modTime time.Time
+}
+
+// NewAuthenticator loads the key set and keeps reloading it on file change until ctx is done.
+func NewAuthenticator(ctx context.Context, cfg AuthConfig) (*Authenticator, error) {
+	if cfg.ExemptMethodPrefixes == nil {
+		cfg.ExemptMethodPrefixes = defaultAuthExemptPrefixes
+	}
+	auth := &Authenticator{cfg: cfg}
+	if _, err := auth.reload(); err != nil {
+		return nil, err
+	}
+	interval := cfg.ReloadInterval
+	if interval <= 0 {
+		interval = defaultAuthReloadInterval
+	}
+	go auth.run(ctx, interval)
+	return auth, nil
+}
+
+func (a *Authenticator) UnaryInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		authCtx, err := a.authenticate(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+		return handler(authCtx, req)
+	}
+}
+
+func (a *Authenticator) StreamInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		authCtx, err := a.authenticate(ss.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextServerStream{ServerStream: ss, ctx: authCtx})
+	}
+}
+
+func (a *Authenticator) authenticate(ctx context.Context, fullMethod string) (context.Context, error) {
+	for _, prefix := range a.cfg.ExemptMethodPrefixes {
+		if strings.HasPrefix(fullMethod, prefix) {
+			return ctx, nil
+		}
+	}
+	token, err := bearerToken(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	identity, err := a.verify(token, time.Now())
+	if err != nil {
+		logctx.From(ctx).Warn("Rejected unauthenticated call",
+			zap.String("method", fullMethod),
+			zap.Error(err))
+		return nil, status.Error(codes.Unauthenticated, "invalid credentials")
+	}
+	ctx = context.WithValue(ctx, callerIdentityKey{}, identity)
+	return logctx.WithFields(ctx, zap.String("caller", identity)), nil
+}
+
+func (a *Authenticator) verify(token string, now time.Time) (string, error) {
+	a.mx.RLock()
+	keys := a.keys
+	a.mx.RUnlock()
+
+	if strings.Count(token, ".") == 2 {
+		return verifyJWT(keys.JWTKeys, token, now)
+	}
+	for _, key := range keys.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(key.Key), []byte(token)) == 1 {
+			return key.Identity, nil
+		}
+	}
+	return "", errors.New("unknown api key")
+}
+
+func (a *Authenticator) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := a.reload()
+			if err != nil {
+				// The previous key set stays in use until the file is fixed.
+				zap.L().Warn("Failed to reload auth keys", zap.Error(err))
+				continue
+			}
+			if reloaded {
+				zap.L().Info("Reloaded auth keys", zap.String("keys_file", a.cfg.KeysFile))
+			}
+		}
+	}
+}
+
+func (a *Authenticator) reload() (bool, error) {
+	info, err := os.Stat(a.cfg.KeysFile)
+	if err != nil {
+		return false, err
+	}
+	a.mx.RLock()
+	unchanged := a.keys != nil && info.ModTime().Equal(a.modTime)
+	a.mx.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	raw, err := os.ReadFile(a.cfg.KeysFile)
+	if err != nil {
+		return false, err
+	}
+	var keys keySet
+	if err := json.Unmarshal(raw, &keys); err != nil {
+		return false, fmt.Errorf("parse auth keys: %w", err)
+	}
+	for _, key := range keys.APIKeys {
+		if key.Identity == "" || key.Key == "" {
+			return false, errors.New("api key without identity or key")
+		}
+	}
+	for _, key := range keys.JWTKeys {
+		if key.Secret == "" {
+			return false, errors.New("jwt key without secret")
+		}
+	}
+
+	a.mx.Lock()
+	a.keys = &keys
+	a.modTime = info.ModTime()
+	a.mx.Unlock()
+	return true, nil
+}
+
+func bearerToken(ctx context.Context) (string, error) {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return "", errors.New("missing credentials")
+	}
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return "", errors.New("missing credentials")
+	}
+	scheme, token, ok := strings.Cut(values[0], " ")
+	if !ok || !strings.EqualFold(scheme, "bearer") || strings.TrimSpace(token) == "" {
+		return "", errors.New("authorization must be a bearer token")
+	}
+	return strings.TrimSpace(token), nil
+}
+
+func verifyJWT(keys []jwtKey, token string, now time.Time) (string, error) {
+	parts := strings.Split(token, ".")
+	var header jwtHeader
+	if err := decodeJWTPart(parts[0], &header); err != nil {
+		return "", fmt.Errorf("jwt header: %w", err)
+	}
+	newHash, err := jwtHash(header.Alg)
+	if err != nil {
+		return "", err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("jwt signature: %w", err)
+	}
+	signed := []byte(parts[0] + "." + parts[1])
+	verified := false
+	for _, key := range keys {
+		if header.Kid != "" && key.ID != header.Kid {
+			continue
+		}
+		mac := hmac.New(newHash, []byte(key.Secret))
+		mac.Write(signed)
+		if hmac.Equal(mac.Sum(nil), signature) {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return "", errors.New("jwt signature mismatch")
+	}
+
+	var claims jwtClaims
+	if err := decodeJWTPart(parts[1], &claims); err != nil {
+		return "", fmt.Errorf("jwt claims: %w", err)
+	}
+	if claims.Subject == "" {
+		return "", errors.New("jwt without sub")
+	}
+	if claims.ExpiresAt == nil {
+		return "", errors.New("jwt without exp")
+	}
+	if now.After(time.Unix(*claims.ExpiresAt, 0).Add(jwtClockSkew)) {
+		return "", errors.New("jwt expired")
+	}
+	if claims.NotBefore != nil && now.Add(jwtClockSkew).Before(time.Unix(*claims.NotBefore, 0)) {
+		return "", errors.New("jwt not valid yet")
+	}
+	return claims.Subject, nil
+}
+
+func decodeJWTPart(part string, target any) error {
+	raw, err := base64.RawURLEncoding.DecodeString(part)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, target)
+}
+
+func jwtHash(alg string) (func() hash.Hash, error) {
+	switch alg {
+	case "HS256":
+		return sha256.New, nil
+	case "HS384":
+		return sha512.New384, nil
+	case "HS512":
+		return sha512.New, nil
+	default:
+		return nil, fmt.Errorf("unsupported jwt alg %q", alg)
+	}
+}
+
+type contextServerStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextServerStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,394 @@
+package grpc_kit
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/json"
+	"hash"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var testNow = time.Unix(1_700_000_000, 0)
+
+func encodePart(t *testing.T, v any) string {
+	t.Helper()
+	raw, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return base64.RawURLEncoding.EncodeToString(raw)
+}
+
+func signJWT(t *testing.T, header map[string]any, claims map[string]any, secret string, newHash func() hash.Hash) string {
+	t.Helper()
+	signed := encodePart(t, header) + "." + encodePart(t, claims)
+	mac := hmac.New(newHash, []byte(secret))
+	mac.Write([]byte(signed))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func validClaims() map[string]any {
+	return map[string]any{"sub": "server", "exp": testNow.Add(time.Minute).Unix()}
+}
+
+func TestVerifyJWT(t *testing.T) {
+	keys := []jwtKey{{ID: "old", Secret: "old-secret"}, {ID: "new", Secret: "new-secret"}}
+	hs256 := map[string]any{"alg": "HS256", "kid": "new"}
+
+	tests := []struct {
+		name    string
+		token   func(t *testing.T) string
+		wantSub string
+		wantErr string
+	}{
+		{
+			name:    "valid HS256",
+			token:   func(t *testing.T) string { return signJWT(t, hs256, validClaims(), "new-secret", sha256.New) },
+			wantSub: "server",
+		},
+		{
+			name: "valid HS384",
+			token: func(t *testing.T) string {
+				return signJWT(t, map[string]any{"alg": "HS384", "kid": "new"}, validClaims(), "new-secret", sha512.New384)
+			},
+			wantSub: "server",
+		},
+		{
+			name: "valid HS512",
+			token: func(t *testing.T) string {
+				return signJWT(t, map[string]any{"alg": "HS512", "kid": "new"}, validClaims(), "new-secret", sha512.New)
+			},
+			wantSub: "server",
+		},
+		{
+			name: "alg none",
+			token: func(t *testing.T) string {
+				return encodePart(t, map[string]any{"alg": "none"}) + "." + encodePart(t, validClaims()) + "."
+			},
+			wantErr: "unsupported jwt alg",
+		},
+		{
+			name: "alg RS256 signed with the hmac secret",
+			token: func(t *testing.T) string {
+				return signJWT(t, map[string]any{"alg": "RS256", "kid": "new"}, validClaims(), "new-secret", sha256.New)
+			},
+			wantErr: "unsupported jwt alg",
+		},
+		{
+			name: "alg in header does not match signature",
+			token: func(t *testing.T) string {
+				return signJWT(t, map[string]any{"alg": "HS512", "kid": "new"}, validClaims(), "new-secret", sha256.New)
+			},
+			wantErr: "signature mismatch",
+		},
+		{
+			name: "missing exp",
+			token: func(t *testing.T) string {
+				return signJWT(t, hs256, map[string]any{"sub": "server"}, "new-secret", sha256.New)
+			},
+			wantErr: "without exp",
+		},
+		{
+			name: "missing sub",
+			token: func(t *testing.T) string {
+				return signJWT(t, hs256, map[string]any{"exp": testNow.Add(time.Minute).Unix()}, "new-secret", sha256.New)
+			},
+			wantErr: "without sub",
+		},
+		{
+			name: "expired beyond skew",
+			token: func(t *testing.T) string {
+				claims := map[string]any{"sub": "server", "exp": testNow.Add(-jwtClockSkew - time.Second).Unix()}
+				return signJWT(t, hs256, claims, "new-secret", sha256.New)
+			},
+			wantErr: "expired",
+		},
+		{
+			name: "expired within skew",
+			token: func(t *testing.T) string {
+				claims := map[string]any{"sub": "server", "exp": testNow.Add(-jwtClockSkew + time.Second).Unix()}
+				return signJWT(t, hs256, claims, "new-secret", sha256.New)
+			},
+			wantSub: "server",
+		},
+		{
+			name: "nbf beyond skew",
+			token: func(t *testing.T) string {
+				claims := validClaims()
+				claims["nbf"] = testNow.Add(jwtClockSkew + time.Second).Unix()
+				return signJWT(t, hs256, claims, "new-secret", sha256.New)
+			},
+			wantErr: "not valid yet",
+		},
+		{
+			name: "nbf within skew",
+			token: func(t *testing.T) string {
+				claims := validClaims()
+				claims["nbf"] = testNow.Add(jwtClockSkew - time.Second).Unix()
+				return signJWT(t, hs256, claims, "new-secret", sha256.New)
+			},
+			wantSub: "server",
+		},
+		{
+			name: "kid selects another key than the signing one",
+			token: func(t *testing.T) string {
+				return signJWT(t, map[string]any{"alg": "HS256", "kid": "old"}, validClaims(), "new-secret", sha256.New)
+			},
+			wantErr: "signature mismatch",
+		},
+		{
+			name: "unknown kid",
+			token: func(t *testing.T) string {
+				return signJWT(t, map[string]any{"alg": "HS256", "kid": "gone"}, validClaims(), "new-secret", sha256.New)
+			},
+			wantErr: "signature mismatch",
+		},
+		{
+			name: "no kid tries every key",
+			token: func(t *testing.T) string {
+				return signJWT(t, map[string]any{"alg": "HS256"}, validClaims(), "old-secret", sha256.New)
+			},
+			wantSub: "server",
+		},
+		{
+			name: "unknown secret",
+			token: func(t *testing.T) string {
+				return signJWT(t, map[string]any{"alg": "HS256"}, validClaims(), "other-secret", sha256.New)
+			},
+			wantErr: "signature mismatch",
+		},
+		{
+			name: "malformed header base64",
+			token: func(t *testing.T) string {
+				token := signJWT(t, hs256, validClaims(), "new-secret", sha256.New)
+				return "!!!" + token[strings.Index(token, "."):]
+			},
+			wantErr: "jwt header",
+		},
+		{
+			name: "malformed claims base64",
+			token: func(t *testing.T) string {
+				signed := encodePart(t, hs256) + ".!!!"
+				mac := hmac.New(sha256.New, []byte("new-secret"))
+				mac.Write([]byte(signed))
+				return signed + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+			},
+			wantErr: "jwt claims",
+		},
+		{
+			name: "malformed signature base64",
+			token: func(t *testing.T) string {
+				token := signJWT(t, hs256, validClaims(), "new-secret", sha256.New)
+				return token[:strings.LastIndex(token, ".")] + ".!!!"
+			},
+			wantErr: "jwt signature",
+		},
+		{
+			name: "padded base64 is rejected",
+			token: func(t *testing.T) string {
+				token := signJWT(t, hs256, validClaims(), "new-secret", sha256.New)
+				return token + "="
+			},
+			wantErr: "jwt signature",
+		},
+		{
+			name: "tampered claims",
+			token: func(t *testing.T) string {
+				token := signJWT(t, hs256, validClaims(), "new-secret", sha256.New)
+				parts := strings.Split(token, ".")
+				parts[1] = encodePart(t, map[string]any{"sub": "admin", "exp": testNow.Add(time.Minute).Unix()})
+				return strings.Join(parts, ".")
+			},
+			wantErr: "signature mismatch",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := verifyJWT(keys, tt.token(t), testNow)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("verifyJWT() error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("verifyJWT() error = %v", err)
+			}
+			if sub != tt.wantSub {
+				t.Fatalf("verifyJWT() sub = %q, want %q", sub, tt.wantSub)
+			}
+		})
+	}
+}
+
+func writeKeys(t *testing.T, path string, keys keySet, modTime time.Time) {
+	t.Helper()
+	raw, err := json.Marshal(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAuthenticatorVerify(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	writeKeys(t, path, keySet{
+		APIKeys: []apiKey{{Identity: "server", Key: "api-key"}},
+		JWTKeys: []jwtKey{{ID: "k1", Secret: "secret"}},
+	}, testNow)
+	auth, err := NewAuthenticator(t.Context(), AuthConfig{KeysFile: path, ReloadInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantSub string
+		wantErr bool
+	}{
+		{name: "api key", token: "api-key", wantSub: "server"},
+		{name: "unknown api key", token: "api-key-2", wantErr: true},
+		{name: "api key prefix", token: "api", wantErr: true},
+		{name: "empty token", token: "", wantErr: true},
+		{
+			name:    "jwt",
+			token:   signJWT(t, map[string]any{"alg": "HS256", "kid": "k1"}, map[string]any{"sub": "bot", "exp": time.Now().Add(time.Minute).Unix()}, "secret", sha256.New),
+			wantSub: "bot",
+		},
+		{name: "jwt shaped garbage", token: "a.b.c", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sub, err := auth.verify(tt.token, time.Now())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("verify() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if sub != tt.wantSub {
+				t.Fatalf("verify() sub = %q, want %q", sub, tt.wantSub)
+			}
+		})
+	}
+}
+
+func TestAuthenticatorAuthenticate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	writeKeys(t, path, keySet{APIKeys: []apiKey{{Identity: "server", Key: "api-key"}}}, testNow)
+	auth, err := NewAuthenticator(t.Context(), AuthConfig{KeysFile: path})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		method   string
+		header   string
+		wantCode codes.Code
+		wantID   string
+	}{
+		{name: "bearer", method: "/svc/Call", header: "Bearer api-key", wantCode: codes.OK, wantID: "server"},
+		{name: "lowercase scheme", method: "/svc/Call", header: "bearer api-key", wantCode: codes.OK, wantID: "server"},
+		{name: "missing header", method: "/svc/Call", wantCode: codes.Unauthenticated},
+		{name: "basic scheme", method: "/svc/Call", header: "Basic api-key", wantCode: codes.Unauthenticated},
+		{name: "wrong key", method: "/svc/Call", header: "Bearer nope", wantCode: codes.Unauthenticated},
+		{name: "health is exempt", method: "/grpc.health.v1.Health/Check", wantCode: codes.OK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.header != "" {
+				ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", tt.header))
+			}
+			authCtx, err := auth.authenticate(ctx, tt.method)
+			if code := status.Code(err); code != tt.wantCode {
+				t.Fatalf("authenticate() code = %v, want %v", code, tt.wantCode)
+			}
+			if err != nil {
+				return
+			}
+			identity, _ := CallerIdentity(authCtx)
+			if identity != tt.wantID {
+				t.Fatalf("CallerIdentity() = %q, want %q", identity, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestAuthenticatorReloadDuringCalls(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keys.json")
+	writeKeys(t, path, keySet{APIKeys: []apiKey{{Identity: "server", Key: "old-key"}}}, testNow)
+	auth, err := NewAuthenticator(t.Context(), AuthConfig{KeysFile: path, ReloadInterval: time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				// Every call sees either the old or the new key set, never a mix or nil.
+				oldSub, oldErr := auth.verify("old-key", time.Now())
+				newSub, newErr := auth.verify("new-key", time.Now())
+				if oldErr == nil && oldSub != "server" || newErr == nil && newSub != "server" {
+					t.Errorf("unexpected identities %q %q", oldSub, newSub)
+					return
+				}
+			}
+		}()
+	}
+
+	// A broken file keeps the previous keys in use.
+	if err := os.WriteFile(path, []byte("{"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(path, testNow.Add(time.Second), testNow.Add(time.Second)); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+	if _, err := auth.verify("old-key", time.Now()); err != nil {
+		t.Fatalf("old key rejected after a failed reload: %v", err)
+	}
+
+	writeKeys(t, path, keySet{APIKeys: []apiKey{{Identity: "server", Key: "new-key"}}}, testNow.Add(2*time.Second))
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := auth.verify("new-key", time.Now()); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("new key was not picked up")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	close(stop)
+	wg.Wait()
+
+	if _, err := auth.verify("old-key", time.Now()); err == nil {
+		t.Fatal("old key still accepted after reload")
+	}
+}
@@ -0,0 +1,155 @@
+package grpc_kit
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"runtime/debug"
+	"time"
+
+	"service_common/logctx"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const requestIDHeader = "x-request-id"
+
+// InterceptorConfig holds the defaults applied by the shared interceptor chain.
+type InterceptorConfig struct {
+	// DefaultTimeout is applied to unary calls that arrive without a deadline.
+	DefaultTimeout time.Duration
+	// MethodTimeouts overrides DefaultTimeout for full method names.
+	MethodTimeouts map[string]time.Duration
+	// Requests and RequestDuration record every call by method (and code) when set.
+	Requests        *prometheus.CounterVec
+	RequestDuration *prometheus.HistogramVec
+	// PanicError is returned for recovered panics instead of a plain Internal status.
+	PanicError error
+}
+
+// UnaryInterceptors returns the shared chain: request ID, access log, panic recovery,
+// deadline defaults and then the extra interceptors (e.g. authentication).
+func (cfg InterceptorConfig) UnaryInterceptors(extra ...grpc.UnaryServerInterceptor) []grpc.UnaryServerInterceptor {
+	return append([]grpc.UnaryServerInterceptor{
+		cfg.unaryObservabilityInterceptor,
+		cfg.unaryDeadlineInterceptor,
+	}, extra...)
+}
+
+// StreamInterceptors is the streaming counterpart of UnaryInterceptors. Streams get no default deadline.
+func (cfg InterceptorConfig) StreamInterceptors(extra ...grpc.StreamServerInterceptor) []grpc.StreamServerInterceptor {
+	return append([]grpc.StreamServerInterceptor{cfg.streamObservabilityInterceptor}, extra...)
+}
+
+func (cfg InterceptorConfig) unaryObservabilityInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+	ctx = withRequestLogger(ctx, info.FullMethod)
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			err = cfg.recoveredError(ctx, r)
+		}
+		logAccess(ctx, start, err)
+		cfg.observeCall(info.FullMethod, start, err)
+	}()
+	return handler(ctx, req)
+}
+
+func (cfg InterceptorConfig) streamObservabilityInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+	ctx := withRequestLogger(ss.Context(), info.FullMethod)
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			err = cfg.recoveredError(ctx, r)
+		}
+		logAccess(ctx, start, err)
+		cfg.observeCall(info.FullMethod, start, err)
+	}()
+	return handler(srv, &contextServerStream{ServerStream: ss, ctx: ctx})
+}
+
+func (cfg InterceptorConfig) unaryDeadlineInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if _, ok := ctx.Deadline(); ok {
+		return handler(ctx, req)
+	}
+	timeout, ok := cfg.MethodTimeouts[info.FullMethod]
+	if !ok {
+		timeout = cfg.DefaultTimeout
+	}
+	if timeout <= 0 {
+		return handler(ctx, req)
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return handler(ctx, req)
+}
+
+// withRequestLogger takes the request ID from incoming metadata or generates one,
+// echoes it back in the response header and attaches it to the context logger.
+func withRequestLogger(ctx context.Context, fullMethod string) context.Context {
+	requestID := ""
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get(requestIDHeader); len(values) > 0 {
+			requestID = values[0]
+		}
+	}
+	if requestID == "" {
+		requestID = newRequestID()
+	}
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, requestID))
+	return logctx.WithFields(ctx,
+		zap.String("request_id", requestID),
+		zap.String("grpc_method", fullMethod))
+}
+
+func (cfg InterceptorConfig) recoveredError(ctx context.Context, r any) error {
+	logctx.From(ctx).Error("Recovered from panic in grpc handler",
+		zap.Any("panic", r),
+		zap.ByteString("stack", debug.Stack()))
+	if cfg.PanicError != nil {
+		return cfg.PanicError
+	}
+	return status.Error(codes.Internal, "internal error")
+}
+
+func logAccess(ctx context.Context, start time.Time, err error) {
+	code := status.Code(err)
+	level := zapcore.InfoLevel
+	switch code {
+	case codes.OK:
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		level = zapcore.ErrorLevel
+	default:
+		level = zapcore.WarnLevel
+	}
+	fields := []zap.Field{
+		zap.String("grpc_code", code.String()),
+		zap.Duration("latency", time.Since(start)),
+	}
+	if err != nil {
+		fields = append(fields, zap.Error(err))
+	}
+	logctx.From(ctx).Log(level, "grpc call finished", fields...)
+}
+
+func (cfg InterceptorConfig) observeCall(fullMethod string, start time.Time, err error) {
+	if cfg.Requests != nil {
+		cfg.Requests.WithLabelValues(fullMethod, status.Code(err).String()).Inc()
+	}
+	if cfg.RequestDuration != nil {
+		cfg.RequestDuration.WithLabelValues(fullMethod).Observe(time.Since(start).Seconds())
+	}
+}
+
+func newRequestID() string {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return time.Now().UTC().Format("20060102T150405.000000000")
+	}
+	return hex.EncodeToString(raw)
+}
@@ -0,0 +1,188 @@
+package grpc_kit
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc/credentials"
+)
+
+const defaultTLSReloadInterval = 30 * time.Second
+
+// TLSConfig enables TLS when CertFile and KeyFile are set and mTLS when ClientCAFile is set too.
+type TLSConfig struct {
+	CertFile     string
+	KeyFile      string
+	ClientCAFile string
+	// AllowedClients restricts mTLS clients to the given common names or SANs (DNS, URI, email).
+	// Empty means every client with a certificate signed by the client CA is accepted.
+	AllowedClients []string
+	// ReloadInterval is how often the files are checked for changes.
+	ReloadInterval time.Duration
+}
+
+// Enabled reports whether any TLS option is set; a partial config is rejected by Validate.
+func (cfg TLSConfig) Enabled() bool {
+	return cfg.CertFile != "" || cfg.KeyFile != "" || cfg.ClientCAFile != "" || len(cfg.AllowedClients) > 0
+}
+
+// Validate rejects configs that would silently fall back to plaintext or skip client verification.
+func (cfg TLSConfig) Validate() error {
+	if !cfg.Enabled() {
+		return nil
+	}
+	if cfg.CertFile == "" || cfg.KeyFile == "" {
+		return errors.New("both tls cert and key files are required")
+	}
+	if len(cfg.AllowedClients) > 0 && cfg.ClientCAFile == "" {
+		return errors.New("allowed tls clients require a client ca file")
+	}
+	return nil
+}
+
+// NewServerCredentials loads the certificates and keeps reloading them on file change until ctx is done.
+func NewServerCredentials(ctx context.Context, cfg TLSConfig) (credentials.TransportCredentials, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	reloader := &certReloader{cfg: cfg}
+	if _, err := reloader.reload(); err != nil {
+		return nil, err
+	}
+	interval := cfg.ReloadInterval
+	if interval <= 0 {
+		interval = defaultTLSReloadInterval
+	}
+	go reloader.run(ctx, interval)
+	return credentials.NewTLS(&tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		GetConfigForClient: reloader.configForClient,
+	}), nil
+}
+
+type certReloader struct {
+	cfg TLSConfig
+
+	mx       sync.RWMutex
+	cert     *tls.Certificate
+	clientCA *x509.CertPool
+	modTimes [3]time.Time
+}
+
+func (r *certReloader) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.reload()
+			if err != nil {
+				// The previous certificates stay in use until the files are fixed.
+				zap.L().Warn("Failed to reload tls certificates", zap.Error(err))
+				continue
+			}
+			if reloaded {
+				zap.L().Info("Reloaded tls certificates", zap.String("cert_file", r.cfg.CertFile))
+			}
+		}
+	}
+}
+
+// reload reads the files when any of them changed since the last successful load.
+func (r *certReloader) reload() (bool, error) {
+	var modTimes [3]time.Time
+	for i, path := range []string{r.cfg.CertFile, r.cfg.KeyFile, r.cfg.ClientCAFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return false, err
+		}
+		modTimes[i] = info.ModTime()
+	}
+	r.mx.RLock()
+	unchanged := r.cert != nil && modTimes == r.modTimes
+	r.mx.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(r.cfg.CertFile, r.cfg.KeyFile)
+	if err != nil {
+		return false, fmt.Errorf("load tls key pair: %w", err)
+	}
+	var clientCA *x509.CertPool
+	if r.cfg.ClientCAFile != "" {
+		pem, err := os.ReadFile(r.cfg.ClientCAFile)
+		if err != nil {
+			return false, fmt.Errorf("read client ca: %w", err)
+		}
+		clientCA = x509.NewCertPool()
+		if !clientCA.AppendCertsFromPEM(pem) {
+			return false, fmt.Errorf("no certificates found in client ca file %v", r.cfg.ClientCAFile)
+		}
+	}
+
+	r.mx.Lock()
+	r.cert = &cert
+	r.clientCA = clientCA
+	r.modTimes = modTimes
+	r.mx.Unlock()
+	return true, nil
+}
+
+func (r *certReloader) configForClient(*tls.ClientHelloInfo) (*tls.Config, error) {
+	r.mx.RLock()
+	defer r.mx.RUnlock()
+	cfg := &tls.Config{
+		MinVersion:   tls.VersionTLS12,
+		Certificates: []tls.Certificate{*r.cert},
+		NextProtos:   []string{"h2"},
+	}
+	if r.clientCA != nil {
+		cfg.ClientAuth = tls.RequireAndVerifyClientCert
+		cfg.ClientCAs = r.clientCA
+		if len(r.cfg.AllowedClients) > 0 {
+			cfg.VerifyPeerCertificate = r.verifyClientIdentity
+		}
+	}
+	return cfg, nil
+}
+
+// verifyClientIdentity runs after chain verification, so only the leaf identity is checked here.
+func (r *certReloader) verifyClientIdentity(_ [][]byte, verifiedChains [][]*x509.Certificate) error {
+	for _, chain := range verifiedChains {
+		if len(chain) == 0 {
+			continue
+		}
+		if slices.ContainsFunc(certificateIdentities(chain[0]), func(identity string) bool {
+			return slices.Contains(r.cfg.AllowedClients, identity)
+		}) {
+			return nil
+		}
+	}
+	return errors.New("client certificate identity is not allowed")
+}
+
+func certificateIdentities(cert *x509.Certificate) []string {
+	identities := make([]string, 0, 1+len(cert.DNSNames)+len(cert.URIs)+len(cert.EmailAddresses))
+	if cert.Subject.CommonName != "" {
+		identities = append(identities, cert.Subject.CommonName)
+	}
+	identities = append(identities, cert.DNSNames...)
+	for _, uri := range cert.URIs {
+		identities = append(identities, uri.String())
+	}
+	return append(identities, cert.EmailAddresses...)
+}
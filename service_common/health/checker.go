@@ -0,0 +1,196 @@
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	gormio "gorm.io/gorm"
+)
+
+// Check pings one dependency and returns an error when it is unavailable.
+type Check func(ctx context.Context) error
+
+// Checker periodically runs dependency checks and notifies listeners about the aggregated result.
+type Checker struct {
+	interval time.Duration
+	timeout  time.Duration
+
+	mx        sync.RWMutex
+	names     []string
+	checks    map[string]Check
+	failures  map[string]string
+	healthy   bool
+	checked   bool
+	listeners []func(healthy bool)
+}
+
+func NewChecker(interval time.Duration, timeout time.Duration) *Checker {
+	return &Checker{
+		interval: interval,
+		timeout:  timeout,
+		checks:   make(map[string]Check),
+		failures: make(map[string]string),
+	}
+}
+
+// Add registers a dependency check. It must be called before Run.
+func (c *Checker) Add(name string, check Check) *Checker {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.names = append(c.names, name)
+	c.checks[name] = check
+	return c
+}
+
+// OnChange registers a listener called after the first check and on every status change.
+func (c *Checker) OnChange(listener func(healthy bool)) {
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	c.listeners = append(c.listeners, listener)
+	if c.checked {
+		listener(c.healthy)
+	}
+}
+
+// Run checks the dependencies right away and then every interval until ctx is done.
+func (c *Checker) Run(ctx context.Context) {
+	ticker := time.NewTicker(c.interval)
+	defer ticker.Stop()
+	for {
+		c.checkOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Status returns the last aggregated result and the failing dependencies.
+func (c *Checker) Status() (bool, map[string]string) {
+	c.mx.RLock()
+	defer c.mx.RUnlock()
+	failures := make(map[string]string, len(c.failures))
+	for name, reason := range c.failures {
+		failures[name] = reason
+	}
+	return c.checked && c.healthy, failures
+}
+
+func (c *Checker) checkOnce(ctx context.Context) {
+	c.mx.RLock()
+	names := append([]string(nil), c.names...)
+	c.mx.RUnlock()
+
+	failures := make(map[string]string)
+	for _, name := range names {
+		checkCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		err := c.checks[name](checkCtx)
+		cancel()
+		if err != nil {
+			failures[name] = err.Error()
+		}
+	}
+	healthy := len(failures) == 0
+
+	c.mx.Lock()
+	changed := !c.checked || c.healthy != healthy
+	c.healthy = healthy
+	c.checked = true
+	c.failures = failures
+	listeners := append([]func(bool){}, c.listeners...)
+	c.mx.Unlock()
+
+	if !changed {
+		return
+	}
+	if healthy {
+		zap.L().Info("dependencies are healthy")
+	} else {
+		zap.L().Warn("dependencies are unhealthy", zap.Any("failures", failures))
+	}
+	for _, listener := range listeners {
+		listener(healthy)
+	}
+}
+
+// DBCheck pings the database behind the gorm connection.
+func DBCheck(db *gormio.DB) Check {
+	return func(ctx context.Context) error {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return err
+		}
+		return sqlDB.PingContext(ctx)
+	}
+}
+
+// TCPCheck succeeds when at least one of the addresses accepts a connection, e.g. Kafka brokers.
+func TCPCheck(addrs []string) Check {
+	return func(ctx context.Context) error {
+		var dialer net.Dialer
+		errs := make([]error, 0, len(addrs))
+		for _, addr := range addrs {
+			conn, err := dialer.DialContext(ctx, "tcp", addr)
+			if err == nil {
+				return conn.Close()
+			}
+			errs = append(errs, err)
+		}
+		if len(errs) == 0 {
+			return errors.New("no addresses to check")
+		}
+		return errors.Join(errs...)
+	}
+}
+
+// HTTPCheck succeeds when GET url answers with a 2xx status, e.g. the Coder /healthz endpoint.
+func HTTPCheck(url string) Check {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("unexpected status %v from %v", resp.StatusCode, url)
+		}
+		return nil
+	}
+}
+
+// ProbeHandler serves the probe result as JSON with 200 when probe returns nil and 503 otherwise.
+func ProbeHandler(probe func() error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := probe(); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			_ = json.NewEncoder(w).Encode(map[string]string{"status": "unhealthy", "reason": err.Error()})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"status": "ok"})
+	})
+}
+
+// ReadinessProbe reports the checker status for ProbeHandler.
+func (c *Checker) ReadinessProbe() error {
+	healthy, failures := c.Status()
+	if healthy {
+		return nil
+	}
+	if len(failures) == 0 {
+		return errors.New("dependencies not checked yet")
+	}
+	return fmt.Errorf("unhealthy dependencies: %v", failures)
+}
@@ -0,0 +1,27 @@
+package logctx
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+type loggerKey struct{}
+
+// With returns a context carrying the logger.
+func With(ctx context.Context, logger *zap.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// WithFields adds fields to the logger stored in the context.
+func WithFields(ctx context.Context, fields ...zap.Field) context.Context {
+	return With(ctx, From(ctx).With(fields...))
+}
+
+// From returns the logger stored in the context or the global logger.
+func From(ctx context.Context) *zap.Logger {
+	if logger, ok := ctx.Value(loggerKey{}).(*zap.Logger); ok {
+		return logger
+	}
+	return zap.L()
+}
@@ -0,0 +1,206 @@
+// Package tokencrypt encrypts stored forge tokens with envelope encryption: every token is sealed
+// with its own random data key, and the data key is sealed with a master key identified by a key ID.
+// Rotating the master key only needs the data keys to be re-sealed. The keyring is shared by the
+// services that read the stored tokens.
+package tokencrypt
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const keySize = 32
+
+var ErrUnknownKey = errors.New("unknown master key")
+
+// Sealed is a token as stored in the tokens table. KeyID and DataKey are nil for plaintext rows
+// written before encryption was enabled.
+type Sealed struct {
+	Token   string
+	KeyID   *string
+	DataKey *string
+}
+
+// Keyring holds the master keys by ID; new tokens are sealed with the active one.
+type Keyring struct {
+	active string
+	keys   map[string][]byte
+}
+
+// NewKeyring returns a keyring sealing with the active key. Keys must be 32 bytes (AES-256).
+func NewKeyring(active string, keys map[string][]byte) (*Keyring, error) {
+	for id, key := range keys {
+		if len(key) != keySize {
+			return nil, fmt.Errorf("master key %q must be %d bytes, got %d", id, keySize, len(key))
+		}
+	}
+	if _, ok := keys[active]; !ok {
+		return nil, fmt.Errorf("%w: active key %q", ErrUnknownKey, active)
+	}
+	return &Keyring{active: active, keys: keys}, nil
+}
+
+// LoadKeyring reads master keys from a key file of "id=base64key" lines, or when path is empty
+// from the base64 key in envKey, which gets the ID envKeyID. The active key defaults to the last
+// key of the file. It returns nil when neither is set.
+func LoadKeyring(path string, active string, envKey string, envKeyID string) (*Keyring, error) {
+	keys := make(map[string][]byte)
+	var last string
+	switch {
+	case path != "":
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		scanner := bufio.NewScanner(bytes.NewReader(raw))
+		for line := 1; scanner.Scan(); line++ {
+			entry := strings.TrimSpace(scanner.Text())
+			if entry == "" || strings.HasPrefix(entry, "#") {
+				continue
+			}
+			id, encoded, ok := strings.Cut(entry, "=")
+			id = strings.TrimSpace(id)
+			if !ok || id == "" {
+				return nil, fmt.Errorf("%v:%d: expected id=base64key", path, line)
+			}
+			key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+			if err != nil {
+				return nil, fmt.Errorf("%v:%d: %w", path, line, err)
+			}
+			keys[id] = key
+			last = id
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, err
+		}
+		if active == "" {
+			active = last
+		}
+	case envKey != "":
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(envKey))
+		if err != nil {
+			return nil, fmt.Errorf("master key: %w", err)
+		}
+		if envKeyID == "" {
+			return nil, errors.New("master key ID is required")
+		}
+		keys[envKeyID] = key
+		active = envKeyID
+	default:
+		return nil, nil
+	}
+	return NewKeyring(active, keys)
+}
+
+// ActiveKeyID returns the ID of the key new tokens are sealed with.
+func (k *Keyring) ActiveKeyID() string {
+	return k.active
+}
+
+// Seal encrypts the token with a fresh data key sealed by the active master key.
+func (k *Keyring) Seal(token string) (Sealed, error) {
+	return k.SealBytes([]byte(token))
+}
+
+// SealBytes is Seal for binary payloads, e.g. cached API responses.
+func (k *Keyring) SealBytes(data []byte) (Sealed, error) {
+	dataKey := make([]byte, keySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return Sealed{}, err
+	}
+	sealedToken, err := seal(dataKey, data, nil)
+	if err != nil {
+		return Sealed{}, err
+	}
+	// The key ID is authenticated so a data key cannot be moved under another master key.
+	sealedKey, err := seal(k.keys[k.active], dataKey, []byte(k.active))
+	if err != nil {
+		return Sealed{}, err
+	}
+	keyID := k.active
+	return Sealed{Token: sealedToken, KeyID: &keyID, DataKey: &sealedKey}, nil
+}
+
+// Open returns the plaintext token. Plaintext rows are returned unchanged.
+func (k *Keyring) Open(s Sealed) (string, error) {
+	if s.KeyID == nil {
+		return s.Token, nil
+	}
+	token, err := k.OpenBytes(s)
+	if err != nil {
+		return "", err
+	}
+	return string(token), nil
+}
+
+// OpenBytes returns the payload sealed by SealBytes.
+func (k *Keyring) OpenBytes(s Sealed) ([]byte, error) {
+	if s.KeyID == nil {
+		return []byte(s.Token), nil
+	}
+	if k == nil {
+		return nil, fmt.Errorf("%w: %q, token encryption is not configured", ErrUnknownKey, *s.KeyID)
+	}
+	masterKey, ok := k.keys[*s.KeyID]
+	if !ok || s.DataKey == nil {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownKey, *s.KeyID)
+	}
+	dataKey, err := open(masterKey, *s.DataKey, []byte(*s.KeyID))
+	if err != nil {
+		return nil, fmt.Errorf("open data key: %w", err)
+	}
+	data, err := open(dataKey, s.Token, nil)
+	if err != nil {
+		return nil, fmt.Errorf("open token: %w", err)
+	}
+	return data, nil
+}
+
+// NeedsRotation reports whether the row is plaintext or sealed under a key other than the active one.
+func (k *Keyring) NeedsRotation(s Sealed) bool {
+	return s.KeyID == nil || *s.KeyID != k.active
+}
+
+// seal returns base64(nonce || AES-GCM ciphertext).
+func seal(key []byte, plaintext []byte, additional []byte) (string, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(aead.Seal(nonce, nonce, plaintext, additional)), nil
+}
+
+func open(key []byte, sealed string, additional []byte) ([]byte, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) < aead.NonceSize() {
+		return nil, errors.New("ciphertext too short")
+	}
+	return aead.Open(nil, raw[:aead.NonceSize()], raw[aead.NonceSize():], additional)
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,251 @@
+package tokencrypt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testKey(b byte) []byte {
+	return bytes.Repeat([]byte{b}, keySize)
+}
+
+func testKeyring(t *testing.T, active string) *Keyring {
+	t.Helper()
+	keyring, err := NewKeyring(active, map[string][]byte{"old": testKey(1), "new": testKey(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return keyring
+}
+
+func ptr(s string) *string {
+	return &s
+}
+
+// flip changes one byte of the base64 payload.
+func flip(t *testing.T, sealed string) string {
+	t.Helper()
+	raw, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	raw[len(raw)-1] ^= 1
+	return base64.StdEncoding.EncodeToString(raw)
+}
+
+func TestSealOpen(t *testing.T) {
+	keyring := testKeyring(t, "new")
+	sealed, err := keyring.Seal("ghp_secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sealed.KeyID == nil || *sealed.KeyID != "new" || sealed.DataKey == nil {
+		t.Fatalf("sealed = %+v, want it sealed with the active key", sealed)
+	}
+	if sealed.Token == "ghp_secret" {
+		t.Fatal("token is stored in plaintext")
+	}
+	got, err := keyring.Open(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "ghp_secret" {
+		t.Errorf("Open = %q, want %q", got, "ghp_secret")
+	}
+
+	again, err := keyring.Seal("ghp_secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Token == sealed.Token || *again.DataKey == *sealed.DataKey {
+		t.Error("sealing twice gives the same ciphertext, want a fresh data key and nonce")
+	}
+}
+
+func TestSealOpenBytes(t *testing.T) {
+	keyring := testKeyring(t, "new")
+	payload := []byte{0, 1, 2, 0xff}
+	sealed, err := keyring.SealBytes(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := keyring.OpenBytes(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, payload) {
+		t.Errorf("OpenBytes = %v, want %v", got, payload)
+	}
+}
+
+func TestOpenRotated(t *testing.T) {
+	sealed, err := testKeyring(t, "old").Seal("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Tokens sealed with a previous key still open while it stays in the keyring.
+	got, err := testKeyring(t, "new").Open(sealed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "token" {
+		t.Errorf("Open = %q, want %q", got, "token")
+	}
+}
+
+func TestOpenInvalid(t *testing.T) {
+	keyring := testKeyring(t, "new")
+	sealed, err := keyring.Seal("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	retired, err := NewKeyring("new", map[string][]byte{"new": testKey(2)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	oldSealed, err := testKeyring(t, "old").Seal("token")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		keyring *Keyring
+		sealed  Sealed
+		wantErr error
+	}{
+		{name: "unknown key id", keyring: keyring, sealed: Sealed{Token: sealed.Token, KeyID: ptr("missing"), DataKey: sealed.DataKey}, wantErr: ErrUnknownKey},
+		{name: "retired key", keyring: retired, sealed: oldSealed, wantErr: ErrUnknownKey},
+		{name: "encryption not configured", sealed: sealed, wantErr: ErrUnknownKey},
+		{name: "missing data key", keyring: keyring, sealed: Sealed{Token: sealed.Token, KeyID: sealed.KeyID}, wantErr: ErrUnknownKey},
+		{name: "tampered token", keyring: keyring, sealed: Sealed{Token: flip(t, sealed.Token), KeyID: sealed.KeyID, DataKey: sealed.DataKey}},
+		{name: "tampered data key", keyring: keyring, sealed: Sealed{Token: sealed.Token, KeyID: sealed.KeyID, DataKey: ptr(flip(t, *sealed.DataKey))}},
+		// The key ID is authenticated, so a data key does not open under another master key.
+		{name: "data key moved to another key id", keyring: keyring, sealed: Sealed{Token: oldSealed.Token, KeyID: ptr("new"), DataKey: oldSealed.DataKey}},
+		{name: "not base64", keyring: keyring, sealed: Sealed{Token: "%%%", KeyID: sealed.KeyID, DataKey: sealed.DataKey}},
+		{name: "too short", keyring: keyring, sealed: Sealed{Token: sealed.Token, KeyID: sealed.KeyID, DataKey: ptr("AAAA")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.keyring.Open(tt.sealed)
+			if err == nil {
+				t.Fatalf("Open = %q, want an error", got)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("err = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestOpenPlaintext(t *testing.T) {
+	plaintext := Sealed{Token: "ghp_plain"}
+	for name, keyring := range map[string]*Keyring{"keyring": testKeyring(t, "new"), "no keyring": nil} {
+		t.Run(name, func(t *testing.T) {
+			got, err := keyring.Open(plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != "ghp_plain" {
+				t.Errorf("Open = %q, want %q", got, "ghp_plain")
+			}
+			data, err := keyring.OpenBytes(plaintext)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(data) != "ghp_plain" {
+				t.Errorf("OpenBytes = %q, want %q", data, "ghp_plain")
+			}
+		})
+	}
+}
+
+func TestNeedsRotation(t *testing.T) {
+	keyring := testKeyring(t, "new")
+	tests := []struct {
+		name   string
+		sealed Sealed
+		want   bool
+	}{
+		{name: "plaintext", sealed: Sealed{Token: "token"}, want: true},
+		{name: "previous key", sealed: Sealed{KeyID: ptr("old")}, want: true},
+		{name: "active key", sealed: Sealed{KeyID: ptr("new")}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keyring.NeedsRotation(tt.sealed); got != tt.want {
+				t.Errorf("NeedsRotation = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewKeyringInvalid(t *testing.T) {
+	if _, err := NewKeyring("a", map[string][]byte{"a": []byte("short")}); err == nil {
+		t.Error("short key: err = nil, want an error")
+	}
+	if _, err := NewKeyring("b", map[string][]byte{"a": testKey(1)}); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("missing active key: err = %v, want %v", err, ErrUnknownKey)
+	}
+}
+
+func TestLoadKeyring(t *testing.T) {
+	encoded := func(b byte) string {
+		return base64.StdEncoding.EncodeToString(testKey(b))
+	}
+	path := filepath.Join(t.TempDir(), "keys")
+	content := "# master keys\n\n2024=" + encoded(1) + "\n 2025 = " + encoded(2) + "\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	invalid := filepath.Join(t.TempDir(), "invalid")
+	if err := os.WriteFile(invalid, []byte("2024\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		path       string
+		active     string
+		envKey     string
+		envKeyID   string
+		wantActive string
+		wantErr    bool
+	}{
+		{name: "file uses the last key", path: path, wantActive: "2025"},
+		{name: "file with an active key", path: path, active: "2024", wantActive: "2024"},
+		{name: "file with an unknown active key", path: path, active: "2023", wantErr: true},
+		{name: "file takes precedence over the env key", path: path, envKey: encoded(3), envKeyID: "env", wantActive: "2025"},
+		{name: "env key", envKey: encoded(3), envKeyID: "env", wantActive: "env"},
+		{name: "env key without id", envKey: encoded(3), wantErr: true},
+		{name: "env key not base64", envKey: "%%%", envKeyID: "env", wantErr: true},
+		{name: "malformed file", path: invalid, wantErr: true},
+		{name: "missing file", path: filepath.Join(t.TempDir(), "missing"), wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			keyring, err := LoadKeyring(tt.path, tt.active, tt.envKey, tt.envKeyID)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("LoadKeyring = %+v, want an error", keyring)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := keyring.ActiveKeyID(); got != tt.wantActive {
+				t.Errorf("active key = %q, want %q", got, tt.wantActive)
+			}
+		})
+	}
+
+	keyring, err := LoadKeyring("", "", "", "")
+	if err != nil || keyring != nil {
+		t.Errorf("LoadKeyring without keys = %v, %v, want nil", keyring, err)
+	}
+}